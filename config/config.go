@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 
 	"github.com/fsnotify/fsnotify"
@@ -18,16 +19,21 @@ var (
 	once   sync.Once
 	mu     sync.Mutex
 	v      *viper.Viper
+
+	// listeners are the OnChange subscribers
+	listeners   []func(old, new *Config)
+	listenersMu sync.Mutex
+	watchOnce   sync.Once
 )
 
 // Config represents the configuration implementation.
 type Config struct {
 	AppName   string
 	RunMode   string
-	Protocol  string
-	Domain    string
-	Host      string
-	Port      int
+	Protocol  string `config:"server.protocol"`
+	Domain    string `config:"server.domain"`
+	Host      string `config:"server.host"`
+	Port      int    `config:"server.port" validate:"min=0,max=65535"`
 	Consul    *Consul
 	Observes  *Observes
 	Extension *Extension
@@ -36,8 +42,14 @@ type Config struct {
 	Data      *Data
 	Auth      *Auth
 	Storage   *Storage
-	OAuth     *OAuth
+	OAuth     *OAuth `config:"oauth"`
 	Email     *Email
+	Remote    *Remote
+
+	// settings are the layers the configuration was read from, for Get
+	settings *viper.Viper
+	// sections are the registered extension sections by name
+	sections map[string]any
 }
 
 func init() {
@@ -79,6 +91,7 @@ func loadConfiguration() (*Config, error) {
 		return nil, fmt.Errorf("error loading config: %w", err)
 	}
 	config = cfg
+	applySections(cfg)
 	return cfg, nil
 }
 
@@ -97,28 +110,75 @@ func LoadConfig(configPath string) (*Config, error) {
 		v.AddConfigPath(".")
 		v.AddConfigPath(filepath.Dir(ex))
 	}
+	if format != "" {
+		f, err := normalizeFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		v.SetConfigType(f)
+	}
+	bindEnv(v)
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	if err := mergeIncludes(v); err != nil {
+		return nil, err
+	}
+	if err := mergeOverlay(v); err != nil {
+		return nil, err
+	}
+	if f, err := configFormat(v); err == nil && f == FormatDotenv {
+		if err := applyDotenv(v); err != nil {
+			return nil, err
+		}
+	}
+
+	remote, err := getRemoteConfig(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote config: %w", err)
+	}
+	src := v
+	if remote != nil {
+		if src, err = mergeRemote(v, remote); err != nil {
+			return nil, err
+		}
+	}
+	applyFlags(src)
+	if err := checkKeys(src); err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{
-		AppName:   v.GetString("app_name"),
-		RunMode:   v.GetString("run_mode"),
-		Protocol:  v.GetString("server.protocol"),
-		Domain:    v.GetString("server.domain"),
-		Host:      v.GetString("server.host"),
-		Port:      v.GetInt("server.port"),
-		Consul:    getConsulConfig(v),
-		Observes:  getObservesConfig(v),
-		Extension: getExtensionConfig(v),
-		Auth:      getAuth(v),
-		Frontend:  getFrontendConfig(v),
-		Logger:    getLoggerConfig(v),
-		Data:      getDataConfig(v),
-		Storage:   getStorageConfig(v),
-		OAuth:     getOAuthConfig(v),
-		Email:     getEmailConfig(v),
+		AppName:   src.GetString("app_name"),
+		RunMode:   src.GetString("run_mode"),
+		Protocol:  src.GetString("server.protocol"),
+		Domain:    src.GetString("server.domain"),
+		Host:      src.GetString("server.host"),
+		Port:      src.GetInt("server.port"),
+		Consul:    getConsulConfig(src),
+		Observes:  getObservesConfig(src),
+		Extension: getExtensionConfig(src),
+		Auth:      getAuth(src),
+		Frontend:  getFrontendConfig(src),
+		Logger:    getLoggerConfig(src),
+		Data:      getDataConfig(src),
+		Storage:   getStorageConfig(src),
+		OAuth:     getOAuthConfig(src),
+		Email:     getEmailConfig(src),
+		Remote:    remote,
+		settings:  src,
+	}
+
+	if err := resolveSecrets(context.Background(), cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve config secrets: %w", err)
+	}
+	if cfg.sections, err = loadSections(context.Background(), src); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
@@ -135,17 +195,74 @@ func Reload() error {
 	}
 
 	config = newConfig
+	applySections(newConfig)
 	return nil
 }
 
+// OnChange subscribes fn to configuration changes, it is called with the
+// previous and the reloaded configuration every time the file changes to a
+// valid configuration that differs from the current one. The file is watched
+// from the first subscription on.
+func OnChange(fn func(old, new *Config)) {
+	listenersMu.Lock()
+	listeners = append(listeners, fn)
+	listenersMu.Unlock()
+
+	watchOnce.Do(func() {
+		v.OnConfigChange(func(e fsnotify.Event) {
+			reloadAndNotify()
+		})
+		v.WatchConfig()
+		watchOverlay(v.ConfigFileUsed(), reloadAndNotify)
+		watchIncludes(reloadAndNotify)
+		if config != nil && config.Remote != nil && config.Remote.Watch {
+			go watchRemote(config.Remote)
+		}
+	})
+}
+
 // Watch watches the configuration file and reloads it when it changes.
 func Watch(callback func(*Config)) {
-	v.WatchConfig()
-	v.OnConfigChange(func(e fsnotify.Event) {
-		if err := Reload(); err != nil {
-			fmt.Printf("Error reloading config: %v\n", err)
-			return
-		}
-		callback(config)
+	OnChange(func(_, cfg *Config) {
+		callback(cfg)
 	})
 }
+
+// reloadAndNotify reloads the configuration and calls the subscribers when
+// it changed, an invalid file keeps the current configuration
+func reloadAndNotify() {
+	mu.Lock()
+	old := config
+	next, err := LoadConfig(path)
+	if err != nil {
+		mu.Unlock()
+		fmt.Printf("Error reloading config: %v\n", err)
+		return
+	}
+	// Editors often emit several events for one save
+	if sameConfig(old, next) {
+		mu.Unlock()
+		return
+	}
+	config = next
+	applySections(next)
+	mu.Unlock()
+
+	listenersMu.Lock()
+	fns := make([]func(old, new *Config), len(listeners))
+	copy(fns, listeners)
+	listenersMu.Unlock()
+	for _, fn := range fns {
+		fn(old, next)
+	}
+}
+
+// sameConfig reports whether two configurations hold the same values
+func sameConfig(a, b *Config) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.settings, y.settings = nil, nil
+	return reflect.DeepEqual(x, y)
+}
@@ -4,14 +4,14 @@ import "github.com/spf13/viper"
 
 // Consul config struct
 type Consul struct {
-	Address   string `json:"address"`
-	Scheme    string `json:"scheme"`
+	Address   string `json:"address" validate:"omitempty,hostport"`
+	Scheme    string `json:"scheme" validate:"omitempty,oneof=http https"`
 	Discovery struct {
 		DefaultTags   []string          `json:"default_tags"`
 		DefaultMeta   map[string]string `json:"default_meta"`
 		HealthCheck   bool              `json:"health_check"`
-		CheckInterval string            `json:"check_interval"`
-		Timeout       string            `json:"timeout"`
+		CheckInterval string            `json:"check_interval" default:"10s"`
+		Timeout       string            `json:"timeout" default:"5s"`
 	} `json:"discovery"`
 }
 
@@ -30,13 +30,7 @@ func getConsulConfig(v *viper.Viper) *Consul {
 	consul.Discovery.CheckInterval = v.GetString("consul.discovery.check_interval")
 	consul.Discovery.Timeout = v.GetString("consul.discovery.timeout")
 
-	// Set default values if not set
-	if consul.Discovery.CheckInterval == "" {
-		consul.Discovery.CheckInterval = "10s"
-	}
-	if consul.Discovery.Timeout == "" {
-		consul.Discovery.Timeout = "5s"
-	}
+	setDefaults(v, "consul.discovery", &consul.Discovery)
 
 	return consul
 }
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+// setDefaults sets the fields of target tagged `default:"..."` whose key is
+// not set in any layer, so an explicit zero, e.g. level: 0, is kept
+//
+// The key of a field is prefix and its config or json tag name, or its name in
+// snake case, e.g. FlushInterval of logger.search is logger.search.flush_interval.
+// Slices are comma separated and durations use time.ParseDuration.
+func setDefaults(v *viper.Viper, prefix string, target any) {
+	rv := reflect.ValueOf(target).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		def, ok := f.Tag.Lookup("default")
+		if !ok || v.IsSet(prefix+"."+fieldKey(f)) {
+			continue
+		}
+		if err := setDefault(rv.Field(i), def); err != nil {
+			panic(fmt.Sprintf("config: invalid default of %s.%s: %v", rt.Name(), f.Name, err))
+		}
+	}
+}
+
+// fieldKey returns the config key of a field, a config tag overrides the
+// key, e.g. `config:"server.port"`, and `config:"-"` marks a derived field
+func fieldKey(f reflect.StructField) string {
+	if key, ok := f.Tag.Lookup("config"); ok {
+		return key
+	}
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return snakeCase(f.Name)
+}
+
+// snakeCase converts a field name to snake case, keeping acronyms together,
+// e.g. MaxSizeMB is max_size_mb
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// setDefault parses the default into the field
+func setDefault(field reflect.Value, def string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(def, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		field.Set(reflect.ValueOf(strings.Split(def, ",")).Convert(field.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
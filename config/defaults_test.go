@@ -0,0 +1,50 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"Level":         "level",
+		"FlushInterval": "flush_interval",
+		"MaxSizeMB":     "max_size_mb",
+		"SizeKB":        "size_kb",
+		"IncludePID":    "include_pid",
+		"DSN":           "dsn",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader("logger:\n  level: 0\n  search:\n    batch_size: 10\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := getLoggerConfig(v)
+	// An explicit zero is kept
+	if logger.Level != 0 {
+		t.Errorf("level = %d, want 0", logger.Level)
+	}
+	search := logger.Search
+	if search.BatchSize != 10 || search.BufferSize != 1000 || search.FlushInterval != 5*time.Second {
+		t.Errorf("unexpected search config %+v", search)
+	}
+	if !reflect.DeepEqual(logger.Redact.Fields, []string{"password", "token", "authorization", "card_number"}) {
+		t.Errorf("redact fields = %v", logger.Redact.Fields)
+	}
+
+	if got := getLoggerConfig(viper.New()).Level; got != 4 {
+		t.Errorf("default level = %d, want 4", got)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+// Config encryption keys, NCO_CONFIG_KEY is a base64 AES-256 key and
+// NCO_CONFIG_KEY_KMS the same key encrypted by AWS KMS, base64 encoded
+const (
+	ConfigKeyEnv    = "NCO_CONFIG_KEY"
+	ConfigKeyKMSEnv = "NCO_CONFIG_KEY_KMS"
+)
+
+// AES256GCM is the built-in cipher of encrypted values
+const AES256GCM = "AES256_GCM"
+
+// encValue matches encrypted values like ENC[AES256_GCM,base64]
+var encValue = regexp.MustCompile(`ENC\[([A-Za-z0-9_-]+),([A-Za-z0-9+/=]+)\]`)
+
+// Decrypter decrypts the values of a cipher, e.g. ENC[age,...] with a
+// decrypter registered under "age"
+type Decrypter interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// DecrypterFunc adapts a function to a Decrypter
+type DecrypterFunc func(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+// Decrypt calls f
+func (f DecrypterFunc) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return f(ctx, ciphertext)
+}
+
+var (
+	decrypters   = map[string]Decrypter{AES256GCM: DecrypterFunc(decryptAESGCM)}
+	decryptersMu sync.RWMutex
+)
+
+// RegisterDecrypter registers the decrypter of a cipher name
+func RegisterDecrypter(name string, d Decrypter) {
+	decryptersMu.Lock()
+	defer decryptersMu.Unlock()
+	decrypters[name] = d
+}
+
+// decrypter returns the decrypter of a cipher name
+func decrypter(name string) (Decrypter, bool) {
+	decryptersMu.RLock()
+	defer decryptersMu.RUnlock()
+	d, ok := decrypters[name]
+	return d, ok
+}
+
+// Encrypt encrypts a value with AES-256-GCM, returning it as ENC[AES256_GCM,...]
+// to be committed in a config file
+func Encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return "ENC[" + AES256GCM + "," + base64.StdEncoding.EncodeToString(sealed) + "]", nil
+}
+
+// decryptValue replaces the encrypted values in s
+func decryptValue(ctx context.Context, s string) (string, error) {
+	var err error
+	out := encValue.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+		m := encValue.FindStringSubmatch(match)
+		d, ok := decrypter(m[1])
+		if !ok {
+			err = fmt.Errorf("unknown cipher %q", m[1])
+			return match
+		}
+		ciphertext, decodeErr := base64.StdEncoding.DecodeString(m[2])
+		if decodeErr != nil {
+			err = fmt.Errorf("invalid %s value: %w", m[1], decodeErr)
+			return match
+		}
+		plaintext, decryptErr := d.Decrypt(ctx, ciphertext)
+		if decryptErr != nil {
+			err = fmt.Errorf("failed to decrypt %s value: %w", m[1], decryptErr)
+			return match
+		}
+		return string(plaintext)
+	})
+	return out, err
+}
+
+// decryptAESGCM opens a nonce prefixed AES-256-GCM ciphertext with the config key
+func decryptAESGCM(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	key, err := configKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+// newGCM creates an AES-256-GCM cipher
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("config key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// kmsKey caches the data key decrypted by KMS
+var kmsKey struct {
+	sync.Mutex
+	blob string
+	key  []byte
+}
+
+// configKey returns the config key of NCO_CONFIG_KEY, or of
+// NCO_CONFIG_KEY_KMS decrypted by KMS with the default credential chain
+func configKey(ctx context.Context) ([]byte, error) {
+	if k := os.Getenv(ConfigKeyEnv); k != "" {
+		key, err := base64.StdEncoding.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", ConfigKeyEnv, err)
+		}
+		return key, nil
+	}
+	blob := os.Getenv(ConfigKeyKMSEnv)
+	if blob == "" {
+		return nil, fmt.Errorf("encrypted config values need %s or %s", ConfigKeyEnv, ConfigKeyKMSEnv)
+	}
+
+	kmsKey.Lock()
+	defer kmsKey.Unlock()
+	if kmsKey.blob == blob {
+		return kmsKey.key, nil
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(blob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ConfigKeyKMSEnv, err)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	out, err := kms.New(sess).DecryptWithContext(ctx, &kms.DecryptInput{CiphertextBlob: ciphertext})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config key with KMS: %w", err)
+	}
+	kmsKey.blob, kmsKey.key = blob, out.Plaintext
+	return out.Plaintext, nil
+}
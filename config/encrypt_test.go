@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	t.Setenv(ConfigKeyEnv, base64.StdEncoding.EncodeToString(key))
+
+	enc, err := Encrypt(key, []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(enc, "ENC["+AES256GCM+",") {
+		t.Fatalf("unexpected encrypted value %s", enc)
+	}
+
+	cfg := &Config{Auth: &Auth{JWT: &JWT{Secret: enc}}, AppName: "plain"}
+	if err := resolveSecrets(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Auth.JWT.Secret != "hunter2" || cfg.AppName != "plain" {
+		t.Errorf("got secret %q and app name %q", cfg.Auth.JWT.Secret, cfg.AppName)
+	}
+}
+
+func TestDecryptValue_WrongKey(t *testing.T) {
+	enc, err := Encrypt(bytes.Repeat([]byte{1}, 32), []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigKeyEnv, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+	if _, err := decryptValue(context.Background(), enc); err == nil {
+		t.Error("expected an error with the wrong key")
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultEnvPrefix is the prefix of environment variable overrides
+const DefaultEnvPrefix = "NCO"
+
+// envPrefix is the prefix of environment variable overrides, empty disables them
+var envPrefix = DefaultEnvPrefix
+
+// SetEnvPrefix sets the prefix of environment variable overrides, it must be
+// called before the configuration is loaded. An empty prefix disables them.
+//
+// The variable of a setting is the prefix and its key path in upper case
+// joined by underscores, e.g. NCO_LOGGER_LEVEL overrides logger.level and
+// NCO_DATA_RABBITMQ_URL overrides data.rabbitmq.url. List values are
+// separated by spaces.
+func SetEnvPrefix(prefix string) {
+	mu.Lock()
+	defer mu.Unlock()
+	envPrefix = prefix
+}
+
+// EnvPrefix returns the prefix of environment variable overrides
+func EnvPrefix() string {
+	return envPrefix
+}
+
+// EnvKey returns the environment variable overriding the key,
+// e.g. NCO_SERVER_PORT for server.port
+func EnvKey(key string) string {
+	key = strings.ToUpper(envKeyReplacer.Replace(key))
+	if envPrefix == "" {
+		return key
+	}
+	return envPrefix + "_" + key
+}
+
+// envKeyReplacer maps key paths to environment variable names
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// bindEnv makes every key readable from the environment, variables taking
+// precedence over the file
+func bindEnv(v *viper.Viper) {
+	if envPrefix == "" {
+		return
+	}
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+}
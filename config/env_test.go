@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"logger.level":          "NCO_LOGGER_LEVEL",
+		"data.rabbitmq.url":     "NCO_DATA_RABBITMQ_URL",
+		"server.port":           "NCO_SERVER_PORT",
+		"logger.search.flush-x": "NCO_LOGGER_SEARCH_FLUSH_X",
+	} {
+		if got := EnvKey(key); got != want {
+			t.Errorf("EnvKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestLoadConfig_EnvOverride(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "app_name: demo\nserver:\n  port: 3000\nlogger:\n  level: 4\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NCO_SERVER_PORT", "4000")
+	t.Setenv("NCO_DATA_RABBITMQ_URL", "amqp://mq:5672")
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 4000 {
+		t.Errorf("port = %d, want 4000", cfg.Port)
+	}
+	if cfg.Data.RabbitMQ.URL != "amqp://mq:5672" {
+		t.Errorf("rabbitmq url = %q", cfg.Data.RabbitMQ.URL)
+	}
+	if cfg.AppName != "demo" || cfg.Logger.Level != 4 {
+		t.Errorf("file values not kept: %q %d", cfg.AppName, cfg.Logger.Level)
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// flagValue is the flag of a config key, it keeps the raw value until the
+// configuration is loaded
+type flagValue struct {
+	key    string
+	value  string
+	set    bool
+	isBool bool
+	isList bool
+}
+
+// String returns the raw value
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set records the raw value
+func (f *flagValue) Set(s string) error {
+	f.value, f.set = s, true
+	return nil
+}
+
+// IsBoolFlag allows boolean keys without value, e.g. -logger.compress
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+var (
+	flagValues []*flagValue
+	flagsMu    sync.Mutex
+)
+
+// BindFlags registers on fs a flag for every key of the Config struct, e.g.
+// -logger.level=5 or --data.database.master.source=..., set flags override
+// every other layer: files, overlays, remote values and the environment.
+// Slices are comma separated, maps and lists of structs have no flag.
+//
+// Call it before Init, which parses the command line, or before fs.Parse:
+//
+//	config.BindFlags(flag.CommandLine)
+//	cfg, err := config.Init()
+//
+// pflag users add the generated flags with pflag.CommandLine.AddGoFlagSet.
+func BindFlags(fs *flag.FlagSet) {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	walkKeys(reflect.TypeOf(Config{}), "", func(key string, t reflect.Type) {
+		if !flagSupported(t) || fs.Lookup(key) != nil {
+			return
+		}
+		fv := &flagValue{
+			key:    key,
+			isBool: t.Kind() == reflect.Bool,
+			isList: t.Kind() == reflect.Slice,
+		}
+		fs.Var(fv, key, "config "+key+" ("+flagType(t)+")")
+		flagValues = append(flagValues, fv)
+	})
+}
+
+// walkKeys calls fn with the key and type of every field of t that is not a
+// struct, pointers are dereferenced
+func walkKeys(t reflect.Type, prefix string, fn func(key string, t reflect.Type)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key := fieldKey(f)
+		if key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			walkKeys(ft, key, fn)
+			continue
+		}
+		fn(key, ft)
+	}
+}
+
+// flagSupported reports whether a flag can set a field of type t
+func flagSupported(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// flagType returns the type shown in the usage of a flag
+func flagType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "comma separated list"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// applyFlags sets the keys of the set flags on v, the highest priority layer
+func applyFlags(v *viper.Viper) {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	for _, f := range flagValues {
+		if !f.set {
+			continue
+		}
+		if f.isList {
+			v.Set(f.key, strings.Split(f.value, ","))
+			continue
+		}
+		v.Set(f.key, f.value)
+	}
+}
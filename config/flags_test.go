@@ -0,0 +1,72 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestWalkKeys(t *testing.T) {
+	keys := map[string]bool{}
+	walkKeys(reflect.TypeOf(Config{}), "", func(key string, t reflect.Type) {
+		keys[key] = flagSupported(t)
+	})
+	for _, key := range []string{
+		"app_name",
+		"server.port",
+		"logger.level",
+		"logger.cloudwatch.region",
+		"data.database.master.source",
+		"data.database.master.max_life_time",
+		"data.rabbitmq.uri",
+		"email.smtp.host",
+		"oauth.github.id",
+		"extension.includes",
+	} {
+		if !keys[key] {
+			t.Errorf("missing key %s", key)
+		}
+	}
+	for _, key := range []string{"logger.index_name", "logger.meilisearch.host", "data.database.slaves"} {
+		if keys[key] {
+			t.Errorf("unexpected key %s", key)
+		}
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	// Flags are overrides of the shared viper instance
+	t.Cleanup(func() {
+		flagValues = nil
+		v = viper.New()
+	})
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "app_name: demo\nserver:\n  port: 3000\nlogger:\n  level: 4\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NCO_SERVER_PORT", "4000")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	BindFlags(fs)
+	if err := fs.Parse([]string{"--server.port=5000", "-logger.compress", "-auth.whitelist=/health,/metrics"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Flags win over the environment, unset flags keep the file values
+	if cfg.Port != 5000 || !cfg.Logger.Compress || cfg.Logger.Level != 4 {
+		t.Errorf("got port %d, compress %v and level %d", cfg.Port, cfg.Logger.Compress, cfg.Logger.Level)
+	}
+	if want := []string{"/health", "/metrics"}; !reflect.DeepEqual(cfg.Auth.Whitelist, want) {
+		t.Errorf("whitelist = %v, want %v", cfg.Auth.Whitelist, want)
+	}
+}
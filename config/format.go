@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Config file formats
+const (
+	FormatYAML   = "yaml"
+	FormatTOML   = "toml"
+	FormatJSON   = "json"
+	FormatDotenv = "dotenv"
+)
+
+// format is the explicit config file format, empty detects it from the extension
+var format string
+
+// dotenvKeys are the variables set from a dotenv file, so reloads update them
+var dotenvKeys = map[string]bool{}
+
+func init() {
+	flag.StringVar(&format, "conf-format", "", "config file format: yaml, toml, json or dotenv, detected from the extension by default")
+}
+
+// SetFormat sets the config file format, e.g. for files without extension,
+// it must be called before the configuration is loaded
+func SetFormat(f string) error {
+	normalized, err := normalizeFormat(f)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	format = normalized
+	return nil
+}
+
+// normalizeFormat maps a format or file extension to one of the formats
+func normalizeFormat(f string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(f, ".")) {
+	case "":
+		return "", nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	case "json":
+		return FormatJSON, nil
+	case "dotenv", "env":
+		return FormatDotenv, nil
+	default:
+		return "", fmt.Errorf("unsupported config format %q", f)
+	}
+}
+
+// configFormat returns the format of the config file read by v
+func configFormat(v *viper.Viper) (string, error) {
+	if format != "" {
+		return normalizeFormat(format)
+	}
+	return normalizeFormat(filepath.Ext(v.ConfigFileUsed()))
+}
+
+// applyDotenv exports the variables of a dotenv file to the environment, so
+// they map to config keys like environment overrides, e.g. NCO_LOGGER_LEVEL
+// sets logger.level. Variables already set in the environment win.
+func applyDotenv(v *viper.Viper) error {
+	if envPrefix == "" {
+		return errors.New("dotenv config files need an env prefix")
+	}
+	for key, value := range v.AllSettings() {
+		name := strings.ToUpper(key)
+		if _, set := os.LookupEnv(name); set && !dotenvKeys[name] {
+			continue
+		}
+		if err := os.Setenv(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("failed to set %s: %w", name, err)
+		}
+		dotenvKeys[name] = true
+	}
+	return nil
+}
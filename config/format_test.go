@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeFormat(t *testing.T) {
+	for in, want := range map[string]string{
+		"":      "",
+		".yml":  FormatYAML,
+		"YAML":  FormatYAML,
+		".toml": FormatTOML,
+		"json":  FormatJSON,
+		".env":  FormatDotenv,
+	} {
+		if got, err := normalizeFormat(in); err != nil || got != want {
+			t.Errorf("normalizeFormat(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeFormat("xml"); err == nil {
+		t.Error("expected an error for xml")
+	}
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	t.Cleanup(func() {
+		for name := range dotenvKeys {
+			_ = os.Unsetenv(name)
+			delete(dotenvKeys, name)
+		}
+	})
+
+	files := map[string]string{
+		"config.yaml": "app_name: demo\nserver:\n  port: 3000\n",
+		"config.toml": "app_name = \"demo\"\n[server]\nport = 3000\n",
+		"config.json": `{"app_name": "demo", "server": {"port": 3000}}`,
+		".env":        "NCO_APP_NAME=demo\nNCO_SERVER_PORT=3000\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(file)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.AppName != "demo" || cfg.Port != 3000 {
+				t.Errorf("got app name %q and port %d", cfg.AppName, cfg.Port)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrKeyNotFound is returned by Get when the key is not set in any layer
+var ErrKeyNotFound = errors.New("config key not found")
+
+// Get returns the value of a key outside of the Config struct, e.g. a key
+// registered by an extension module, from all layers: overlays, remote
+// values, environment overrides and defaults. Values are coerced to string,
+// int, int64, float64, bool, time.Duration, []string, []int,
+// map[string]string or map[string]any, other types are decoded like
+// viper.UnmarshalKey. Secret references and encrypted values are resolved.
+//
+//	timeout, err := config.Get[time.Duration]("payments.timeout")
+func Get[T any](path string) (T, error) {
+	cfg, err := current()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return get[T](cfg, path)
+}
+
+// get returns the value of a key of cfg
+func get[T any](cfg *Config, path string) (T, error) {
+	var out T
+	s := cfg.settings
+	if s == nil || !s.IsSet(path) {
+		return out, fmt.Errorf("%w: %s", ErrKeyNotFound, path)
+	}
+
+	if err := coerce(s.Get(path), &out); err != nil {
+		if !errors.Is(err, errUnsupportedType) {
+			return out, fmt.Errorf("config key %s: %w", path, err)
+		}
+		if err := s.UnmarshalKey(path, &out); err != nil {
+			return out, fmt.Errorf("config key %s: %w", path, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	r := &secretResolver{ctx: ctx, cache: make(map[string]string)}
+	r.walk(reflect.ValueOf(&out).Elem())
+	if r.err != nil {
+		return out, fmt.Errorf("config key %s: %w", path, r.err)
+	}
+	return out, nil
+}
+
+// errUnsupportedType is returned by coerce for types it does not convert
+var errUnsupportedType = errors.New("unsupported type")
+
+// coerce converts raw to the type of out
+func coerce(raw any, out any) error {
+	var v any
+	var err error
+	switch out.(type) {
+	case *string:
+		v, err = cast.ToStringE(raw)
+	case *int:
+		v, err = cast.ToIntE(raw)
+	case *int64:
+		v, err = cast.ToInt64E(raw)
+	case *float64:
+		v, err = cast.ToFloat64E(raw)
+	case *bool:
+		v, err = cast.ToBoolE(raw)
+	case *time.Duration:
+		v, err = cast.ToDurationE(raw)
+	case *[]string:
+		v, err = cast.ToStringSliceE(raw)
+	case *[]int:
+		v, err = cast.ToIntSliceE(raw)
+	case *map[string]string:
+		v, err = cast.ToStringMapStringE(raw)
+	case *map[string]any:
+		v, err = cast.ToStringMapE(raw)
+	default:
+		return errUnsupportedType
+	}
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(out).Elem().Set(reflect.ValueOf(v))
+	return nil
+}
+
+// current returns the loaded configuration, loading it when needed
+func current() (*Config, error) {
+	mu.Lock()
+	cfg := config
+	mu.Unlock()
+	if cfg != nil {
+		return cfg, nil
+	}
+	return GetConfig()
+}
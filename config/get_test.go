@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := `payments:
+  timeout: 1m30s
+  retries: "3"
+  enabled: true
+  currencies: [usd, eur]
+  gateway:
+    name: stripe
+    region: eu
+`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NCO_PAYMENTS_REGION", "eu-west-1")
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d, err := get[time.Duration](cfg, "payments.timeout"); err != nil || d != 90*time.Second {
+		t.Errorf("timeout = %v, %v", d, err)
+	}
+	if n, err := get[int](cfg, "payments.retries"); err != nil || n != 3 {
+		t.Errorf("retries = %v, %v", n, err)
+	}
+	if b, err := get[bool](cfg, "payments.enabled"); err != nil || !b {
+		t.Errorf("enabled = %v, %v", b, err)
+	}
+	if s, err := get[[]string](cfg, "payments.currencies"); err != nil || !reflect.DeepEqual(s, []string{"usd", "eur"}) {
+		t.Errorf("currencies = %v, %v", s, err)
+	}
+	if s, err := get[string](cfg, "payments.region"); err != nil || s != "eu-west-1" {
+		t.Errorf("region = %q, %v", s, err)
+	}
+
+	type gateway struct {
+		Name   string
+		Region string
+	}
+	if g, err := get[gateway](cfg, "payments.gateway"); err != nil || g.Name != "stripe" || g.Region != "eu" {
+		t.Errorf("gateway = %+v, %v", g, err)
+	}
+
+	if _, err := get[string](cfg, "payments.missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := get[int](cfg, "payments.currencies"); err == nil {
+		t.Error("expected a conversion error")
+	}
+}
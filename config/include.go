@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// IncludeKey lists the files composed into a config file, e.g.
+//
+//	include:
+//	  - logger.yaml
+//	  - data.yaml
+//	  - conf.d/*.yaml
+const IncludeKey = "include"
+
+var (
+	// includedFiles are the files included by the last loaded configuration
+	includedFiles   []string
+	includedFilesMu sync.Mutex
+)
+
+// mergeIncludes composes the files included by the config file read by v.
+// Paths are relative to the including file and may be globs, matched in
+// lexical order. Included files are merged in the listed order, each one
+// replacing the keys of the previous ones, and the including file replaces
+// the keys of all its includes. Included files may include other files.
+func mergeIncludes(v *viper.Viper) error {
+	if !v.IsSet(IncludeKey) {
+		setIncludedFiles(nil)
+		return nil
+	}
+	file, err := filepath.Abs(v.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	composed, err := readComposed(file, []string{file}, &files)
+	if err != nil {
+		return err
+	}
+	if err := v.MergeConfigMap(composed); err != nil {
+		return fmt.Errorf("failed to merge config includes: %w", err)
+	}
+	setIncludedFiles(files)
+	return nil
+}
+
+// readComposed reads a config file with its includes, stack holds the
+// including files to detect cycles and files collects the included files
+func readComposed(file string, stack []string, files *[]string) (map[string]any, error) {
+	r := viper.New()
+	r.SetConfigFile(file)
+	if format != "" && filepath.Ext(file) == "" {
+		f, err := normalizeFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		r.SetConfigType(f)
+	}
+	if err := r.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", file, err)
+	}
+	if !r.IsSet(IncludeKey) {
+		return r.AllSettings(), nil
+	}
+
+	includes, err := cast.ToStringSliceE(r.Get(IncludeKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s of %s: %w", IncludeKey, file, err)
+	}
+	acc := viper.New()
+	for _, include := range includes {
+		paths, err := includePaths(filepath.Dir(file), include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s of %s: %w", IncludeKey, file, err)
+		}
+		for _, path := range paths {
+			for _, f := range stack {
+				if f == path {
+					return nil, fmt.Errorf("config include cycle: %s -> %s", strings.Join(stack, " -> "), path)
+				}
+			}
+			settings, err := readComposed(path, append(stack[:len(stack):len(stack)], path), files)
+			if err != nil {
+				return nil, err
+			}
+			if err := acc.MergeConfigMap(settings); err != nil {
+				return nil, fmt.Errorf("failed to merge config include %s: %w", path, err)
+			}
+			*files = append(*files, path)
+		}
+	}
+	if err := acc.MergeConfigMap(r.AllSettings()); err != nil {
+		return nil, fmt.Errorf("failed to merge config file %s: %w", file, err)
+	}
+	return acc.AllSettings(), nil
+}
+
+// includePaths returns the absolute files of an include, a glob matching
+// no file is skipped while a missing plain file fails
+func includePaths(dir, include string) ([]string, error) {
+	if !filepath.IsAbs(include) {
+		include = filepath.Join(dir, include)
+	}
+	if !strings.ContainsAny(include, "*?[") {
+		return []string{filepath.Clean(include)}, nil
+	}
+	return filepath.Glob(include)
+}
+
+// setIncludedFiles records the included files for watchIncludes
+func setIncludedFiles(files []string) {
+	includedFilesMu.Lock()
+	defer includedFilesMu.Unlock()
+	includedFiles = files
+}
+
+// isIncludedFile reports whether path is included by the last configuration
+func isIncludedFile(path string) bool {
+	includedFilesMu.Lock()
+	defer includedFilesMu.Unlock()
+	for _, f := range includedFiles {
+		if f == filepath.Clean(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchIncludes calls fn when an included file is written, created or
+// removed, watching the directories of the files included at subscription
+func watchIncludes(fn func()) {
+	includedFilesMu.Lock()
+	dirs := map[string]bool{}
+	for _, f := range includedFiles {
+		dirs[filepath.Dir(f)] = true
+	}
+	includedFilesMu.Unlock()
+	if len(dirs) == 0 {
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Printf("Error watching config includes: %v\n", err)
+		return
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			fmt.Printf("Error watching config includes: %v\n", err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isIncludedFile(event.Name) &&
+					event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					fn()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("Error watching config includes: %v\n", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig_Include(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":      "include: [logger.yaml, conf.d/*.yaml]\napp_name: demo\nserver:\n  port: 3000\n",
+		"logger.yaml":      "logger:\n  level: 5\n  format: json\nserver:\n  port: 1000\n",
+		"conf.d/a.yaml":    "logger:\n  format: text\n",
+		"conf.d/b.yaml":    "include: ../shared/data.yaml\nlogger:\n  format: console\n",
+		"shared/data.yaml": "data:\n  environment: staging\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Later includes win over earlier ones and the including file over all
+	if cfg.Logger.Level != 5 || cfg.Logger.Format != "console" || cfg.Port != 3000 {
+		t.Errorf("got level %d, format %q and port %d", cfg.Logger.Level, cfg.Logger.Format, cfg.Port)
+	}
+	if cfg.Data.Enveronment != "staging" {
+		t.Errorf("expected the nested include, got environment %q", cfg.Data.Enveronment)
+	}
+	if !isIncludedFile(filepath.Join(dir, "shared", "data.yaml")) {
+		t.Errorf("expected the nested include to be watched, got %v", includedFiles)
+	}
+}
+
+func TestLoadConfig_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("include: a.yaml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("include: config.yaml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "config.yaml")); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
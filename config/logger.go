@@ -1,6 +1,10 @@
 package config
 
 import (
+	"errors"
+	"os"
+	"time"
+
 	dc "ncobase/common/data/config"
 
 	"github.com/spf13/viper"
@@ -8,23 +12,254 @@ import (
 
 // Logger logger config struct
 type Logger struct {
-	Level         int
-	Path          string
-	Format        string
-	Output        string
-	OutputFile    string
-	IndexName     string
-	Meilisearch   *dc.Meilisearch
-	Elasticsearch *dc.Elasticsearch
+	Level              int               `default:"4"` // logrus level, 0 panic to 6 trace
+	Modules            map[string]string // level name by module for Named loggers, e.g. payments: debug
+	HookLevels         map[string]string // minimum level by hook name, e.g. elasticsearch: warn, meilisearch: info
+	Path               string
+	Format             string `validate:"omitempty,oneof=json gelf ecs console text"` // json, gelf, ecs, console or text
+	Output             string `validate:"omitempty,oneof=stdout stderr file custom syslog tee"`
+	OutputFile         string
+	FileBuffer         *LoggerFileBuffer
+	Outputs            []LoggerOutput `validate:"dive"` // multiple destinations, replaces Output and Format when set
+	FallbackToStderr   bool           // log to stderr instead of failing Init when the log file cannot be set up
+	RotateSchedule     string         // hourly, daily or daily@HH:MM, default daily at midnight
+	RotateTimezone     string         // IANA timezone of the rotation schedule and file names, default local
+	MaxSizeMB          int            `validate:"min=0"` // rotate the log file once it exceeds this size, 0 rotates on schedule only
+	MaxBackups         int            `validate:"min=0"` // rotated log files to keep, 0 keeps all
+	MaxAgeDays         int            `validate:"min=0"` // remove rotated log files older than this, 0 keeps all
+	Compress           bool           // gzip rotated log files
+	RetentionDryRun    bool           // only log the rotated files MaxBackups and MaxAgeDays would remove
+	ServiceName        string         `config:"-"` // service.name of ECS entries, default app_name
+	IndexName          string         `config:"-"`
+	Pipeline           string         // Elasticsearch ingest pipeline applied to log documents
+	IncludeHost        bool
+	IncludePID         bool
+	ReportCaller       bool              // add the file, line and function of the logging call
+	ShutdownTimeout    time.Duration     // max wait for hooks to flush at cleanup, default 5s
+	SlowQueryThreshold time.Duration     // ORM queries slower than this are logged at warn, default 200ms
+	Metrics            bool              // count entries by level in the log_entries_total Prometheus metric
+	SpanEvents         bool              // record entries as events of the active OpenTelemetry span
+	Meilisearch        *dc.Meilisearch   `config:"-"`
+	Elasticsearch      *dc.Elasticsearch `config:"-"`
+	Search             *LoggerSearch
+	Spool              *LoggerSpool
+	Document           *LoggerDocument
+	Loki               *LoggerLoki
+	CloudWatch         *LoggerCloudWatch `config:"cloudwatch"`
+	Kafka              *LoggerKafka
+	Syslog             *LoggerSyslog
+	OTLP               *LoggerOTLP
+	Fluentd            *LoggerFluentd
+	Graylog            *LoggerGraylog
+	Sentry             *LoggerSentry
+	Redact             *LoggerRedact
+	Stack              *LoggerStack
+	Sampling           *LoggerSampling
+	Dedup              *LoggerDedup
+	Audit              *LoggerAudit
+}
+
+// LoggerSampling per level sampling config
+//
+// Within every Tick the first First entries of a level are logged, then one in
+// Thereafter, e.g. levels: {debug: {first: 100, thereafter: 100}}.
+type LoggerSampling struct {
+	Tick   time.Duration                 `default:"1s"` // sampling window
+	Levels map[string]LoggerSamplingRate // rates by level name, levels not listed are not sampled
+}
+
+// LoggerDedup duplicate entry suppression config
+//
+// Entries with the same level, message and Keys fields within Window are
+// logged once, followed by one entry with their repeat_count.
+type LoggerDedup struct {
+	Window time.Duration // dedup window, dedup is disabled when 0
+	Keys   []string      // fields compared besides level and message, e.g. error
+}
+
+// LoggerSamplingRate sampling rate of a level
+type LoggerSamplingRate struct {
+	First      int // entries logged per tick before sampling
+	Thereafter int // then log one in Thereafter, 0 drops the rest of the tick
+}
+
+// LoggerFileBuffer write buffering of the file output
+type LoggerFileBuffer struct {
+	SizeKB        int           // buffer size, 0 writes every entry straight to the file
+	FlushInterval time.Duration `default:"1s"`    // max time an entry stays buffered
+	FlushLevel    string        `default:"error"` // entries at this level or above are flushed at once
+}
+
+// LoggerAudit tamper-evident audit log config
+type LoggerAudit struct {
+	Path string // audit log file, hash chained JSON lines, auditing is disabled when empty
+}
+
+// LoggerStack stack trace capture config for Error and above
+type LoggerStack struct {
+	Enabled bool // attach a stack field to Error, Fatal and Panic entries
+	Skip    int  // extra frames skipped above the logging call, e.g. for logging helpers
+	Depth   int  `default:"32"` // max frames captured
+}
+
+// LoggerOutput a log destination with its own format, e.g. text to stdout and json to a file
+type LoggerOutput struct {
+	Type   string `validate:"required,oneof=stdout stderr file custom syslog"` // stdout, stderr, file, custom or syslog
+	Format string `validate:"omitempty,oneof=json gelf ecs console text"`      // json, gelf, ecs, console or text
+	Path   string // file path, default OutputFile
+}
+
+// LoggerRedact sensitive value masking config
+type LoggerRedact struct {
+	Fields   []string `default:"password,token,authorization,card_number"` // field names whose values are masked
+	Patterns []string // regular expressions masked inside string values and messages
+}
+
+// LoggerSentry Sentry config of Error, Fatal and Panic entries
+type LoggerSentry struct {
+	DSN         string  `validate:"omitempty,url"` // project DSN, the hook is disabled when empty
+	Environment string  // default run_mode
+	SampleRate  float64 // share of events sent, 0 sends all
+}
+
+// LoggerGraylog Graylog GELF input config
+type LoggerGraylog struct {
+	Network   string `validate:"omitempty,oneof=udp tcp" default:"udp"` // udp or tcp
+	Address   string `validate:"omitempty,hostport"`                    // GELF input host:port, the hook is disabled when empty
+	ChunkSize int    `default:"1420"`                                   // max UDP datagram size before chunking
+}
+
+// LoggerFluentd Fluentd forward protocol config
+type LoggerFluentd struct {
+	Host    string        // Fluentd or Fluent Bit host, the hook is disabled when empty
+	Port    int           `validate:"min=0,max=65535" default:"24224"` // forward port
+	Tag     string        // tag of the records, default app_name
+	Timeout time.Duration `default:"3s"` // dial and write timeout
+}
+
+// LoggerOTLP OpenTelemetry logs exporter config
+type LoggerOTLP struct {
+	Endpoint    string            // collector host:port, the exporter is disabled when empty
+	Protocol    string            `validate:"omitempty,oneof=grpc http" default:"grpc"` // grpc or http
+	Insecure    bool              // disable TLS
+	Headers     map[string]string // extra headers, e.g. authentication
+	ServiceName string            // service.name resource attribute, default app_name
+}
+
+// LoggerSyslog syslog output config, used when Output is "syslog"
+type LoggerSyslog struct {
+	Network            string `validate:"omitempty,oneof=udp tcp tls"` // udp, tcp or tls, empty for the local daemon
+	Address            string // host:port of a remote daemon
+	Facility           string `default:"local0"` // facility name
+	Tag                string // APP-NAME of the messages, default app_name
+	InsecureSkipVerify bool   // skip certificate verification for tls
+}
+
+// LoggerKafka Kafka log sink config
+type LoggerKafka struct {
+	Brokers      []string      // default data.kafka.brokers
+	Topic        string        // topic receiving JSON encoded entries
+	KeyField     string        `default:"trace_id"` // entry field used as message key
+	BatchTimeout time.Duration `default:"1s"`       // max time messages wait before being sent
+}
+
+// LoggerCloudWatch AWS CloudWatch Logs config, entries are batched with the Search settings
+//
+// Credentials come from the AWS SDK default chain, e.g. the environment or IRSA.
+type LoggerCloudWatch struct {
+	Region    string // AWS region, default AWS_REGION
+	LogGroup  string // existing log group, the hook is disabled when empty
+	LogStream string // log stream, created when missing, default the hostname
+	Endpoint  string // custom endpoint, e.g. LocalStack
+}
+
+// LoggerLoki Grafana Loki push config, entries are batched with the Search settings
+type LoggerLoki struct {
+	URL      string            `validate:"omitempty,url"` // Loki base URL, e.g. http://loki:3100
+	TenantID string            // sent as X-Scope-OrgID for multi-tenant Loki
+	Username string            // basic auth user
+	Password string            // basic auth password
+	Labels   map[string]string // stream labels, default app and env
+}
+
+// LoggerDocument key names of the documents indexed in Meilisearch and Elasticsearch
+type LoggerDocument struct {
+	Message   string `default:"message"`    // key of the message
+	Level     string `default:"level"`      // key of the level
+	Timestamp string `default:"@timestamp"` // key of the entry time
+	Fields    string `default:"fields"`     // key of the nested entry fields
+	Schema    string // "ecs" indexes Elastic Common Schema documents, the keys above are ignored
+}
+
+// LoggerSpool disk spool of search hook batches that failed to send
+type LoggerSpool struct {
+	Dir           string        // spool directory, spooling is disabled when empty
+	MaxSizeMB     int           `default:"64"`  // max spool size per hook, batches are dropped beyond it
+	RetryInterval time.Duration `default:"30s"` // interval between re-sends of spooled batches
+}
+
+// LoggerSearch search hook batching config, shared by Meilisearch and Elasticsearch
+//
+// Entries are queued in a buffer of BufferSize, a batch is sent once BatchSize
+// entries are pending or FlushInterval has elapsed, whichever comes first.
+// When the buffer is full new entries are dropped rather than blocking the
+// caller, unless BlockOnFull applies backpressure instead.
+type LoggerSearch struct {
+	BatchSize     int           `default:"100"`  // entries per bulk request
+	FlushInterval time.Duration `default:"5s"`   // max time an entry waits before being sent
+	BufferSize    int           `default:"1000"` // max pending entries before dropping
+	BlockOnFull   bool          // block logging calls while the buffer is full instead of dropping
+}
+
+// Validate validates logger configuration
+func (c *Logger) Validate() error {
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 || c.MaxAgeDays < 0 {
+		return errors.New("logger rotation limits must not be negative")
+	}
+	if c.Search == nil {
+		return nil
+	}
+	if c.Search.BatchSize < 1 {
+		return errors.New("logger search batch size must be greater than 0")
+	}
+	if c.Search.BufferSize < 1 {
+		return errors.New("logger search buffer size must be greater than 0")
+	}
+	if c.Search.FlushInterval <= 0 {
+		return errors.New("logger search flush interval must be greater than 0")
+	}
+	if c.Search.BatchSize > c.Search.BufferSize {
+		return errors.New("logger search batch size must not exceed buffer size")
+	}
+	return nil
 }
 
 func getLoggerConfig(v *viper.Viper) *Logger {
-	return &Logger{
-		Level:      v.GetInt("logger.level"),
-		Format:     v.GetString("logger.format"),
-		Path:       v.GetString("logger.path"),
-		Output:     v.GetString("logger.output"),
-		OutputFile: v.GetString("logger.output_file"),
+	logger := &Logger{
+		Level:              v.GetInt("logger.level"),
+		Modules:            v.GetStringMapString("logger.modules"),
+		HookLevels:         v.GetStringMapString("logger.hook_levels"),
+		Format:             v.GetString("logger.format"),
+		Path:               v.GetString("logger.path"),
+		Output:             v.GetString("logger.output"),
+		OutputFile:         v.GetString("logger.output_file"),
+		Outputs:            getLoggerOutputsConfig(v),
+		FileBuffer:         getLoggerFileBufferConfig(v),
+		FallbackToStderr:   v.GetBool("logger.fallback_to_stderr"),
+		RotateSchedule:     v.GetString("logger.rotate_schedule"),
+		RotateTimezone:     v.GetString("logger.rotate_timezone"),
+		MaxSizeMB:          v.GetInt("logger.max_size_mb"),
+		MaxBackups:         v.GetInt("logger.max_backups"),
+		MaxAgeDays:         v.GetInt("logger.max_age_days"),
+		Compress:           v.GetBool("logger.compress"),
+		RetentionDryRun:    v.GetBool("logger.retention_dry_run"),
+		IncludeHost:        v.GetBool("logger.include_host"),
+		IncludePID:         v.GetBool("logger.include_pid"),
+		ReportCaller:       v.GetBool("logger.report_caller"),
+		ShutdownTimeout:    v.GetDuration("logger.shutdown_timeout"),
+		SlowQueryThreshold: v.GetDuration("logger.slow_query_threshold"),
+		Metrics:            v.GetBool("logger.metrics"),
+		SpanEvents:         v.GetBool("logger.span_events"),
 		Meilisearch: &dc.Meilisearch{
 			Host:   v.GetString("data.meilisearch.host"),
 			APIKey: v.GetString("data.meilisearch.api_key"),
@@ -34,6 +269,289 @@ func getLoggerConfig(v *viper.Viper) *Logger {
 			Username:  v.GetString("data.elasticsearch.username"),
 			Password:  v.GetString("data.elasticsearch.password"),
 		},
-		IndexName: v.GetString("app_name") + "_log",
+		Search:      getLoggerSearchConfig(v),
+		Spool:       getLoggerSpoolConfig(v),
+		Document:    getLoggerDocumentConfig(v),
+		Loki:        getLoggerLokiConfig(v),
+		CloudWatch:  getLoggerCloudWatchConfig(v),
+		Kafka:       getLoggerKafkaConfig(v),
+		Syslog:      getLoggerSyslogConfig(v),
+		OTLP:        getLoggerOTLPConfig(v),
+		Fluentd:     getLoggerFluentdConfig(v),
+		Graylog:     getLoggerGraylogConfig(v),
+		Sentry:      getLoggerSentryConfig(v),
+		Redact:      getLoggerRedactConfig(v),
+		Stack:       getLoggerStackConfig(v),
+		Sampling:    getLoggerSamplingConfig(v),
+		Dedup:       getLoggerDedupConfig(v),
+		Audit:       getLoggerAuditConfig(v),
+		IndexName:   v.GetString("app_name") + "_log",
+		ServiceName: v.GetString("app_name"),
+		Pipeline:    v.GetString("logger.pipeline"),
 	}
+	setDefaults(v, "logger", logger)
+
+	return logger
+}
+
+// getLoggerSearchConfig get logger search hook config
+func getLoggerSearchConfig(v *viper.Viper) *LoggerSearch {
+	search := &LoggerSearch{
+		BatchSize:     v.GetInt("logger.search.batch_size"),
+		FlushInterval: v.GetDuration("logger.search.flush_interval"),
+		BufferSize:    v.GetInt("logger.search.buffer_size"),
+		BlockOnFull:   v.GetBool("logger.search.block_on_full"),
+	}
+
+	setDefaults(v, "logger.search", search)
+
+	return search
+}
+
+// getLoggerOutputsConfig get logger output destinations config
+func getLoggerOutputsConfig(v *viper.Viper) []LoggerOutput {
+	var outputs []LoggerOutput
+	if err := v.UnmarshalKey("logger.outputs", &outputs); err != nil {
+		return nil
+	}
+	return outputs
+}
+
+// getLoggerRedactConfig get logger redaction config
+func getLoggerRedactConfig(v *viper.Viper) *LoggerRedact {
+	redact := &LoggerRedact{
+		Fields:   v.GetStringSlice("logger.redact.fields"),
+		Patterns: v.GetStringSlice("logger.redact.patterns"),
+	}
+
+	setDefaults(v, "logger.redact", redact)
+
+	return redact
+}
+
+// getLoggerFileBufferConfig get logger file output buffering config
+func getLoggerFileBufferConfig(v *viper.Viper) *LoggerFileBuffer {
+	buffer := &LoggerFileBuffer{
+		SizeKB:        v.GetInt("logger.file_buffer.size_kb"),
+		FlushInterval: v.GetDuration("logger.file_buffer.flush_interval"),
+		FlushLevel:    v.GetString("logger.file_buffer.flush_level"),
+	}
+
+	setDefaults(v, "logger.file_buffer", buffer)
+
+	return buffer
+}
+
+// getLoggerAuditConfig get logger audit log config
+func getLoggerAuditConfig(v *viper.Viper) *LoggerAudit {
+	return &LoggerAudit{
+		Path: v.GetString("logger.audit.path"),
+	}
+}
+
+// getLoggerDedupConfig get logger duplicate suppression config
+func getLoggerDedupConfig(v *viper.Viper) *LoggerDedup {
+	return &LoggerDedup{
+		Window: v.GetDuration("logger.dedup.window"),
+		Keys:   v.GetStringSlice("logger.dedup.keys"),
+	}
+}
+
+// getLoggerStackConfig get logger stack trace config
+func getLoggerStackConfig(v *viper.Viper) *LoggerStack {
+	stack := &LoggerStack{
+		Enabled: v.GetBool("logger.stack.enabled"),
+		Skip:    v.GetInt("logger.stack.skip"),
+		Depth:   v.GetInt("logger.stack.depth"),
+	}
+
+	setDefaults(v, "logger.stack", stack)
+
+	return stack
+}
+
+// getLoggerSamplingConfig get logger sampling config
+func getLoggerSamplingConfig(v *viper.Viper) *LoggerSampling {
+	sampling := &LoggerSampling{
+		Tick:   v.GetDuration("logger.sampling.tick"),
+		Levels: make(map[string]LoggerSamplingRate),
+	}
+	for level := range v.GetStringMap("logger.sampling.levels") {
+		key := "logger.sampling.levels." + level
+		sampling.Levels[level] = LoggerSamplingRate{
+			First:      v.GetInt(key + ".first"),
+			Thereafter: v.GetInt(key + ".thereafter"),
+		}
+	}
+
+	setDefaults(v, "logger.sampling", sampling)
+
+	return sampling
+}
+
+// getLoggerDocumentConfig get logger search document config
+func getLoggerDocumentConfig(v *viper.Viper) *LoggerDocument {
+	doc := &LoggerDocument{
+		Message:   v.GetString("logger.document.message"),
+		Level:     v.GetString("logger.document.level"),
+		Timestamp: v.GetString("logger.document.timestamp"),
+		Fields:    v.GetString("logger.document.fields"),
+		Schema:    v.GetString("logger.document.schema"),
+	}
+
+	setDefaults(v, "logger.document", doc)
+
+	return doc
+}
+
+// getLoggerSpoolConfig get logger search spool config
+func getLoggerSpoolConfig(v *viper.Viper) *LoggerSpool {
+	spool := &LoggerSpool{
+		Dir:           v.GetString("logger.spool.dir"),
+		MaxSizeMB:     v.GetInt("logger.spool.max_size_mb"),
+		RetryInterval: v.GetDuration("logger.spool.retry_interval"),
+	}
+
+	setDefaults(v, "logger.spool", spool)
+
+	return spool
+}
+
+// getLoggerCloudWatchConfig get logger CloudWatch Logs hook config
+func getLoggerCloudWatchConfig(v *viper.Viper) *LoggerCloudWatch {
+	cw := &LoggerCloudWatch{
+		Region:    v.GetString("logger.cloudwatch.region"),
+		LogGroup:  v.GetString("logger.cloudwatch.log_group"),
+		LogStream: v.GetString("logger.cloudwatch.log_stream"),
+		Endpoint:  v.GetString("logger.cloudwatch.endpoint"),
+	}
+
+	// Set default values if not set
+	if cw.LogStream == "" {
+		if host, err := os.Hostname(); err == nil {
+			cw.LogStream = host
+		}
+	}
+
+	return cw
+}
+
+// getLoggerLokiConfig get logger Loki hook config
+func getLoggerLokiConfig(v *viper.Viper) *LoggerLoki {
+	loki := &LoggerLoki{
+		URL:      v.GetString("logger.loki.url"),
+		TenantID: v.GetString("logger.loki.tenant_id"),
+		Username: v.GetString("logger.loki.username"),
+		Password: v.GetString("logger.loki.password"),
+		Labels:   v.GetStringMapString("logger.loki.labels"),
+	}
+
+	// Set default labels if not set
+	if _, ok := loki.Labels["app"]; !ok {
+		loki.Labels["app"] = v.GetString("app_name")
+	}
+	if _, ok := loki.Labels["env"]; !ok {
+		loki.Labels["env"] = v.GetString("run_mode")
+	}
+
+	return loki
+}
+
+// getLoggerKafkaConfig get logger Kafka hook config
+func getLoggerKafkaConfig(v *viper.Viper) *LoggerKafka {
+	kafka := &LoggerKafka{
+		Brokers:      v.GetStringSlice("logger.kafka.brokers"),
+		Topic:        v.GetString("logger.kafka.topic"),
+		KeyField:     v.GetString("logger.kafka.key_field"),
+		BatchTimeout: v.GetDuration("logger.kafka.batch_timeout"),
+	}
+
+	setDefaults(v, "logger.kafka", kafka)
+	if len(kafka.Brokers) == 0 {
+		kafka.Brokers = v.GetStringSlice("data.kafka.brokers")
+	}
+
+	return kafka
+}
+
+// getLoggerSyslogConfig get logger syslog output config
+func getLoggerSyslogConfig(v *viper.Viper) *LoggerSyslog {
+	syslog := &LoggerSyslog{
+		Network:            v.GetString("logger.syslog.network"),
+		Address:            v.GetString("logger.syslog.address"),
+		Facility:           v.GetString("logger.syslog.facility"),
+		Tag:                v.GetString("logger.syslog.tag"),
+		InsecureSkipVerify: v.GetBool("logger.syslog.insecure_skip_verify"),
+	}
+
+	setDefaults(v, "logger.syslog", syslog)
+	if syslog.Tag == "" {
+		syslog.Tag = v.GetString("app_name")
+	}
+
+	return syslog
+}
+
+// getLoggerOTLPConfig get logger OTLP exporter config
+func getLoggerOTLPConfig(v *viper.Viper) *LoggerOTLP {
+	otlp := &LoggerOTLP{
+		Endpoint:    v.GetString("logger.otlp.endpoint"),
+		Protocol:    v.GetString("logger.otlp.protocol"),
+		Insecure:    v.GetBool("logger.otlp.insecure"),
+		Headers:     v.GetStringMapString("logger.otlp.headers"),
+		ServiceName: v.GetString("logger.otlp.service_name"),
+	}
+
+	setDefaults(v, "logger.otlp", otlp)
+	if otlp.ServiceName == "" {
+		otlp.ServiceName = v.GetString("app_name")
+	}
+
+	return otlp
+}
+
+// getLoggerFluentdConfig get logger Fluentd hook config
+func getLoggerFluentdConfig(v *viper.Viper) *LoggerFluentd {
+	fluentd := &LoggerFluentd{
+		Host:    v.GetString("logger.fluentd.host"),
+		Port:    v.GetInt("logger.fluentd.port"),
+		Tag:     v.GetString("logger.fluentd.tag"),
+		Timeout: v.GetDuration("logger.fluentd.timeout"),
+	}
+
+	setDefaults(v, "logger.fluentd", fluentd)
+	if fluentd.Tag == "" {
+		fluentd.Tag = v.GetString("app_name")
+	}
+
+	return fluentd
+}
+
+// getLoggerSentryConfig get logger Sentry hook config
+func getLoggerSentryConfig(v *viper.Viper) *LoggerSentry {
+	sentry := &LoggerSentry{
+		DSN:         v.GetString("logger.sentry.dsn"),
+		Environment: v.GetString("logger.sentry.environment"),
+		SampleRate:  v.GetFloat64("logger.sentry.sample_rate"),
+	}
+
+	// Set default values if not set
+	if sentry.Environment == "" {
+		sentry.Environment = v.GetString("run_mode")
+	}
+
+	return sentry
+}
+
+// getLoggerGraylogConfig get logger Graylog hook config
+func getLoggerGraylogConfig(v *viper.Viper) *LoggerGraylog {
+	graylog := &LoggerGraylog{
+		Network:   v.GetString("logger.graylog.network"),
+		Address:   v.GetString("logger.graylog.address"),
+		ChunkSize: v.GetInt("logger.graylog.chunk_size"),
+	}
+
+	setDefaults(v, "logger.graylog", graylog)
+
+	return graylog
 }
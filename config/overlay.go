@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// AppEnvVar selects the config overlay, e.g. APP_ENV=prod merges
+// config.prod.yaml over config.yaml
+const AppEnvVar = "APP_ENV"
+
+// overlayPath returns the overlay of a config file for APP_ENV in the same
+// directory, e.g. config.prod.yaml, or .env.prod for .env, empty when APP_ENV
+// is not set
+func overlayPath(file string) string {
+	env := os.Getenv(AppEnvVar)
+	if env == "" || file == "" {
+		return ""
+	}
+	ext := filepath.Ext(file)
+	if ext == filepath.Base(file) {
+		return file + "." + env
+	}
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// mergeOverlay merges the overlay of the config file read by v by deep key,
+// keys of the overlay replacing those of the base file. A missing overlay is
+// skipped so environments without differences need no file.
+func mergeOverlay(v *viper.Viper) error {
+	path := overlayPath(v.ConfigFileUsed())
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open config overlay: %w", err)
+	}
+	defer f.Close()
+
+	// The overlay is parsed in the format of the base file, MergeConfig needs
+	// it set explicitly when the base file was read by SetConfigFile
+	cf, err := configFormat(v)
+	if err != nil {
+		return err
+	}
+	if cf != "" {
+		v.SetConfigType(cf)
+	}
+	if err := v.MergeConfig(f); err != nil {
+		return fmt.Errorf("failed to merge config overlay %s: %w", path, err)
+	}
+	return nil
+}
+
+// watchOverlay calls fn when the overlay of the config file is written,
+// created or removed, watching its directory so it may appear later
+func watchOverlay(file string, fn func()) {
+	path := overlayPath(file)
+	if path == "" {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Printf("Error watching config overlay: %v\n", err)
+		return
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		fmt.Printf("Error watching config overlay: %v\n", err)
+		_ = watcher.Close()
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == filepath.Clean(path) &&
+					event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					fn()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("Error watching config overlay: %v\n", err)
+			}
+		}
+	}()
+}
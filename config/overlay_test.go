@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverlayPath(t *testing.T) {
+	t.Setenv(AppEnvVar, "prod")
+	for file, want := range map[string]string{
+		"/etc/app/config.yaml": "/etc/app/config.prod.yaml",
+		"config.toml":          "config.prod.toml",
+		"/srv/.env":            "/srv/.env.prod",
+	} {
+		if got := overlayPath(file); got != want {
+			t.Errorf("overlayPath(%q) = %q, want %q", file, got, want)
+		}
+	}
+
+	t.Setenv(AppEnvVar, "")
+	if got := overlayPath("config.yaml"); got != "" {
+		t.Errorf("expected no overlay without %s, got %q", AppEnvVar, got)
+	}
+}
+
+func TestLoadConfig_Overlay(t *testing.T) {
+	dir := t.TempDir()
+	base := "app_name: demo\nserver:\n  host: 0.0.0.0\n  port: 3000\n"
+	overlay := "server:\n  port: 8080\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(base), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.staging.yaml"), []byte(overlay), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(AppEnvVar, "staging")
+	cfg, err := LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Keys missing from the overlay keep their base values
+	if cfg.AppName != "demo" || cfg.Host != "0.0.0.0" || cfg.Port != 8080 {
+		t.Errorf("got app name %q, host %q and port %d", cfg.AppName, cfg.Host, cfg.Port)
+	}
+
+	// An environment without overlay uses the base file
+	t.Setenv(AppEnvVar, "dev")
+	cfg, err = LoadConfig(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 3000 {
+		t.Errorf("port = %d, want 3000", cfg.Port)
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces secrets in redacted output
+const RedactedValue = "******"
+
+// sensitiveKeys are matched against normalized field names, either exactly or as a suffix
+var sensitiveKeys = []string{"password", "secret", "token", "key", "credentials", "dsn"}
+
+// dsnCredentials matches "user:password@" in driver DSNs, e.g. "root:pass@tcp(127.0.0.1:3306)/db"
+var dsnCredentials = regexp.MustCompile(`^([^:@/\s]+):([^@\s]+)@`)
+
+// Redacted returns the configuration as a generic map with secrets masked,
+// passwords embedded in connection strings are masked as well
+//
+// Fields are keyed by their json tag or name. Embedded sections, e.g. the
+// Database and RabbitMQ of Data, are kept under their own key, flattening
+// them like encoding/json would drop their colliding fields such as Password.
+func (c *Config) Redacted() (map[string]any, error) {
+	v, err := plainValue(reflect.ValueOf(c))
+	if err != nil {
+		return nil, err
+	}
+	out, _ := v.(map[string]any)
+	if out == nil {
+		out = map[string]any{}
+	}
+	redactMap(out)
+	return out, nil
+}
+
+// plainValue converts v to maps, slices and scalars, values implementing
+// json.Marshaler, e.g. time.Time, are converted through their JSON
+func plainValue(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Pointer && v.Type().Implements(marshalerType) {
+			return jsonValue(v)
+		}
+		return plainValue(v.Elem())
+	}
+	if v.Type().Implements(marshalerType) {
+		return jsonValue(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		out := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" || f.Anonymous {
+				name = f.Name
+			}
+			fv, err := plainValue(v.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			out[name] = fv
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			mv, err := plainValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(iter.Key().Interface())] = mv
+		}
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return jsonValue(v)
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			ev, err := plainValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = ev
+		}
+		return out, nil
+	}
+	return v.Interface(), nil
+}
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+// jsonValue converts v through its JSON encoding
+func jsonValue(v reflect.Value) (any, error) {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return out, nil
+}
+
+// redactMap masks sensitive values in place
+func redactMap(m map[string]any) {
+	for k, v := range m {
+		if isSensitiveKey(k) {
+			if s, ok := v.(string); !ok || s != "" {
+				m[k] = RedactedValue
+			}
+			continue
+		}
+		m[k] = redactValue(v)
+	}
+}
+
+// redactValue masks nested values and credentials inside connection strings
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		redactMap(val)
+	case []any:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+	case string:
+		return redactConnectionString(val)
+	}
+	return v
+}
+
+// isSensitiveKey reports whether a field name holds a secret
+func isSensitiveKey(key string) bool {
+	k := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, s := range sensitiveKeys {
+		if strings.HasSuffix(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConnectionString masks the password of URLs and driver DSNs
+func redactConnectionString(s string) string {
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), RedactedValue)
+			return u.String()
+		}
+	}
+	return dsnCredentials.ReplaceAllString(s, "${1}:"+RedactedValue+"@")
+}
+
+// Dump writes the configuration as indented JSON with secrets masked
+func (c *Config) Dump(w io.Writer) error {
+	redacted, err := c.Redacted()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(redacted)
+}
+
+// Dump writes the effective configuration, including overlays, remote
+// values, environment overrides and resolved secrets, with secrets masked,
+// e.g. for a startup banner
+func Dump(w io.Writer) error {
+	cfg, err := current()
+	if err != nil {
+		return err
+	}
+	return cfg.Dump(w)
+}
+
+// DumpHandler serves the effective configuration with secrets masked, e.g.
+// mounted at /debug/config of an admin server
+func DumpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := Dump(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	dc "ncobase/common/data/config"
+)
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := &Config{
+		AppName: "demo",
+		Auth:    &Auth{JWT: &JWT{Secret: "jwt-secret", Expire: 3600}},
+		Data: &dc.Config{
+			Database: &dc.Database{Master: &dc.DBNode{Driver: "mysql", Source: "root:hunter2@tcp(127.0.0.1:3306)/app"}},
+			RabbitMQ: &dc.RabbitMQ{URI: "amqp://svc:s3cret@mq:5672/%2F", Password: "s3cret"},
+		},
+	}
+
+	redacted, err := cfg.Redacted()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatalf("failed to marshal redacted config: %v", err)
+	}
+	out := string(b)
+	for _, secret := range []string{"jwt-secret", "hunter2", "s3cret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q leaked in %s", secret, out)
+		}
+	}
+	for _, kept := range []string{"demo", "3600", "127.0.0.1:3306", "mq:5672"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("expected %q to be kept in %s", kept, out)
+		}
+	}
+
+	// Embedded data sections are kept under their own key
+	data, _ := redacted["Data"].(map[string]any)
+	for _, section := range []string{"Database", "RabbitMQ"} {
+		if _, ok := data[section].(map[string]any); !ok {
+			t.Errorf("expected data section %s in %s", section, out)
+		}
+	}
+
+	// The original config is untouched
+	if cfg.Auth.JWT.Secret != "jwt-secret" {
+		t.Error("original config was modified")
+	}
+}
+
+func TestConfig_Dump(t *testing.T) {
+	cfg := &Config{
+		AppName: "demo",
+		Data:    &dc.Config{RabbitMQ: &dc.RabbitMQ{URI: "amqp://svc:s3cret@mq:5672/%2F"}},
+	}
+
+	var b strings.Builder
+	if err := cfg.Dump(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("secret leaked in %s", out)
+	}
+	if !strings.Contains(out, "\n  \"AppName\": \"demo\"") {
+		t.Errorf("expected indented output, got %s", out)
+	}
+}
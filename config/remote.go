@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/spf13/viper"
+)
+
+// Remote config providers
+const (
+	RemoteConsul = "consul"
+	RemoteEtcd   = "etcd"
+)
+
+const (
+	remoteTimeout    = 10 * time.Second
+	remoteRetryDelay = 5 * time.Second
+	consulWaitTime   = 5 * time.Minute
+)
+
+// ErrRemoteKeyNotFound is returned when the remote config key does not exist
+var ErrRemoteKeyNotFound = errors.New("remote config key not found")
+
+// Remote is the remote configuration source, its value is a config document
+// merged beneath the local file and environment variables, e.g.
+//
+//	remote:
+//	  provider: consul
+//	  endpoint: 127.0.0.1:8500
+//	  path: ncobase/config.yaml
+//	  watch: true
+type Remote struct {
+	Provider string `json:"provider" validate:"required,oneof=consul etcd"` // consul or etcd
+	Endpoint string `json:"endpoint" validate:"required"`                   // consul address or etcd URL, consul.address by default
+	Path     string `json:"path" validate:"required"`                       // key of the config document
+	Format   string `json:"format"`                                         // format of the document, from the key extension or yaml by default
+	Watch    bool   `json:"watch"`                                          // reload when the key changes
+}
+
+// getRemoteConfig returns the remote config, nil when no provider is set
+func getRemoteConfig(v *viper.Viper) (*Remote, error) {
+	r := &Remote{
+		Provider: strings.ToLower(v.GetString("remote.provider")),
+		Endpoint: v.GetString("remote.endpoint"),
+		Path:     v.GetString("remote.path"),
+		Format:   v.GetString("remote.format"),
+		Watch:    v.GetBool("remote.watch"),
+	}
+	if r.Provider == "" {
+		return nil, nil
+	}
+
+	// Set default values if not set
+	if r.Provider == "etcd3" {
+		r.Provider = RemoteEtcd
+	}
+	if r.Endpoint == "" && r.Provider == RemoteConsul {
+		r.Endpoint = v.GetString("consul.address")
+	}
+	if r.Format == "" {
+		r.Format = filepath.Ext(r.Path)
+	}
+	format, err := normalizeFormat(r.Format)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = FormatYAML
+	}
+	r.Format = format
+
+	if err := validateStruct(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// remoteSource reads a key of a remote store
+type remoteSource interface {
+	// get returns the value of the key and its revision
+	get(ctx context.Context) ([]byte, uint64, error)
+	// wait blocks until the key changes after revision, returning the new value
+	wait(ctx context.Context, revision uint64) ([]byte, uint64, error)
+}
+
+// newRemoteSource creates the source of the provider
+func newRemoteSource(r *Remote) (remoteSource, error) {
+	switch r.Provider {
+	case RemoteConsul:
+		client, err := api.NewClient(&api.Config{Address: r.Endpoint})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consul client: %w", err)
+		}
+		return &consulSource{kv: client.KV(), key: r.Path}, nil
+	case RemoteEtcd:
+		return &etcdSource{client: http.DefaultClient, endpoint: strings.TrimSuffix(r.Endpoint, "/"), key: r.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported remote config provider %q", r.Provider)
+	}
+}
+
+// mergeRemote returns a viper holding the remote document with the settings
+// of v merged over it, environment variables still taking precedence
+func mergeRemote(v *viper.Viper, r *Remote) (*viper.Viper, error) {
+	src, err := newRemoteSource(r)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	value, _, err := src.get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote config: %w", err)
+	}
+
+	merged := viper.New()
+	merged.SetConfigType(r.Format)
+	if err := merged.ReadConfig(bytes.NewReader(value)); err != nil {
+		return nil, fmt.Errorf("failed to parse remote config: %w", err)
+	}
+	if err := merged.MergeConfigMap(v.AllSettings()); err != nil {
+		return nil, fmt.Errorf("failed to merge remote config: %w", err)
+	}
+	bindEnv(merged)
+	return merged, nil
+}
+
+// watchRemote reloads the configuration every time the remote key changes,
+// retrying on errors
+func watchRemote(r *Remote) {
+	src, err := newRemoteSource(r)
+	if err != nil {
+		fmt.Printf("Error watching remote config: %v\n", err)
+		return
+	}
+	ctx := context.Background()
+	var revision uint64
+	for {
+		var err error
+		if revision == 0 {
+			_, revision, err = src.get(ctx)
+		} else {
+			_, revision, err = src.wait(ctx, revision)
+			if err == nil {
+				reloadAndNotify()
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error watching remote config: %v\n", err)
+			revision = 0
+			time.Sleep(remoteRetryDelay)
+		}
+	}
+}
+
+// consulSource reads a Consul KV key
+type consulSource struct {
+	kv  *api.KV
+	key string
+}
+
+// get returns the value and modify index of the key
+func (s *consulSource) get(ctx context.Context) ([]byte, uint64, error) {
+	return s.query((&api.QueryOptions{}).WithContext(ctx))
+}
+
+// wait runs blocking queries until the index moves past revision
+func (s *consulSource) wait(ctx context.Context, revision uint64) ([]byte, uint64, error) {
+	for {
+		value, index, err := s.query((&api.QueryOptions{WaitIndex: revision, WaitTime: consulWaitTime}).WithContext(ctx))
+		// The index may go backwards when Consul restores a snapshot
+		if err != nil || index != revision {
+			return value, index, err
+		}
+	}
+}
+
+// query reads the key
+func (s *consulSource) query(q *api.QueryOptions) ([]byte, uint64, error) {
+	pair, meta, err := s.kv.Get(s.key, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get consul key %s: %w", s.key, err)
+	}
+	if pair == nil {
+		return nil, meta.LastIndex, fmt.Errorf("%w: %s", ErrRemoteKeyNotFound, s.key)
+	}
+	return pair.Value, meta.LastIndex, nil
+}
+
+// etcdSource reads an etcd key through the v3 JSON gateway, so no gRPC
+// client is needed
+type etcdSource struct {
+	client   *http.Client
+	endpoint string // e.g. http://127.0.0.1:2379
+	key      string
+}
+
+// etcdKV is a key value of the gateway, 64 bit integers are strings
+type etcdKV struct {
+	Value       []byte `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+// get returns the value and modify revision of the key
+func (s *etcdSource) get(ctx context.Context) ([]byte, uint64, error) {
+	resp, err := s.post(ctx, "/v3/kv/range", map[string]any{"key": []byte(s.key)})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Kvs []etcdKV `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+	if len(out.Kvs) == 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrRemoteKeyNotFound, s.key)
+	}
+	return etcdValue(out.Kvs[0])
+}
+
+// wait watches the key from the revision after revision
+func (s *etcdSource) wait(ctx context.Context, revision uint64) ([]byte, uint64, error) {
+	resp, err := s.post(ctx, "/v3/watch", map[string]any{
+		"create_request": map[string]any{
+			"key":            []byte(s.key),
+			"start_revision": strconv.FormatUint(revision+1, 10),
+		},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	// The gateway streams one JSON message per watch response
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			Result struct {
+				Events []struct {
+					Type string `json:"type"`
+					Kv   etcdKV `json:"kv"`
+				} `json:"events"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode etcd watch response: %w", err)
+		}
+		events := msg.Result.Events
+		if len(events) == 0 {
+			continue
+		}
+		last := events[len(events)-1]
+		if last.Type == "DELETE" {
+			return nil, 0, fmt.Errorf("%w: %s", ErrRemoteKeyNotFound, s.key)
+		}
+		return etcdValue(last.Kv)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to watch etcd key %s: %w", s.key, err)
+	}
+	return nil, 0, fmt.Errorf("etcd watch of %s closed", s.key)
+}
+
+// post sends a JSON request to the gateway
+func (s *etcdSource) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request etcd %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("etcd %s returned %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+// etcdValue returns the value and revision of a key value
+func etcdValue(kv etcdKV) ([]byte, uint64, error) {
+	revision, err := strconv.ParseUint(kv.ModRevision, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid etcd revision %q", kv.ModRevision)
+	}
+	return kv.Value, revision, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeEtcd serves the range and watch endpoints of the etcd JSON gateway
+func fakeEtcd(t *testing.T, value string, next string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"kvs": []map[string]any{{"value": []byte(value), "mod_revision": "7"}},
+			})
+		case "/v3/watch":
+			var req struct {
+				CreateRequest struct {
+					StartRevision string `json:"start_revision"`
+				} `json:"create_request"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.CreateRequest.StartRevision != "8" {
+				t.Errorf("start revision = %s, want 8", req.CreateRequest.StartRevision)
+			}
+			fmt.Fprintln(w, `{"result":{"created":true}}`)
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{
+				"events": []map[string]any{{"kv": map[string]any{"value": []byte(next), "mod_revision": "9"}}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestEtcdSource(t *testing.T) {
+	srv := fakeEtcd(t, "a", "b")
+	defer srv.Close()
+	src := &etcdSource{client: srv.Client(), endpoint: srv.URL, key: "app/config.yaml"}
+
+	value, revision, err := src.get(context.Background())
+	if err != nil || string(value) != "a" || revision != 7 {
+		t.Fatalf("get = %q, %d, %v", value, revision, err)
+	}
+	value, revision, err = src.wait(context.Background(), revision)
+	if err != nil || string(value) != "b" || revision != 9 {
+		t.Fatalf("wait = %q, %d, %v", value, revision, err)
+	}
+}
+
+func TestLoadConfig_Remote(t *testing.T) {
+	srv := fakeEtcd(t, "app_name: remote\nserver:\n  port: 3000\n  host: remote-host\n", "")
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := fmt.Sprintf("server:\n  port: 4000\nremote:\n  provider: etcd\n  endpoint: %s\n  path: app/config.yaml\n", srv.URL)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NCO_SERVER_HOST", "env-host")
+
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Remote beneath the file, the environment over both
+	if cfg.AppName != "remote" || cfg.Port != 4000 || cfg.Host != "env-host" {
+		t.Errorf("got app name %q, port %d and host %q", cfg.AppName, cfg.Port, cfg.Host)
+	}
+	if cfg.Remote == nil || cfg.Remote.Format != FormatYAML {
+		t.Errorf("unexpected remote config %+v", cfg.Remote)
+	}
+}
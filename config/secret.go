@@ -0,0 +1,271 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// secretTimeout bounds the resolution of all secrets of a load
+const secretTimeout = 30 * time.Second
+
+// secretRef matches references like ${vault:secret/data/db#password}
+var secretRef = regexp.MustCompile(`\$\{([a-z][a-z0-9]*):([^}]+)\}`)
+
+// SecretProvider resolves secret references of a scheme, ref is the part
+// after the scheme, e.g. secret/data/db#password for ${vault:secret/data/db#password}
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretProviderFunc adapts a function to a SecretProvider
+type SecretProviderFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f
+func (f SecretProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretProviders = map[string]SecretProvider{
+		"vault": &vaultProvider{client: http.DefaultClient},
+		"awssm": &awsSecretsProvider{},
+	}
+	secretProvidersMu sync.RWMutex
+)
+
+// RegisterSecretProvider registers the provider of a scheme, replacing the
+// built-in vault and awssm providers when registered under their scheme
+func RegisterSecretProvider(scheme string, p SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[scheme] = p
+}
+
+// secretProvider returns the provider of a scheme
+func secretProvider(scheme string) (SecretProvider, bool) {
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+	p, ok := secretProviders[scheme]
+	return p, ok
+}
+
+// resolveSecrets decrypts the encrypted values and replaces the secret
+// references in the string values of cfg, whatever layer they come from,
+// resolving each reference once
+func resolveSecrets(ctx context.Context, cfg *Config) error {
+	ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+	defer cancel()
+	r := &secretResolver{ctx: ctx, cache: make(map[string]string)}
+	r.walk(reflect.ValueOf(cfg))
+	return r.err
+}
+
+// secretResolver walks a value resolving references, keeping the first error
+type secretResolver struct {
+	ctx   context.Context
+	cache map[string]string
+	err   error
+}
+
+// walk resolves the strings reachable from v
+func (r *secretResolver) walk(v reflect.Value) {
+	if r.err != nil {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Interface && v.Elem().Kind() == reflect.String {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(r.resolve(v.Elem().String())).Convert(v.Elem().Type()))
+			}
+			return
+		}
+		r.walk(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				r.walk(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.walk(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			val := iter.Value()
+			switch {
+			case val.Kind() == reflect.String:
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(r.resolve(val.String())).Convert(val.Type()))
+			case val.Kind() == reflect.Interface && !val.IsNil() && val.Elem().Kind() == reflect.String:
+				v.SetMapIndex(iter.Key(), reflect.ValueOf(r.resolve(val.Elem().String())).Convert(val.Elem().Type()))
+			default:
+				r.walk(val)
+			}
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(r.resolve(v.String()))
+		}
+	}
+}
+
+// resolve decrypts the ENC[...] values of s and replaces its references
+func (r *secretResolver) resolve(s string) string {
+	if r.err != nil {
+		return s
+	}
+	if strings.Contains(s, "ENC[") {
+		decrypted, err := decryptValue(r.ctx, s)
+		if err != nil {
+			r.err = err
+			return s
+		}
+		s = decrypted
+	}
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return secretRef.ReplaceAllStringFunc(s, func(match string) string {
+		if r.err != nil {
+			return match
+		}
+		if value, ok := r.cache[match]; ok {
+			return value
+		}
+		m := secretRef.FindStringSubmatch(match)
+		p, ok := secretProvider(m[1])
+		if !ok {
+			r.err = fmt.Errorf("unknown secret provider %q in %s", m[1], match)
+			return match
+		}
+		value, err := p.Resolve(r.ctx, m[2])
+		if err != nil {
+			r.err = fmt.Errorf("failed to resolve %s: %w", match, err)
+			return match
+		}
+		r.cache[match] = value
+		return value
+	})
+}
+
+// splitSecretRef splits a reference into the secret path and the field after #
+func splitSecretRef(ref string) (path, field string) {
+	path, field, _ = strings.Cut(ref, "#")
+	return path, field
+}
+
+// vaultProvider reads Vault secrets with VAULT_ADDR and VAULT_TOKEN,
+// ${vault:secret/data/db#password} reads the password field of a KV v2
+// secret, KV v1 paths like secret/db work as well
+type vaultProvider struct {
+	client *http.Client
+}
+
+// Resolve reads the field of the secret
+func (p *vaultProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field := splitSecretRef(ref)
+	if field == "" {
+		return "", errors.New("vault reference needs a field, e.g. secret/data/db#password")
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var out struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	data := out.Data
+	// KV v2 nests the secret under data.data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, meta := data["metadata"]; meta {
+			data = nested
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// awsSecretsProvider reads AWS Secrets Manager secrets with the default
+// credential chain, ${awssm:prod/db-pass} is the whole secret string and
+// ${awssm:prod/db#password} a field of a JSON secret
+type awsSecretsProvider struct {
+	once sync.Once
+	api  *secretsmanager.SecretsManager
+	err  error
+}
+
+// Resolve reads the secret or its field
+func (p *awsSecretsProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	p.once.Do(func() {
+		sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+		if err != nil {
+			p.err = fmt.Errorf("failed to create AWS session: %w", err)
+			return
+		}
+		p.api = secretsmanager.New(sess)
+	})
+	if p.err != nil {
+		return "", p.err
+	}
+
+	id, field := splitSecretRef(ref)
+	out, err := p.api.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", id, err)
+	}
+	value := aws.StringValue(out.SecretString)
+	if field == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %s", id, field)
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dc "ncobase/common/data/config"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	calls := 0
+	RegisterSecretProvider("test", SecretProviderFunc(func(_ context.Context, ref string) (string, error) {
+		calls++
+		return strings.ToUpper(ref), nil
+	}))
+
+	cfg := &Config{
+		AppName: "demo",
+		Auth:    &Auth{JWT: &JWT{Secret: "${test:jwt}"}},
+		Data: &dc.Config{
+			RabbitMQ: &dc.RabbitMQ{URI: "amqp://svc:${test:mq}@mq:5672", Password: "${test:mq}"},
+		},
+		Consul: &Consul{},
+	}
+	cfg.Consul.Discovery.DefaultMeta = map[string]string{"token": "${test:meta}"}
+
+	if err := resolveSecrets(context.Background(), cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Auth.JWT.Secret != "JWT" {
+		t.Errorf("jwt secret = %q", cfg.Auth.JWT.Secret)
+	}
+	if cfg.Data.RabbitMQ.URI != "amqp://svc:MQ@mq:5672" || cfg.Data.RabbitMQ.Password != "MQ" {
+		t.Errorf("rabbitmq = %q, %q", cfg.Data.RabbitMQ.URI, cfg.Data.RabbitMQ.Password)
+	}
+	if cfg.Consul.Discovery.DefaultMeta["token"] != "META" {
+		t.Errorf("meta = %q", cfg.Consul.Discovery.DefaultMeta["token"])
+	}
+	if calls != 3 {
+		t.Errorf("provider called %d times, want 3", calls)
+	}
+}
+
+func TestResolveSecrets_UnknownProvider(t *testing.T) {
+	cfg := &Config{AppName: "${nope:x}"}
+	if err := resolveSecrets(context.Background(), cfg); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/db" || r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":1}}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	p := &vaultProvider{client: srv.Client()}
+	value, err := p.Resolve(context.Background(), "secret/data/db#password")
+	if err != nil || value != "hunter2" {
+		t.Fatalf("Resolve = %q, %v", value, err)
+	}
+	if _, err := p.Resolve(context.Background(), "secret/data/db#missing"); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+var (
+	sections   = map[string]any{}
+	sectionsMu sync.RWMutex
+)
+
+// RegisterSection registers the config section of an extension, e.g.
+//
+//	var payments struct {
+//		Gateway string        `validate:"required"`
+//		Timeout time.Duration `default:"30s"`
+//	}
+//	config.RegisterSection("payments", &payments)
+//
+// The section is read from the same layers as the built-in sections, its keys
+// are the json tag names or the snake case field names, default tags apply,
+// secret references and encrypted values are resolved and validate tags are
+// checked by Validate. target must be a pointer to a struct, it is updated
+// every time the configuration is loaded or reloaded, before the OnChange
+// subscribers are called. Register sections before Init, or the section is
+// read from the current configuration and checked on the next reload.
+func RegisterSection(name string, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config section %s: target must be a pointer to a struct, got %T", name, target)
+	}
+
+	sectionsMu.Lock()
+	if _, ok := sections[name]; ok {
+		sectionsMu.Unlock()
+		return fmt.Errorf("config section %s is already registered", name)
+	}
+	sections[name] = target
+	sectionsMu.Unlock()
+
+	mu.Lock()
+	cfg := config
+	mu.Unlock()
+	if cfg == nil || cfg.settings == nil {
+		return nil
+	}
+	value, err := loadSection(context.Background(), cfg.settings, name, rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+	if err := validateStruct(value); err != nil {
+		return fmt.Errorf("config section %s: %w", name, err)
+	}
+	rv.Elem().Set(reflect.ValueOf(value).Elem())
+	return nil
+}
+
+// loadSections reads the registered sections from v
+func loadSections(ctx context.Context, v *viper.Viper) (map[string]any, error) {
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+	if len(sections) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]any, len(sections))
+	for name, target := range sections {
+		value, err := loadSection(ctx, v, name, reflect.TypeOf(target).Elem())
+		if err != nil {
+			return nil, err
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// loadSection decodes a section of type t from v, returning a pointer to it
+func loadSection(ctx context.Context, v *viper.Viper, name string, t reflect.Type) (any, error) {
+	value := reflect.New(t)
+	err := v.UnmarshalKey(name, value.Interface(), func(c *mapstructure.DecoderConfig) {
+		c.TagName = "json"
+		c.MatchName = func(mapKey, fieldName string) bool {
+			return strings.EqualFold(mapKey, fieldName) || strings.EqualFold(mapKey, snakeCase(fieldName))
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config section %s: %w", name, err)
+	}
+	setDefaults(v, name, value.Interface())
+
+	ctx, cancel := context.WithTimeout(ctx, secretTimeout)
+	defer cancel()
+	r := &secretResolver{ctx: ctx, cache: make(map[string]string)}
+	r.walk(value)
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to resolve config section %s secrets: %w", name, r.err)
+	}
+	return value.Interface(), nil
+}
+
+// sectionErrors returns the fields of the sections of c failing their
+// validate tags, named after the section, e.g. payments.Gateway
+func (c *Config) sectionErrors() ([]FieldError, error) {
+	names := make([]string, 0, len(c.sections))
+	for name := range c.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []FieldError
+	for _, name := range names {
+		fes, err := structErrors(c.sections[name])
+		if err != nil {
+			return nil, err
+		}
+		for _, fe := range fes {
+			if _, rest, ok := strings.Cut(fe.Field, "."); ok {
+				field := name + "." + rest
+				fe.Message = strings.Replace(fe.Message, fe.Field, field, 1)
+				fe.Field = field
+			}
+			fields = append(fields, fe)
+		}
+	}
+	return fields, nil
+}
+
+// applySections updates the registered targets with the sections of c
+func applySections(c *Config) {
+	if c == nil {
+		return
+	}
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+	for name, value := range c.sections {
+		if target, ok := sections[name]; ok {
+			reflect.ValueOf(target).Elem().Set(reflect.ValueOf(value).Elem())
+		}
+	}
+}
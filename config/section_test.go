@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type paymentsSection struct {
+	Gateway  string        `validate:"required"`
+	MaxRetry int           `validate:"min=0"`
+	Timeout  time.Duration `default:"30s"`
+}
+
+func TestRegisterSection(t *testing.T) {
+	var payments paymentsSection
+	if err := RegisterSection("payments", &payments); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		sectionsMu.Lock()
+		delete(sections, "payments")
+		sectionsMu.Unlock()
+	})
+	if err := RegisterSection("payments", &payments); err == nil {
+		t.Error("expected an error registering a section twice")
+	}
+	if err := RegisterSection("other", payments); err == nil {
+		t.Error("expected an error for a non pointer target")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte("payments:\n  gateway: stripe\n  max_retry: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	applySections(cfg)
+	if payments.Gateway != "stripe" || payments.MaxRetry != 3 || payments.Timeout != 30*time.Second {
+		t.Errorf("got section %+v", payments)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("payments:\n  max_retry: -1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadConfig(invalid)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = true
+	}
+	if !fields["payments.Gateway"] || !fields["payments.MaxRetry"] {
+		t.Errorf("unexpected fields %+v", verr.Fields)
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Strict modes, unknown and deprecated keys are ignored by default
+const (
+	StrictOff   = ""
+	StrictWarn  = "warn"
+	StrictError = "error"
+)
+
+// strict is the strict mode of the configuration loading
+var strict string
+
+var (
+	// allowedKeys are the key prefixes read outside of the Config struct
+	allowedKeys []string
+	// deprecatedKeys are the replacements of deprecated keys
+	deprecatedKeys = map[string]string{}
+	strictMu       sync.RWMutex
+)
+
+func init() {
+	flag.StringVar(&strict, "conf-strict", "", "report unknown and deprecated config keys: warn or error")
+}
+
+// SetStrict sets the strict mode, StrictWarn prints the unknown and
+// deprecated keys of the loaded layers and StrictError fails the load,
+// catching typos like levle: debug that fall back to defaults otherwise
+func SetStrict(mode string) error {
+	switch mode {
+	case StrictOff, StrictWarn, StrictError:
+	default:
+		return fmt.Errorf("unsupported strict mode %q", mode)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	strict = mode
+	return nil
+}
+
+// AllowKeys declares key prefixes read outside of the Config struct and the
+// registered sections, e.g. with Get, so strict mode accepts them
+func AllowKeys(prefixes ...string) {
+	strictMu.Lock()
+	defer strictMu.Unlock()
+	for _, p := range prefixes {
+		allowedKeys = append(allowedKeys, strings.ToLower(p))
+	}
+}
+
+// DeprecateKey declares a key, or a section and its keys, replaced by
+// another one, strict mode reports its use with the replacement
+func DeprecateKey(old, replacement string) {
+	strictMu.Lock()
+	defer strictMu.Unlock()
+	deprecatedKeys[strings.ToLower(old)] = strings.ToLower(replacement)
+}
+
+// KeyIssue is an unknown or deprecated key
+type KeyIssue struct {
+	Key        string
+	Suggestion string // closest known key or replacement, if any
+	Deprecated bool
+}
+
+// String describes the issue
+func (k KeyIssue) String() string {
+	switch {
+	case k.Deprecated:
+		return fmt.Sprintf("config key %s is deprecated, use %s", k.Key, k.Suggestion)
+	case k.Suggestion != "":
+		return fmt.Sprintf("unknown config key %s, did you mean %s?", k.Key, k.Suggestion)
+	default:
+		return "unknown config key " + k.Key
+	}
+}
+
+// KeyError lists the unknown and deprecated keys found in strict mode
+type KeyError struct {
+	Keys []KeyIssue
+}
+
+// Error returns the issues joined
+func (e *KeyError) Error() string {
+	msgs := make([]string, len(e.Keys))
+	for i, k := range e.Keys {
+		msgs[i] = k.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// checkKeys reports the unknown and deprecated keys of v in strict mode,
+// dotenv files are not checked as they hold variable names
+func checkKeys(v *viper.Viper) error {
+	if strict == StrictOff {
+		return nil
+	}
+	if f, err := configFormat(v); err == nil && f == FormatDotenv {
+		return nil
+	}
+
+	issues := keyIssues(v.AllKeys())
+	if len(issues) == 0 {
+		return nil
+	}
+	if strict == StrictError {
+		return &KeyError{Keys: issues}
+	}
+	for _, issue := range issues {
+		fmt.Printf("Warning: %s\n", issue)
+	}
+	return nil
+}
+
+// keyIssues returns the unknown and deprecated keys among keys
+func keyIssues(keys []string) []KeyIssue {
+	known := newKnownKeys()
+
+	strictMu.RLock()
+	defer strictMu.RUnlock()
+	var issues []KeyIssue
+	for _, key := range keys {
+		if old, replacement, ok := deprecation(key); ok {
+			issues = append(issues, KeyIssue{Key: key, Suggestion: replacement + strings.TrimPrefix(key, old), Deprecated: true})
+			continue
+		}
+		if !known.has(key) {
+			issues = append(issues, KeyIssue{Key: key, Suggestion: known.closest(key)})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// deprecation returns the deprecated key or section of key and its
+// replacement, the caller holds strictMu
+func deprecation(key string) (old, replacement string, ok bool) {
+	for old, replacement := range deprecatedKeys {
+		if key == old || strings.HasPrefix(key, old+".") {
+			return old, replacement, true
+		}
+	}
+	return "", "", false
+}
+
+// knownKeys are the keys of the Config struct and the registered sections
+type knownKeys struct {
+	exact   map[string]bool
+	parents map[string]bool
+	// open are the prefixes accepting any key, e.g. maps and lists of structs
+	open []string
+}
+
+// newKnownKeys collects the known keys
+func newKnownKeys() *knownKeys {
+	k := &knownKeys{exact: map[string]bool{IncludeKey: true}, parents: map[string]bool{}}
+	add := func(key string, t reflect.Type) {
+		switch t.Kind() {
+		case reflect.Map, reflect.Interface:
+			k.open = append(k.open, key)
+		case reflect.Slice:
+			if t.Elem().Kind() != reflect.String {
+				k.open = append(k.open, key)
+			}
+		}
+		k.exact[key] = true
+		for p := key; strings.Contains(p, "."); {
+			p = p[:strings.LastIndex(p, ".")]
+			k.parents[p] = true
+		}
+	}
+	walkKeys(reflect.TypeOf(Config{}), "", add)
+
+	sectionsMu.RLock()
+	for name, target := range sections {
+		k.parents[name] = true
+		walkKeys(reflect.TypeOf(target).Elem(), name, add)
+	}
+	sectionsMu.RUnlock()
+
+	strictMu.RLock()
+	k.open = append(k.open, allowedKeys...)
+	strictMu.RUnlock()
+	return k
+}
+
+// has reports whether key is known
+func (k *knownKeys) has(key string) bool {
+	if k.exact[key] || k.parents[key] {
+		return true
+	}
+	for _, p := range k.open {
+		if key == p || strings.HasPrefix(key, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// closest returns the known key nearest to key, at most two edits away
+func (k *knownKeys) closest(key string) string {
+	best, bestDist := "", 3
+	for known := range k.exact {
+		if d := editDistance(key, known); d < bestDist || (d == bestDist && known < best) {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
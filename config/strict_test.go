@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKeyIssues(t *testing.T) {
+	DeprecateKey("logger.cloud_watch", "logger.cloudwatch")
+	AllowKeys("payments")
+	t.Cleanup(func() {
+		strictMu.Lock()
+		delete(deprecatedKeys, "logger.cloud_watch")
+		allowedKeys = nil
+		strictMu.Unlock()
+	})
+
+	issues := keyIssues([]string{
+		"logger.level",
+		"logger.levle",
+		"logger.modules.payments",
+		"data.database.slaves",
+		"server.port",
+		"payments.timeout",
+		"logger.cloud_watch.region",
+		"unrelated",
+	})
+	want := []KeyIssue{
+		{Key: "logger.cloud_watch.region", Suggestion: "logger.cloudwatch.region", Deprecated: true},
+		{Key: "logger.levle", Suggestion: "logger.level"},
+		{Key: "unrelated"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("got issues %+v, want %+v", issues, want)
+	}
+}
+
+func TestLoadConfig_Strict(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("logger:\n  levle: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(file); err != nil {
+		t.Fatalf("unknown keys must be ignored by default, got %v", err)
+	}
+
+	if err := SetStrict(StrictError); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { strict = StrictOff })
+	_, err := LoadConfig(file)
+	var kerr *KeyError
+	if !errors.As(err, &kerr) || len(kerr.Keys) != 1 || kerr.Keys[0].Suggestion != "logger.level" {
+		t.Errorf("expected a KeyError suggesting logger.level, got %v", err)
+	}
+
+	if err := SetStrict("fatal"); err == nil {
+		t.Error("expected an error for an unsupported mode")
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate checks the validate struct tags of the configuration
+var validate = newValidator()
+
+// newValidator creates the validator, hostport is an alias of hostname_port
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterAlias("hostport", "hostname_port")
+	return v
+}
+
+// FieldError is an invalid configuration field
+type FieldError struct {
+	Field   string // struct path, e.g. Logger.Format
+	Rule    string // failed rule, e.g. oneof=json text
+	Message string
+}
+
+// ValidationError lists every invalid field of a configuration
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error returns the messages of all invalid fields
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Message
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the validate struct tags of the configuration and the
+// logger settings, returning a *ValidationError listing every invalid field
+func (c *Config) Validate() error {
+	fields, err := structErrors(c)
+	if err != nil {
+		return err
+	}
+	if c.Logger != nil {
+		if err := c.Logger.Validate(); err != nil {
+			fields = append(fields, FieldError{Field: "Logger", Message: err.Error()})
+		}
+	}
+	sectionFields, err := c.sectionErrors()
+	if err != nil {
+		return err
+	}
+	fields = append(fields, sectionFields...)
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateStruct checks the validate tags of a config section
+func validateStruct(s any) error {
+	fields, err := structErrors(s)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// structErrors returns the fields of s failing their validate tags
+func structErrors(s any) ([]FieldError, error) {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil, nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil, fmt.Errorf("failed to validate config: %w", err)
+	}
+	fields := make([]FieldError, len(verrs))
+	for i, fe := range verrs {
+		fields[i] = fieldError(fe)
+	}
+	return fields, nil
+}
+
+// fieldError describes a failed tag, masking secret values
+func fieldError(fe validator.FieldError) FieldError {
+	field := strings.TrimPrefix(fe.Namespace(), "Config.")
+	rule := fe.Tag()
+	if fe.Param() != "" {
+		rule += "=" + fe.Param()
+	}
+
+	var msg string
+	switch fe.Tag() {
+	case "required":
+		msg = field + " is required"
+	case "oneof":
+		msg = fmt.Sprintf("%s must be one of %s", field, fe.Param())
+	case "min":
+		msg = fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		msg = fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "url":
+		msg = field + " must be a URL"
+	case "hostport", "hostname_port":
+		msg = field + " must be host:port"
+	default:
+		msg = fmt.Sprintf("%s fails %s", field, rule)
+	}
+	if fe.Tag() != "required" {
+		value := fmt.Sprint(fe.Value())
+		if isSensitiveKey(fe.Field()) {
+			value = RedactedValue
+		}
+		msg += fmt.Sprintf(", got %q", redactConnectionString(value))
+	}
+	return FieldError{Field: field, Rule: rule, Message: msg}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	dc "ncobase/common/data/config"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := &Config{
+		Port:   70000,
+		Logger: &Logger{Format: "xml", MaxBackups: -1, Outputs: []LoggerOutput{{Type: "printer"}}},
+		Data: &dc.Config{
+			RabbitMQ: &dc.RabbitMQ{URI: "not a url with s3cret"},
+		},
+		Consul: &Consul{Address: "consul"},
+	}
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	got := map[string]bool{}
+	for _, f := range verr.Fields {
+		got[f.Field] = true
+	}
+	for _, field := range []string{
+		"Port",
+		"Logger.Format",
+		"Logger.MaxBackups",
+		"Logger.Outputs[0].Type",
+		"Data.RabbitMQ.URI",
+		"Consul.Address",
+		"Logger",
+	} {
+		if !got[field] {
+			t.Errorf("expected %s to be reported in %v", field, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "Logger.Format must be one of json gelf ecs console text") {
+		t.Errorf("unexpected message %v", err)
+	}
+}
+
+func TestConfig_ValidateValid(t *testing.T) {
+	cfg := &Config{
+		Port:   8080,
+		Logger: &Logger{Format: "json", Output: "stdout"},
+		Consul: &Consul{Address: "127.0.0.1:8500", Scheme: "http"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"ncobase/common/data/config"
+	"net/http"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// ErrOpen is returned without calling the backend while the breaker is open,
+// or half-open with all probes in flight
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Default settings of a breaker
+const (
+	DefaultMaxRequests  = 1
+	DefaultTimeout      = 60 * time.Second
+	DefaultMinRequests  = 10
+	DefaultFailureRatio = 0.5
+)
+
+// Breaker fails calls to a backend fast once too many of them failed, so a
+// dead dependency degrades gracefully instead of piling up timeouts
+//
+// Closed, calls go through and their failures are counted. Once
+// FailureRatio of at least MinRequests calls failed it opens and rejects
+// calls with ErrOpen for Timeout. It then goes half-open and lets MaxRequests
+// probes through, closing if they all succeed and opening again otherwise.
+// A nil Breaker calls through.
+type Breaker struct {
+	cb *gobreaker.CircuitBreaker
+}
+
+// New creates a breaker named after its backend, nil if conf is nil or not
+// enabled, zero settings take their default
+func New(name string, conf *config.CircuitBreaker) *Breaker {
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+
+	maxRequests := conf.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = DefaultMaxRequests
+	}
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	minRequests := conf.MinRequests
+	if minRequests == 0 {
+		minRequests = DefaultMinRequests
+	}
+	ratio := conf.FailureRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = DefaultFailureRatio
+	}
+
+	return &Breaker{cb: gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        name,
+		MaxRequests: maxRequests,
+		Interval:    conf.Interval,
+		Timeout:     timeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.Requests >= minRequests &&
+				float64(counts.TotalFailures)/float64(counts.Requests) >= ratio
+		},
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("Circuit breaker %s changed from %s to %s", name, from, to)
+		},
+		IsSuccessful: isSuccessful,
+	})}
+}
+
+// isSuccessful does not count canceled calls as failures of the backend
+func isSuccessful(err error) bool {
+	return err == nil || errors.Is(err, context.Canceled)
+}
+
+// Name returns the name of the breaker
+func (b *Breaker) Name() string {
+	if b == nil {
+		return ""
+	}
+	return b.cb.Name()
+}
+
+// State returns the current state, "closed", "half-open" or "open"
+func (b *Breaker) State() string {
+	if b == nil {
+		return gobreaker.StateClosed.String()
+	}
+	return b.cb.State().String()
+}
+
+// Execute calls fn unless the breaker is open, counting its error as a failure
+func (b *Breaker) Execute(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	_, err := b.cb.Execute(func() (any, error) {
+		return nil, fn()
+	})
+	return wrapError(b.cb.Name(), err)
+}
+
+// wrapError wraps the errors of gobreaker rejecting a call with ErrOpen
+func wrapError(name string, err error) error {
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return fmt.Errorf("%s: %w", name, ErrOpen)
+	}
+	return err
+}
+
+// errStatus counts a response as a failure while still returning it
+var errStatus = errors.New("backend unavailable status")
+
+// Transport wraps next, http.DefaultTransport if nil, with the breaker.
+// Transport errors, 5xx and 429 responses count as failures, requests are
+// rejected with ErrOpen while it is open. A nil Breaker returns next.
+func (b *Breaker) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if b == nil {
+		return next
+	}
+	return &transport{breaker: b, next: next}
+}
+
+type transport struct {
+	breaker *Breaker
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.breaker.cb.Execute(func() (any, error) {
+		res, err := t.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests {
+			return res, errStatus
+		}
+		return res, nil
+	})
+	if errors.Is(err, errStatus) {
+		err = nil
+	}
+	if err != nil {
+		return nil, wrapError(t.breaker.cb.Name(), err)
+	}
+	return res.(*http.Response), nil
+}
@@ -0,0 +1,104 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"ncobase/common/data/config"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew_Disabled(t *testing.T) {
+	if New("search", nil) != nil || New("search", &config.CircuitBreaker{}) != nil {
+		t.Fatal("expected no breaker when not enabled")
+	}
+
+	var b *Breaker
+	errFail := errors.New("fail")
+	if err := b.Execute(func() error { return errFail }); !errors.Is(err, errFail) {
+		t.Errorf("expected a nil breaker to call through, got %v", err)
+	}
+	if b.Transport(nil) != http.DefaultTransport {
+		t.Error("expected a nil breaker to return the transport")
+	}
+}
+
+func TestExecute_TripsAndRecovers(t *testing.T) {
+	b := New("search", &config.CircuitBreaker{
+		Enabled:      true,
+		Timeout:      50 * time.Millisecond,
+		MinRequests:  4,
+		FailureRatio: 0.5,
+	})
+	errFail := errors.New("fail")
+
+	// Canceled calls are not failures of the backend
+	for range 4 {
+		_ = b.Execute(func() error { return context.Canceled })
+	}
+	if b.State() != "closed" {
+		t.Fatalf("expected canceled calls to keep it closed, got %s", b.State())
+	}
+
+	for range 4 {
+		_ = b.Execute(func() error { return errFail })
+	}
+	if b.State() != "open" {
+		t.Fatalf("expected open after failures, got %s", b.State())
+	}
+
+	called := false
+	if err := b.Execute(func() error { called = true; return nil }); !errors.Is(err, ErrOpen) {
+		t.Errorf("expected ErrOpen, got %v", err)
+	}
+	if called {
+		t.Error("expected no call while open")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if b.State() != "half-open" {
+		t.Fatalf("expected half-open after the timeout, got %s", b.State())
+	}
+	if err := b.Execute(func() error { return nil }); err != nil {
+		t.Errorf("unexpected probe error: %v", err)
+	}
+	if b.State() != "closed" {
+		t.Errorf("expected closed after a successful probe, got %s", b.State())
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	b := New("search", &config.CircuitBreaker{Enabled: true, Timeout: time.Minute, MinRequests: 2, FailureRatio: 1})
+	client := &http.Client{Transport: b.Transport(nil)}
+
+	for range 2 {
+		res, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected the 503 response to be returned, got %v", err)
+		}
+		_ = res.Body.Close()
+		if res.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", res.StatusCode)
+		}
+	}
+
+	status.Store(http.StatusOK)
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrOpen) {
+		t.Errorf("expected ErrOpen, got %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("expected no request while open, got %d", got)
+	}
+}
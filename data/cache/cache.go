@@ -22,7 +22,7 @@ type ICache[T any] interface {
 
 // Cache implements the ICache interface
 type Cache[T any] struct {
-	rc      *redis.Client
+	rc      redis.UniversalClient
 	key     string
 	useHash bool
 }
@@ -39,7 +39,7 @@ func (c *Cache[T]) Key(field string) string {
 }
 
 // NewCache creates a new Cache instance
-func NewCache[T any](rc *redis.Client, key string, useHash ...bool) *Cache[T] {
+func NewCache[T any](rc redis.UniversalClient, key string, useHash ...bool) *Cache[T] {
 	hash := false
 	if len(useHash) > 0 {
 		hash = useHash[0]
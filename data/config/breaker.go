@@ -0,0 +1,29 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// CircuitBreaker circuit breaker config struct of a connection
+type CircuitBreaker struct {
+	Enabled      bool
+	MaxRequests  uint32        // probes let through while half-open, 1 by default
+	Interval     time.Duration // period clearing the counts while closed, never by default
+	Timeout      time.Duration // time open before probing, 60s by default
+	MinRequests  uint32        // requests of a period needed before tripping, 10 by default
+	FailureRatio float64       // failed share of the requests of a period tripping it, 0.5 by default
+}
+
+// getCircuitBreakerConfig reads the circuit breaker configuration under prefix
+func getCircuitBreakerConfig(v *viper.Viper, prefix string) *CircuitBreaker {
+	return &CircuitBreaker{
+		Enabled:      v.GetBool(prefix + ".enabled"),
+		MaxRequests:  v.GetUint32(prefix + ".max_requests"),
+		Interval:     v.GetDuration(prefix + ".interval"),
+		Timeout:      v.GetDuration(prefix + ".timeout"),
+		MinRequests:  v.GetUint32(prefix + ".min_requests"),
+		FailureRatio: v.GetFloat64(prefix + ".failure_ratio"),
+	}
+}
@@ -11,10 +11,11 @@ type Config struct {
 	*Redis
 	*Meilisearch
 	*Elasticsearch
-	*MongoDB
+	*MongoDB `config:"mongodb"`
 	*Neo4j
-	*RabbitMQ
+	*RabbitMQ `config:"rabbitmq"`
 	*Kafka
+	*NATS
 }
 
 // GetConfig returns data config
@@ -29,5 +30,6 @@ func GetConfig(v *viper.Viper) *Config {
 		Neo4j:         getNeo4jConfigs(v),
 		RabbitMQ:      getRabbitMQConfigs(v),
 		Kafka:         getKafkaConfigs(v),
+		NATS:          getNATSConfigs(v),
 	}
 }
@@ -9,11 +9,19 @@ import (
 
 // Database database config struct
 type Database struct {
-	Master   *DBNode   `json:"master"`
-	Slaves   []*DBNode `json:"slaves"`
-	Migrate  bool      `json:"migrate"`
-	Strategy string    `json:"strategy"`
-	MaxRetry int       `json:"max_retry"`
+	Master *DBNode   `json:"master"`
+	Slaves []*DBNode `json:"slaves"`
+	// Replicas are sources of slaves sharing the driver and pool settings
+	// of the master
+	Replicas []string `json:"replicas"`
+	Migrate  bool     `json:"migrate"`
+	// Strategy balances reads over slaves, round_robin, random, weight or
+	// least_latency
+	Strategy string `json:"strategy"`
+	MaxRetry int    `json:"max_retry"`
+	// EjectTime is how long a failing slave gets no reads before it is
+	// tried again
+	EjectTime time.Duration `json:"eject_time"`
 }
 
 // DBNode represents a single database node configuration
@@ -23,32 +31,33 @@ type DBNode struct {
 	Logging         bool          `json:"logging"`
 	MaxIdleConn     int           `json:"max_idle_conn"`
 	MaxOpenConn     int           `json:"max_open_conn"`
-	ConnMaxLifeTime time.Duration `json:"conn_max_life_time"`
+	ConnMaxLifeTime time.Duration `json:"conn_max_life_time" config:"max_life_time"`
 	Weight          int           `json:"weight"`
+	// Pool settings of the postgres driver, MaxOpenConn and ConnMaxLifeTime
+	// apply to the pool as well
+	MinConn           int           `json:"min_conn"`
+	MaxConnIdleTime   time.Duration `json:"max_conn_idle_time" config:"max_idle_time"`
+	HealthCheckPeriod time.Duration `json:"health_check_period"`
+	// SlowThreshold logs statements taking longer as warnings, postgres only
+	SlowThreshold time.Duration `json:"slow_threshold"`
 }
 
 // getDatabaseConfig reads database configurations
 func getDatabaseConfig(v *viper.Viper) *Database {
 	return &Database{
-		Master:   getMasterConfig(v),
-		Slaves:   getSlaveConfigs(v),
-		Migrate:  v.GetBool("data.database.migrate"),
-		Strategy: v.GetString("data.database.strategy"),
-		MaxRetry: v.GetInt("data.database.max_retry"),
+		Master:    getMasterConfig(v),
+		Slaves:    getSlaveConfigs(v),
+		Replicas:  v.GetStringSlice("data.database.replicas"),
+		Migrate:   v.GetBool("data.database.migrate"),
+		Strategy:  v.GetString("data.database.strategy"),
+		MaxRetry:  v.GetInt("data.database.max_retry"),
+		EjectTime: v.GetDuration("data.database.eject_time"),
 	}
 }
 
 // getMasterConfig reads master database configurations
 func getMasterConfig(v *viper.Viper) *DBNode {
-	return &DBNode{
-		Driver:          v.GetString("data.database.master.driver"),
-		Source:          v.GetString("data.database.master.source"),
-		Logging:         v.GetBool("data.database.master.logging"),
-		MaxIdleConn:     v.GetInt("data.database.master.max_idle_conn"),
-		MaxOpenConn:     v.GetInt("data.database.master.max_open_conn"),
-		ConnMaxLifeTime: v.GetDuration("data.database.master.max_life_time"),
-		Weight:          v.GetInt("data.database.master.weight"),
-	}
+	return getDBNodeConfig(v, "data.database.master")
 }
 
 // getSlaveConfigs reads slave database configurations
@@ -67,16 +76,24 @@ func getSlaveConfigs(v *viper.Viper) []*DBNode {
 
 	slavesCount := len(slavesList)
 	for i := 0; i < slavesCount; i++ {
-		slave := &DBNode{
-			Driver:          v.GetString(fmt.Sprintf("data.database.slaves.%d.driver", i)),
-			Source:          v.GetString(fmt.Sprintf("data.database.slaves.%d.source", i)),
-			Logging:         v.GetBool(fmt.Sprintf("data.database.slaves.%d.logging", i)),
-			MaxIdleConn:     v.GetInt(fmt.Sprintf("data.database.slaves.%d.max_idle_conn", i)),
-			MaxOpenConn:     v.GetInt(fmt.Sprintf("data.database.slaves.%d.max_open_conn", i)),
-			ConnMaxLifeTime: v.GetDuration(fmt.Sprintf("data.database.slaves.%d.max_life_time", i)),
-			Weight:          v.GetInt(fmt.Sprintf("data.database.slaves.%d.weight", i)),
-		}
-		slaves = append(slaves, slave)
+		slaves = append(slaves, getDBNodeConfig(v, fmt.Sprintf("data.database.slaves.%d", i)))
 	}
 	return slaves
 }
+
+// getDBNodeConfig reads a database node configuration under prefix
+func getDBNodeConfig(v *viper.Viper, prefix string) *DBNode {
+	return &DBNode{
+		Driver:            v.GetString(prefix + ".driver"),
+		Source:            v.GetString(prefix + ".source"),
+		Logging:           v.GetBool(prefix + ".logging"),
+		MaxIdleConn:       v.GetInt(prefix + ".max_idle_conn"),
+		MaxOpenConn:       v.GetInt(prefix + ".max_open_conn"),
+		ConnMaxLifeTime:   v.GetDuration(prefix + ".max_life_time"),
+		Weight:            v.GetInt(prefix + ".weight"),
+		MinConn:           v.GetInt(prefix + ".min_conn"),
+		MaxConnIdleTime:   v.GetDuration(prefix + ".max_idle_time"),
+		HealthCheckPeriod: v.GetDuration(prefix + ".health_check_period"),
+		SlowThreshold:     v.GetDuration(prefix + ".slow_threshold"),
+	}
+}
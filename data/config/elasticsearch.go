@@ -4,9 +4,10 @@ import "github.com/spf13/viper"
 
 // Elasticsearch elasticsearch config struct
 type Elasticsearch struct {
-	Addresses []string `json:"addresses"`
-	Username  string   `json:"username"`
-	Password  string   `json:"password"`
+	Addresses []string        `json:"addresses"`
+	Username  string          `json:"username"`
+	Password  string          `json:"password"`
+	Breaker   *CircuitBreaker `json:"breaker"`
 }
 
 // getElasticsearchConfigs reads Elasticsearch configurations
@@ -15,5 +16,6 @@ func getElasticsearchConfigs(v *viper.Viper) *Elasticsearch {
 		Addresses: v.GetStringSlice("data.elasticsearch.addresses"),
 		Username:  v.GetString("data.elasticsearch.username"),
 		Password:  v.GetString("data.elasticsearch.password"),
+		Breaker:   getCircuitBreakerConfig(v, "data.elasticsearch.breaker"),
 	}
 }
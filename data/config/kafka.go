@@ -15,6 +15,15 @@ type Kafka struct {
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 	ConnectTimeout time.Duration
+	SASL           *KafkaSASL
+	TLS            *TLS
+}
+
+// KafkaSASL kafka SASL authentication config struct
+type KafkaSASL struct {
+	Mechanism string `validate:"omitempty,oneof=plain scram-sha-256 scram-sha-512"` // SASL is disabled when empty
+	Username  string
+	Password  string
 }
 
 // getKafkaConfigs reads Kafka configurations
@@ -27,5 +36,11 @@ func getKafkaConfigs(v *viper.Viper) *Kafka {
 		ReadTimeout:    v.GetDuration("data.kafka.read_timeout"),
 		WriteTimeout:   v.GetDuration("data.kafka.write_timeout"),
 		ConnectTimeout: v.GetDuration("data.kafka.connect_timeout"),
+		SASL: &KafkaSASL{
+			Mechanism: v.GetString("data.kafka.sasl.mechanism"),
+			Username:  v.GetString("data.kafka.sasl.username"),
+			Password:  v.GetString("data.kafka.sasl.password"),
+		},
+		TLS: getTLSConfig(v, "data.kafka.tls"),
 	}
 }
@@ -4,14 +4,16 @@ import "github.com/spf13/viper"
 
 // Meilisearch meilisearch config struct
 type Meilisearch struct {
-	Host   string `json:"host"`
-	APIKey string `json:"api_key"`
+	Host    string          `json:"host"`
+	APIKey  string          `json:"api_key"`
+	Breaker *CircuitBreaker `json:"breaker"`
 }
 
 // getMeilisearchConfigs reads Meilisearch configurations
 func getMeilisearchConfigs(v *viper.Viper) *Meilisearch {
 	return &Meilisearch{
-		Host:   v.GetString("data.meilisearch.host"),
-		APIKey: v.GetString("data.meilisearch.api_key"),
+		Host:    v.GetString("data.meilisearch.host"),
+		APIKey:  v.GetString("data.meilisearch.api_key"),
+		Breaker: getCircuitBreakerConfig(v, "data.meilisearch.breaker"),
 	}
 }
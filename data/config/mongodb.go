@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,20 +15,28 @@ type MongoDB struct {
 	MaxRetry int          `json:"max_retry"`
 }
 
-// MongoNode mongodb node config
+// MongoNode mongodb node config, the fields override the URI options
 type MongoNode struct {
-	URI     string `json:"uri"`
-	Logging bool   `json:"logging"`
-	Weight  int    `json:"weight"`
+	URI                    string        `json:"uri"`
+	Logging                bool          `json:"logging"`
+	Weight                 int           `json:"weight"`
+	Username               string        `json:"username"`
+	Password               string        `json:"password"`
+	AuthSource             string        `json:"auth_source"`    // database of the user, admin by default
+	AuthMechanism          string        `json:"auth_mechanism"` // e.g. SCRAM-SHA-256, negotiated by default
+	ReplicaSet             string        `json:"replica_set"`
+	ReadPreference         string        `json:"read_preference" validate:"omitempty,oneof=primary primaryPreferred secondary secondaryPreferred nearest"`
+	MaxPoolSize            uint64        `json:"max_pool_size"`
+	MinPoolSize            uint64        `json:"min_pool_size"`
+	MaxConnIdleTime        time.Duration `json:"max_conn_idle_time"`
+	ConnectTimeout         time.Duration `json:"connect_timeout"`
+	ServerSelectionTimeout time.Duration `json:"server_selection_timeout"` // also bounds the startup ping, 10s by default
 }
 
 // getMongoDBConfigs reads MongoDB configurations
 func getMongoDBConfigs(v *viper.Viper) *MongoDB {
 	return &MongoDB{
-		Master: &MongoNode{
-			URI:     v.GetString("data.mongodb.master.uri"),
-			Logging: v.GetBool("data.mongodb.master.logging"),
-		},
+		Master:   getMongoNodeConfig(v, "data.mongodb.master"),
 		Slaves:   getMongoSlaveConfigs(v),
 		Strategy: v.GetString("data.mongodb.strategy"),
 		MaxRetry: v.GetInt("data.mongodb.max_retry"),
@@ -53,11 +62,7 @@ func getMongoSlaveConfigs(v *viper.Viper) []*MongoNode {
 
 	// parse each slave
 	for i := 0; i < len(slavesInterface); i++ {
-		slave := &MongoNode{
-			URI:     v.GetString(fmt.Sprintf("data.mongodb.slaves.%d.uri", i)),
-			Logging: v.GetBool(fmt.Sprintf("data.mongodb.slaves.%d.logging", i)),
-			Weight:  v.GetInt(fmt.Sprintf("data.mongodb.slaves.%d.weight", i)),
-		}
+		slave := getMongoNodeConfig(v, fmt.Sprintf("data.mongodb.slaves.%d", i))
 
 		// check if the slave is valid
 		if slave.URI != "" {
@@ -71,3 +76,23 @@ func getMongoSlaveConfigs(v *viper.Viper) []*MongoNode {
 
 	return slaves
 }
+
+// getMongoNodeConfig reads the MongoDB node configuration under prefix
+func getMongoNodeConfig(v *viper.Viper, prefix string) *MongoNode {
+	return &MongoNode{
+		URI:                    v.GetString(prefix + ".uri"),
+		Logging:                v.GetBool(prefix + ".logging"),
+		Weight:                 v.GetInt(prefix + ".weight"),
+		Username:               v.GetString(prefix + ".username"),
+		Password:               v.GetString(prefix + ".password"),
+		AuthSource:             v.GetString(prefix + ".auth_source"),
+		AuthMechanism:          v.GetString(prefix + ".auth_mechanism"),
+		ReplicaSet:             v.GetString(prefix + ".replica_set"),
+		ReadPreference:         v.GetString(prefix + ".read_preference"),
+		MaxPoolSize:            v.GetUint64(prefix + ".max_pool_size"),
+		MinPoolSize:            v.GetUint64(prefix + ".min_pool_size"),
+		MaxConnIdleTime:        v.GetDuration(prefix + ".max_conn_idle_time"),
+		ConnectTimeout:         v.GetDuration(prefix + ".connect_timeout"),
+		ServerSelectionTimeout: v.GetDuration(prefix + ".server_selection_timeout"),
+	}
+}
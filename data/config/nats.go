@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// NATS nats config struct
+type NATS struct {
+	URL            string // comma separated server URLs, e.g. nats://127.0.0.1:4222
+	Name           string // client name shown by the server
+	Username       string
+	Password       string
+	Token          string
+	CredsFile      string // user credentials file of decentralized auth
+	ConnectTimeout time.Duration
+	ReconnectWait  time.Duration
+	MaxReconnects  int // 60 when 0, -1 reconnects forever
+	TLS            *TLS
+}
+
+// getNATSConfigs reads NATS configurations
+func getNATSConfigs(v *viper.Viper) *NATS {
+	return &NATS{
+		URL:            v.GetString("data.nats.url"),
+		Name:           v.GetString("data.nats.name"),
+		Username:       v.GetString("data.nats.username"),
+		Password:       v.GetString("data.nats.password"),
+		Token:          v.GetString("data.nats.token"),
+		CredsFile:      v.GetString("data.nats.creds_file"),
+		ConnectTimeout: v.GetDuration("data.nats.connect_timeout"),
+		ReconnectWait:  v.GetDuration("data.nats.reconnect_wait"),
+		MaxReconnects:  v.GetInt("data.nats.max_reconnects"),
+		TLS:            getTLSConfig(v, "data.nats.tls"),
+	}
+}
@@ -8,22 +8,28 @@ import (
 
 // RabbitMQ rabbitmq config struct
 type RabbitMQ struct {
+	URI               string `validate:"omitempty,url"` // full amqp(s):// URI, overrides the discrete fields when set
 	URL               string
 	Username          string
 	Password          string
 	Vhost             string
 	ConnectionTimeout time.Duration
 	HeartbeatInterval time.Duration
+	TLS               *TLS // amqps, also enabled by an amqps:// URI
+	Breaker           *CircuitBreaker
 }
 
 // getRabbitMQConfigs reads RabbitMQ configurations
 func getRabbitMQConfigs(v *viper.Viper) *RabbitMQ {
 	return &RabbitMQ{
+		URI:               v.GetString("data.rabbitmq.uri"),
 		URL:               v.GetString("data.rabbitmq.url"),
 		Username:          v.GetString("data.rabbitmq.username"),
 		Password:          v.GetString("data.rabbitmq.password"),
 		Vhost:             v.GetString("data.rabbitmq.vhost"),
 		ConnectionTimeout: v.GetDuration("data.rabbitmq.connection_timeout"),
 		HeartbeatInterval: v.GetDuration("data.rabbitmq.heartbeat_interval"),
+		TLS:               getTLSConfig(v, "data.rabbitmq.tls"),
+		Breaker:           getCircuitBreakerConfig(v, "data.rabbitmq.breaker"),
 	}
 }
@@ -6,26 +6,53 @@ import (
 	"github.com/spf13/viper"
 )
 
+// Redis topologies
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
 // Redis redis config struct
 type Redis struct {
-	Addr         string
-	Username     string
-	Password     string
-	Db           int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	DialTimeout  time.Duration
+	// Mode is standalone, sentinel or cluster, when empty it is sentinel
+	// with a master name, cluster with several addresses, else standalone
+	Mode             string `validate:"omitempty,oneof=standalone sentinel cluster"`
+	Addr             string
+	Addrs            []string // sentinel or cluster seed addresses, Addr is one of them when set
+	MasterName       string   // sentinel master
+	Username         string
+	Password         string
+	SentinelUsername string
+	SentinelPassword string
+	Db               int
+	PoolSize         int  // connections per node, 10 when 0
+	MinIdleConns     int  // idle connections kept per node
+	ReadOnly         bool // route cluster reads to replicas
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	DialTimeout      time.Duration
+	TLS              *TLS
 }
 
 // getRedisConfigs reads Redis configurations
 func getRedisConfigs(v *viper.Viper) *Redis {
 	return &Redis{
-		Addr:         v.GetString("data.redis.addr"),
-		Username:     v.GetString("data.redis.username"),
-		Password:     v.GetString("data.redis.password"),
-		Db:           v.GetInt("data.redis.db"),
-		ReadTimeout:  v.GetDuration("data.redis.read_timeout"),
-		WriteTimeout: v.GetDuration("data.redis.write_timeout"),
-		DialTimeout:  v.GetDuration("data.redis.dial_timeout"),
+		Mode:             v.GetString("data.redis.mode"),
+		Addr:             v.GetString("data.redis.addr"),
+		Addrs:            v.GetStringSlice("data.redis.addrs"),
+		MasterName:       v.GetString("data.redis.master_name"),
+		Username:         v.GetString("data.redis.username"),
+		Password:         v.GetString("data.redis.password"),
+		SentinelUsername: v.GetString("data.redis.sentinel_username"),
+		SentinelPassword: v.GetString("data.redis.sentinel_password"),
+		Db:               v.GetInt("data.redis.db"),
+		PoolSize:         v.GetInt("data.redis.pool_size"),
+		MinIdleConns:     v.GetInt("data.redis.min_idle_conns"),
+		ReadOnly:         v.GetBool("data.redis.read_only"),
+		ReadTimeout:      v.GetDuration("data.redis.read_timeout"),
+		WriteTimeout:     v.GetDuration("data.redis.write_timeout"),
+		DialTimeout:      v.GetDuration("data.redis.dial_timeout"),
+		TLS:              getTLSConfig(v, "data.redis.tls"),
 	}
 }
@@ -0,0 +1,25 @@
+package config
+
+import "github.com/spf13/viper"
+
+// TLS tls config struct of a connection
+type TLS struct {
+	Enabled            bool
+	CAFile             string // PEM CA bundle verifying the server, the system pool by default
+	CertFile           string // PEM client certificate for mutual TLS
+	KeyFile            string // PEM key of the client certificate
+	ServerName         string // expected server name, the host by default
+	InsecureSkipVerify bool   // skip server verification, for testing only
+}
+
+// getTLSConfig reads the TLS configuration under prefix
+func getTLSConfig(v *viper.Viper, prefix string) *TLS {
+	return &TLS{
+		Enabled:            v.GetBool(prefix + ".enabled"),
+		CAFile:             v.GetString(prefix + ".ca_file"),
+		CertFile:           v.GetString(prefix + ".cert_file"),
+		KeyFile:            v.GetString(prefix + ".key_file"),
+		ServerName:         v.GetString(prefix + ".server_name"),
+		InsecureSkipVerify: v.GetBool(prefix + ".insecure_skip_verify"),
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"ncobase/common/data/config"
 	"ncobase/common/data/elastic"
 	"ncobase/common/data/meili"
+	"ncobase/common/data/metrics"
+	"ncobase/common/data/rabbitmq"
 	"sync"
 
+	"github.com/nats-io/nats.go"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 )
@@ -18,82 +21,105 @@ import (
 // Connections struct to hold all database connections and clients
 type Connections struct {
 	DBM    *DBManager
-	RC     *redis.Client
+	RC     redis.UniversalClient
 	MS     *meili.Client
 	ES     *elastic.Client
 	MGM    *MongoManager
 	Neo    neo4j.DriverWithContext
-	RMQ    *amqp.Connection
+	RMQ    *rabbitmq.Conn
 	KFK    *kafka.Conn
+	NC     *nats.Conn
 	closed bool
 	mu     sync.Mutex
+	// unregister stops collecting pool stats
+	unregister []func()
 }
 
-// New creates a new Connections
+// New creates a new Connections, opening the configured connections in
+// dependency order, data stores first, then search engines and brokers.
+// Connections opened before a failure are closed again.
 func New(conf *config.Config) (*Connections, error) {
 	c := &Connections{}
+	if err := c.open(conf); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// open opens the configured connections in dependency order
+func (c *Connections) open(conf *config.Config) error {
 	var err error
 
 	if conf.Database != nil && conf.Database.Master != nil && conf.Database.Master.Source != "" {
 		c.DBM, err = NewDBManager(conf.Database)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if conf.Redis != nil && conf.Redis.Addr != "" {
-		c.RC, err = newRedisClient(conf.Redis)
+	if conf.Redis != nil && len(redisAddrs(conf.Redis)) > 0 {
+		c.RC, err = newRedisConnection(conf.Redis)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		c.unregister = append(c.unregister, metrics.RegisterRedisPool("redis", c.RC.PoolStats))
 	}
 
-	if conf.Meilisearch != nil && conf.Meilisearch.Host != "" {
-		c.MS, err = newMeilisearchClient(conf.Meilisearch)
+	if conf.MongoDB != nil && conf.MongoDB.Master.URI != "" {
+		c.MGM, err = NewMongoManager(conf.MongoDB)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if conf.Elasticsearch != nil && len(conf.Elasticsearch.Addresses) > 0 {
-		c.ES, err = newElasticsearchClient(conf.Elasticsearch)
+	if conf.Neo4j != nil && conf.Neo4j.URI != "" {
+		c.Neo, err = newNeo4jClient(conf.Neo4j)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if conf.MongoDB != nil && conf.MongoDB.Master.URI != "" {
-		c.MGM, err = NewMongoManager(conf.MongoDB)
+	if conf.Elasticsearch != nil && len(conf.Elasticsearch.Addresses) > 0 {
+		c.ES, err = newElasticsearchClient(conf.Elasticsearch)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if conf.Neo4j != nil && conf.Neo4j.URI != "" {
-		c.Neo, err = newNeo4jClient(conf.Neo4j)
+	if conf.Meilisearch != nil && conf.Meilisearch.Host != "" {
+		c.MS, err = newMeilisearchClient(conf.Meilisearch)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if conf.RabbitMQ != nil && conf.RabbitMQ.URL != "" {
-		c.RMQ, err = newRabbitMQConnection(conf.RabbitMQ)
+	if conf.RabbitMQ != nil && (conf.RabbitMQ.URL != "" || conf.RabbitMQ.URI != "") {
+		c.RMQ, err = newRabbitMQConn(conf.RabbitMQ)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	if conf.Kafka != nil && conf.Kafka.Brokers != nil && len(conf.Kafka.Brokers) > 0 {
 		c.KFK, err = newKafkaConnection(conf.Kafka)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	return c, nil
+	if conf.NATS != nil && conf.NATS.URL != "" {
+		c.NC, err = newNATSConnection(conf.NATS)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-// Close closes all data connections
+// Close closes all data connections in reverse dependency order, brokers
+// first so consumers stop before the stores they write to
 func (d *Connections) Close() (errs []error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -103,30 +129,39 @@ func (d *Connections) Close() (errs []error) {
 		return nil
 	}
 
-	// Close Redis client if connected
-	if d.RC != nil {
-		if err := d.pingRedis(context.Background()); err == nil {
-			if err := d.RC.Close(); err != nil {
-				errs = append(errs, errors.New("redis close error: "+err.Error()))
+	for _, fn := range d.unregister {
+		fn()
+	}
+	d.unregister = nil
+
+	// Drain NATS connection if connected, so pending messages are handled
+	if d.NC != nil {
+		if !d.NC.IsClosed() {
+			if err := d.NC.Drain(); err != nil {
+				errs = append(errs, errors.New("nats drain error: "+err.Error()))
 			}
 		}
-		d.RC = nil
+		d.NC = nil
 	}
 
-	// Close database connections if connected
-	if d.DBM != nil {
-		if err := d.DBM.Close(); err != nil {
-			errs = append(errs, errors.New("database close error: "+err.Error()))
+	// Close Kafka client if connected
+	if d.KFK != nil {
+		if err := d.pingKafka(); err == nil {
+			if err := d.KFK.Close(); err != nil {
+				errs = append(errs, errors.New("kafka close error: "+err.Error()))
+			}
 		}
-		d.DBM = nil
+		d.KFK = nil
 	}
 
-	// Disconnect MongoDB client if connected
-	if d.MGM != nil {
-		if err := d.MGM.Close(context.Background()); err != nil {
-			errs = append(errs, errors.New("mongodb close error: "+err.Error()))
+	// Close RabbitMQ client if connected
+	if d.RMQ != nil {
+		if !d.RMQ.IsClosed() {
+			if err := d.RMQ.Close(); err != nil {
+				errs = append(errs, errors.New("rabbitmq close error: "+err.Error()))
+			}
 		}
-		d.MGM = nil
+		d.RMQ = nil
 	}
 
 	// Close Neo4j client if connected
@@ -137,24 +172,30 @@ func (d *Connections) Close() (errs []error) {
 		d.Neo = nil
 	}
 
-	// Close RabbitMQ client if connected
-	if d.RMQ != nil {
-		if !d.RMQ.IsClosed() {
-			if err := d.RMQ.Close(); err != nil {
-				errs = append(errs, errors.New("rabbitmq close error: "+err.Error()))
-			}
+	// Disconnect MongoDB client if connected
+	if d.MGM != nil {
+		if err := d.MGM.Close(context.Background()); err != nil {
+			errs = append(errs, errors.New("mongodb close error: "+err.Error()))
 		}
-		d.RMQ = nil
+		d.MGM = nil
 	}
 
-	// Close Kafka client if connected
-	if d.KFK != nil {
-		if err := d.pingKafka(); err == nil {
-			if err := d.KFK.Close(); err != nil {
-				errs = append(errs, errors.New("kafka close error: "+err.Error()))
+	// Close Redis client if connected
+	if d.RC != nil {
+		if err := d.pingRedis(context.Background()); err == nil {
+			if err := d.RC.Close(); err != nil {
+				errs = append(errs, errors.New("redis close error: "+err.Error()))
 			}
 		}
-		d.KFK = nil
+		d.RC = nil
+	}
+
+	// Close database connections if connected
+	if d.DBM != nil {
+		if err := d.DBM.Close(); err != nil {
+			errs = append(errs, errors.New("database close error: "+err.Error()))
+		}
+		d.DBM = nil
 	}
 
 	d.closed = true
@@ -170,6 +211,43 @@ func (d *Connections) Ping(ctx context.Context) error {
 	return nil
 }
 
+// Healthchecks returns the health check of each opened connection, by
+// backend name
+func (d *Connections) Healthchecks() map[string]func(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	checks := make(map[string]func(ctx context.Context) error)
+	if d.DBM != nil {
+		checks["database"] = d.DBM.Healthcheck
+	}
+	if d.RC != nil {
+		checks["redis"] = d.pingRedis
+	}
+	if d.MGM != nil {
+		checks["mongodb"] = d.MGM.Healthcheck
+	}
+	if d.Neo != nil {
+		checks["neo4j"] = d.Neo.VerifyConnectivity
+	}
+	if d.ES != nil {
+		checks["elasticsearch"] = d.ES.Healthcheck
+	}
+	if d.MS != nil {
+		checks["meilisearch"] = d.MS.Healthcheck
+	}
+	if d.RMQ != nil {
+		checks["rabbitmq"] = d.RMQ.Healthcheck
+	}
+	if d.KFK != nil {
+		checks["kafka"] = func(context.Context) error { return d.pingKafka() }
+	}
+	if d.NC != nil {
+		checks["nats"] = d.pingNATS
+	}
+	return checks
+}
+
 // DB returns the master database connection for write operations
 func (d *Connections) DB() *sql.DB {
 	if d.DBM == nil {
@@ -186,6 +264,14 @@ func (d *Connections) DBRead() (*sql.DB, error) {
 	return d.DBM.Slave()
 }
 
+// DBRouter returns a handle sending writes to the master and reads to slaves
+func (d *Connections) DBRouter() (*DBRouter, error) {
+	if d.DBM == nil {
+		return nil, errors.New("database manager is nil")
+	}
+	return d.DBM.Router(), nil
+}
+
 // pingRedis checks if Redis connection is alive
 func (d *Connections) pingRedis(ctx context.Context) error {
 	if d.RC == nil {
@@ -194,6 +280,17 @@ func (d *Connections) pingRedis(ctx context.Context) error {
 	return d.RC.Ping(ctx).Err()
 }
 
+// pingNATS checks if NATS connection is up and round trips to the server
+func (d *Connections) pingNATS(ctx context.Context) error {
+	if d.NC == nil {
+		return errors.New("nats connection is nil")
+	}
+	if !d.NC.IsConnected() {
+		return fmt.Errorf("nats connection is %s", d.NC.Status())
+	}
+	return d.NC.FlushWithContext(ctx)
+}
+
 // pingKafka checks if Kafka connection is alive
 func (d *Connections) pingKafka() error {
 	if d.KFK == nil {
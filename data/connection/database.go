@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"math/rand"
 	"ncobase/common/data/config"
+	"ncobase/common/data/metrics"
+	"ncobase/common/logger"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -20,14 +23,24 @@ var (
 	ErrInvalidStrategy   = errors.New("invalid load balance strategy")
 )
 
-// DBManager manages database connections for read-write splitting
+// defaultEjectTime is how long a failing slave is ejected when no eject time
+// is set
+const defaultEjectTime = 30 * time.Second
+
+// DBManager manages database connections for read-write splitting. Slaves
+// failing a ping are ejected and get no reads until the eject time passed or
+// a health check succeeds, reads go to the master when no slave is left.
 type DBManager struct {
-	master     *sql.DB
-	slaves     []*sql.DB
-	strategy   LoadBalancer
-	mutex      sync.RWMutex
-	maxRetry   int
-	currentIdx uint64 // for round robin
+	master    *sql.DB
+	slaves    []*sql.DB
+	pools     map[*sql.DB]*pgxpool.Pool // pgx pools of postgres nodes
+	strategy  LoadBalancer
+	mutex     sync.RWMutex
+	maxRetry  int
+	ejectTime time.Duration
+	ejected   map[*sql.DB]time.Time // slaves to the end of their ejection
+	// unregister stops collecting pool stats
+	unregister []func()
 }
 
 // LoadBalancer LoadBalancer interface
@@ -35,6 +48,11 @@ type LoadBalancer interface {
 	Next([]*sql.DB) (*sql.DB, error)
 }
 
+// latencyObserver is implemented by balancers picking slaves by latency
+type latencyObserver interface {
+	Observe(db *sql.DB, latency time.Duration)
+}
+
 // RoundRobinBalancer Implement polling strategy
 type RoundRobinBalancer struct {
 	current *uint64
@@ -71,19 +89,13 @@ func (rb *RandomBalancer) Next(slaves []*sql.DB) (*sql.DB, error) {
 
 // WeightBalancer Implement weight strategy
 type WeightBalancer struct {
-	weights []int
+	weights map[*sql.DB]int
 	current *uint64
 }
 
-func NewWeightBalancer(slaves []*config.DBNode) *WeightBalancer {
-	weights := make([]int, len(slaves))
-	for i, slave := range slaves {
-		weights[i] = slave.Weight
-		if weights[i] <= 0 {
-			weights[i] = 1 // default
-		}
-	}
-
+// NewWeightBalancer creates a WeightBalancer, slaves without a positive
+// weight weigh 1
+func NewWeightBalancer(weights map[*sql.DB]int) *WeightBalancer {
 	var counter uint64
 	return &WeightBalancer{
 		weights: weights,
@@ -91,15 +103,23 @@ func NewWeightBalancer(slaves []*config.DBNode) *WeightBalancer {
 	}
 }
 
+// weight returns the weight of a slave, default 1
+func (wb *WeightBalancer) weight(slave *sql.DB) int {
+	if w := wb.weights[slave]; w > 0 {
+		return w
+	}
+	return 1
+}
+
 func (wb *WeightBalancer) Next(slaves []*sql.DB) (*sql.DB, error) {
 	if len(slaves) == 0 {
 		return nil, ErrNoAvailableSlaves
 	}
 
-	// calculate total weight, default 1
+	// calculate total weight of the given slaves
 	totalWeight := 0
-	for _, w := range wb.weights {
-		totalWeight += w
+	for _, slave := range slaves {
+		totalWeight += wb.weight(slave)
 	}
 
 	// select weights
@@ -107,10 +127,10 @@ func (wb *WeightBalancer) Next(slaves []*sql.DB) (*sql.DB, error) {
 
 	// find corresponding slave
 	var accumulator int
-	for i, w := range wb.weights {
-		accumulator += w
+	for _, slave := range slaves {
+		accumulator += wb.weight(slave)
 		if uint64(accumulator) > next {
-			return slaves[i], nil
+			return slave, nil
 		}
 	}
 
@@ -118,26 +138,85 @@ func (wb *WeightBalancer) Next(slaves []*sql.DB) (*sql.DB, error) {
 	return slaves[0], nil
 }
 
+// LatencyBalancer Implement least latency strategy, picking the slave with
+// the lowest moving average ping latency. Slaves without a sample yet are
+// picked first.
+type LatencyBalancer struct {
+	mu        sync.RWMutex
+	latencies map[*sql.DB]time.Duration
+}
+
+// NewLatencyBalancer Create new LatencyBalancer
+func NewLatencyBalancer() *LatencyBalancer {
+	return &LatencyBalancer{latencies: make(map[*sql.DB]time.Duration)}
+}
+
+// Observe adds a latency sample of a slave
+func (lb *LatencyBalancer) Observe(slave *sql.DB, latency time.Duration) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if prev, ok := lb.latencies[slave]; ok {
+		// exponential moving average, new samples weigh 1/4
+		latency = (prev*3 + latency) / 4
+	}
+	lb.latencies[slave] = latency
+}
+
+func (lb *LatencyBalancer) Next(slaves []*sql.DB) (*sql.DB, error) {
+	if len(slaves) == 0 {
+		return nil, ErrNoAvailableSlaves
+	}
+
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	best := slaves[0]
+	bestLatency, ok := lb.latencies[best]
+	if !ok {
+		return best, nil
+	}
+	for _, slave := range slaves[1:] {
+		latency, ok := lb.latencies[slave]
+		if !ok {
+			return slave, nil
+		}
+		if latency < bestLatency {
+			best, bestLatency = slave, latency
+		}
+	}
+	return best, nil
+}
+
 // NewDBManager creates a new database manager with read-write splitting
 func NewDBManager(conf *config.Database) (*DBManager, error) {
 	if conf.Master == nil {
 		return nil, fmt.Errorf("master database configuration is required")
 	}
 	// Initialize master database connection
-	master, err := newDBClient(conf.Master)
+	pools := make(map[*sql.DB]*pgxpool.Pool)
+	master, pool, err := newDBClient(conf.Master)
 	if err != nil {
 		return nil, err
 	}
+	if pool != nil {
+		pools[master] = pool
+	}
 
 	// Initialize slave database connections
 	var slaves []*sql.DB
-	for _, slaveCfg := range conf.Slaves {
-		slave, err := newDBClient(slaveCfg)
+	weights := make(map[*sql.DB]int)
+	for i, slaveCfg := range slaveConfigs(conf) {
+		slave, pool, err := newDBClient(slaveCfg)
 		if err != nil {
-			fmt.Printf("Failed to connect to slave DB: %v", err)
+			logger.Warnf(context.Background(), "Failed to connect to slave DB %d: %v", i, err)
 			continue
 		}
+		if pool != nil {
+			pools[slave] = pool
+		}
 		slaves = append(slaves, slave)
+		weights[slave] = slaveCfg.Weight
 	}
 
 	// if no slave database is available, use master
@@ -153,44 +232,81 @@ func NewDBManager(conf *config.Database) (*DBManager, error) {
 	case "random":
 		strategy = &RandomBalancer{}
 	case "weight":
-		strategy = NewWeightBalancer(conf.Slaves)
+		strategy = NewWeightBalancer(weights)
+	case "least_latency":
+		strategy = NewLatencyBalancer()
 	default:
 		return nil, ErrInvalidStrategy
 	}
 
+	ejectTime := conf.EjectTime
+	if ejectTime <= 0 {
+		ejectTime = defaultEjectTime
+	}
+
+	// collect pool stats
+	unregister := []func(){metrics.RegisterDBPool("master", master.Stats)}
+	for i, slave := range slaves {
+		if slave != master {
+			unregister = append(unregister, metrics.RegisterDBPool(fmt.Sprintf("slave_%d", i), slave.Stats))
+		}
+	}
+
 	return &DBManager{
-		master:   master,
-		slaves:   slaves,
-		strategy: strategy,
-		maxRetry: conf.MaxRetry,
+		master:     master,
+		slaves:     slaves,
+		pools:      pools,
+		strategy:   strategy,
+		maxRetry:   conf.MaxRetry,
+		ejectTime:  ejectTime,
+		ejected:    make(map[*sql.DB]time.Time),
+		unregister: unregister,
 	}, nil
 }
 
-// newDBClient creates a new database client
-func newDBClient(conf *config.DBNode) (*sql.DB, error) {
+// slaveConfigs returns the slave nodes of conf, followed by a node per
+// replica source copying the master settings
+func slaveConfigs(conf *config.Database) []*config.DBNode {
+	nodes := append([]*config.DBNode(nil), conf.Slaves...)
+	for _, source := range conf.Replicas {
+		if source == "" {
+			continue
+		}
+		node := *conf.Master
+		node.Source = source
+		node.Weight = 0
+		nodes = append(nodes, &node)
+	}
+	return nodes
+}
+
+// newDBClient creates a new database client, postgres clients are backed by
+// the returned pgx pool, which is nil for other drivers
+func newDBClient(conf *config.DBNode) (*sql.DB, *pgxpool.Pool, error) {
 	var db *sql.DB
 	var err error
 
 	switch conf.Driver {
 	case "postgres":
-		db, err = sql.Open("pgx", conf.Source)
+		// Pool limits are applied to the pgx pool
+		return newPostgresConnection(conf)
 	case "mysql":
 		db, err = sql.Open("mysql", conf.Source)
 	case "sqlite3":
 		db, err = sql.Open("sqlite3", conf.Source)
 	default:
-		return nil, fmt.Errorf("dialect %v not supported", conf.Driver)
+		return nil, nil, fmt.Errorf("dialect %v not supported", conf.Driver)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
+		return nil, nil, fmt.Errorf("failed to open database: %v", err)
 	}
 
 	db.SetMaxIdleConns(conf.MaxIdleConn)
 	db.SetMaxOpenConns(conf.MaxOpenConn)
 	db.SetConnMaxLifetime(conf.ConnMaxLifeTime)
 
-	return db, nil
+	return db, nil, nil
 }
 
 // Master returns the master database connection
@@ -198,21 +314,36 @@ func (dm *DBManager) Master() *sql.DB {
 	return dm.master
 }
 
-// Slave returns a slave database connection based on the load balancing strategy
-func (dm *DBManager) Slave() (*sql.DB, error) {
-	dm.mutex.RLock()
-	defer dm.mutex.RUnlock()
+// Pool returns the pgx pool of the master database, nil unless the master
+// uses the postgres driver
+func (dm *DBManager) Pool() *pgxpool.Pool {
+	return dm.pools[dm.master]
+}
 
+// Router returns a handle sending writes to the master and reads to slaves
+func (dm *DBManager) Router() *DBRouter {
+	return &DBRouter{dm: dm}
+}
+
+// Slave returns a slave database connection based on the load balancing
+// strategy. A slave failing its ping is ejected and another one is tried,
+// up to max retry times, the master is returned when no slave is left.
+func (dm *DBManager) Slave() (*sql.DB, error) {
 	var lastErr error
 	for i := 0; i <= dm.maxRetry; i++ {
-		slave, err := dm.strategy.Next(dm.slaves)
+		slaves := dm.availableSlaves()
+		if len(slaves) == 0 {
+			return dm.master, nil
+		}
+
+		slave, err := dm.strategy.Next(slaves)
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
 		// Test the slave database connection
-		if err := slave.PingContext(context.Background()); err != nil {
+		if err := dm.ping(context.Background(), slave); err != nil {
 			lastErr = err
 			continue
 		}
@@ -224,10 +355,58 @@ func (dm *DBManager) Slave() (*sql.DB, error) {
 	return nil, fmt.Errorf("all retry attempts failed: %v", lastErr)
 }
 
+// availableSlaves returns the slaves which are not ejected
+func (dm *DBManager) availableSlaves() []*sql.DB {
+	dm.mutex.RLock()
+	defer dm.mutex.RUnlock()
+
+	if len(dm.ejected) == 0 {
+		return dm.slaves
+	}
+	now := time.Now()
+	slaves := make([]*sql.DB, 0, len(dm.slaves))
+	for _, slave := range dm.slaves {
+		if until, ok := dm.ejected[slave]; ok && now.Before(until) {
+			continue
+		}
+		slaves = append(slaves, slave)
+	}
+	return slaves
+}
+
+// ping pings a slave, ejecting it on failure and readmitting it on success
+func (dm *DBManager) ping(ctx context.Context, slave *sql.DB) error {
+	start := time.Now()
+	err := slave.PingContext(ctx)
+
+	dm.mutex.Lock()
+	defer dm.mutex.Unlock()
+
+	if err != nil {
+		if _, ok := dm.ejected[slave]; !ok {
+			logger.Warnf(ctx, "Ejecting slave database for %s: %v", dm.ejectTime, err)
+		}
+		dm.ejected[slave] = time.Now().Add(dm.ejectTime)
+		return err
+	}
+	if _, ok := dm.ejected[slave]; ok {
+		logger.Infof(ctx, "Slave database is back, readmitting it")
+		delete(dm.ejected, slave)
+	}
+	if o, ok := dm.strategy.(latencyObserver); ok {
+		o.Observe(slave, time.Since(start))
+	}
+	return nil
+}
+
 // Close closes all database connections
 func (dm *DBManager) Close() error {
 	var errs []error
 
+	for _, fn := range dm.unregister {
+		fn()
+	}
+
 	// Close master database
 	if err := dm.master.Close(); err != nil {
 		errs = append(errs, fmt.Errorf("error closing master connection: %v", err))
@@ -242,39 +421,85 @@ func (dm *DBManager) Close() error {
 		}
 	}
 
+	// Close pgx pools, after the handles using them
+	for _, pool := range dm.pools {
+		pool.Close()
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("errors closing database connections: %v", errs)
 	}
 	return nil
 }
 
-// Health checks the health of all database connections
+// Healthcheck checks the master database is reachable, slaves are not
+// required as reads fall back to the master
+func (dm *DBManager) Healthcheck(ctx context.Context) error {
+	if err := dm.master.PingContext(ctx); err != nil {
+		return fmt.Errorf("master database health check failed: %w", err)
+	}
+	return nil
+}
+
+// Health checks the health of all database connections, ejecting failing
+// slaves and readmitting recovered ones
 func (dm *DBManager) Health(ctx context.Context) error {
 	// Check health of master database
 	if err := dm.master.PingContext(ctx); err != nil {
 		return fmt.Errorf("master database health check failed: %v", err)
 	}
 
-	dm.mutex.Lock()
-	defer dm.mutex.Unlock()
-
-	// Check health of slave databases, and update the list of healthy slaves
-	var healthySlaves []*sql.DB
+	// Check health of slave databases, ejected ones included
 	for _, slave := range dm.slaves {
-		if err := slave.PingContext(ctx); err != nil {
-			fmt.Printf("Slave database health check failed: %v\n", err)
+		if slave == dm.master {
 			continue
 		}
-		healthySlaves = append(healthySlaves, slave)
+		_ = dm.ping(ctx, slave)
 	}
 
-	// Update the list of healthy slaves
-	dm.slaves = healthySlaves
+	return nil
+}
+
+// DBRouter routes statements of a DBManager, writes to the master and reads
+// to a slave
+type DBRouter struct {
+	dm *DBManager
+}
 
-	// if no slave database is available, use master
-	if len(dm.slaves) == 0 {
-		dm.slaves = append(dm.slaves, dm.master)
+// reader returns the database for reads, the master when no slave answers
+func (r *DBRouter) reader() *sql.DB {
+	slave, err := r.dm.Slave()
+	if err != nil {
+		return r.dm.master
 	}
+	return slave
+}
 
-	return nil
+// ExecContext executes a statement on the master
+func (r *DBRouter) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.dm.master.ExecContext(ctx, query, args...)
+}
+
+// QueryContext runs a query on a slave
+func (r *DBRouter) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.reader().QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning at most one row on a slave
+func (r *DBRouter) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.reader().QueryRowContext(ctx, query, args...)
+}
+
+// PrepareContext prepares a statement on the master, as it may write
+func (r *DBRouter) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.dm.master.PrepareContext(ctx, query)
+}
+
+// BeginTx starts a transaction, read-only transactions on a slave and all
+// others on the master
+func (r *DBRouter) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if opts != nil && opts.ReadOnly {
+		return r.reader().BeginTx(ctx, opts)
+	}
+	return r.dm.master.BeginTx(ctx, opts)
 }
@@ -0,0 +1,115 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"ncobase/common/data/config"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWeightBalancer(t *testing.T) {
+	a, b := &sql.DB{}, &sql.DB{}
+	wb := NewWeightBalancer(map[*sql.DB]int{a: 3})
+
+	counts := map[*sql.DB]int{}
+	for i := 0; i < 8; i++ {
+		slave, err := wb.Next([]*sql.DB{a, b})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[slave]++
+	}
+	if counts[a] != 6 || counts[b] != 2 {
+		t.Errorf("expected 6/2 picks, got %d/%d", counts[a], counts[b])
+	}
+
+	// Weights follow the slaves, not their position
+	if slave, _ := wb.Next([]*sql.DB{b}); slave != b {
+		t.Error("expected the only slave")
+	}
+}
+
+func TestLatencyBalancer(t *testing.T) {
+	a, b := &sql.DB{}, &sql.DB{}
+	lb := NewLatencyBalancer()
+
+	lb.Observe(a, 10*time.Millisecond)
+	if slave, _ := lb.Next([]*sql.DB{a, b}); slave != b {
+		t.Error("expected the slave without samples first")
+	}
+
+	lb.Observe(b, 50*time.Millisecond)
+	if slave, _ := lb.Next([]*sql.DB{a, b}); slave != a {
+		t.Error("expected the fastest slave")
+	}
+
+	// Moving average, a single fast sample does not win
+	lb.Observe(b, time.Millisecond)
+	if slave, _ := lb.Next([]*sql.DB{a, b}); slave != a {
+		t.Error("expected the fastest slave on average")
+	}
+
+	if _, err := lb.Next(nil); err != ErrNoAvailableSlaves {
+		t.Errorf("expected ErrNoAvailableSlaves, got %v", err)
+	}
+}
+
+func TestSlaveConfigs(t *testing.T) {
+	conf := &config.Database{
+		Master:   &config.DBNode{Driver: "mysql", Source: "primary", MaxOpenConn: 10, Weight: 5},
+		Slaves:   []*config.DBNode{{Driver: "mysql", Source: "slave"}},
+		Replicas: []string{"replica", ""},
+	}
+	nodes := slaveConfigs(conf)
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(nodes))
+	}
+	if n := nodes[1]; n.Source != "replica" || n.Driver != "mysql" || n.MaxOpenConn != 10 || n.Weight != 0 {
+		t.Errorf("unexpected replica node %+v", n)
+	}
+	if conf.Master.Source != "primary" {
+		t.Error("master config was modified")
+	}
+}
+
+func TestDBManagerEjection(t *testing.T) {
+	dir := t.TempDir()
+	dm, err := NewDBManager(&config.Database{
+		Master: &config.DBNode{Driver: "sqlite3", Source: filepath.Join(dir, "master.db")},
+		// The replica directory does not exist, so its pings fail
+		Replicas:  []string{filepath.Join(dir, "missing", "replica.db")},
+		EjectTime: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer dm.Close()
+
+	if _, err := dm.Slave(); err == nil {
+		t.Fatal("expected error for failing slave")
+	}
+	if len(dm.availableSlaves()) != 0 {
+		t.Fatal("expected failing slave to be ejected")
+	}
+
+	// Reads go to the master once no slave is left
+	slave, err := dm.Slave()
+	if err != nil || slave != dm.Master() {
+		t.Errorf("expected master for reads, got %v, %v", slave, err)
+	}
+
+	r := dm.Router()
+	ctx := context.Background()
+	if _, err := r.ExecContext(ctx, "CREATE TABLE t (v INTEGER)"); err != nil {
+		t.Fatalf("unexpected exec error: %v", err)
+	}
+	if _, err := r.ExecContext(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("unexpected exec error: %v", err)
+	}
+	var v int
+	if err := r.QueryRowContext(ctx, "SELECT v FROM t").Scan(&v); err != nil || v != 1 {
+		t.Errorf("expected 1, got %d, %v", v, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"ncobase/common/data/breaker"
 	"ncobase/common/data/config"
 	"ncobase/common/data/elastic"
 )
@@ -14,7 +15,8 @@ func newElasticsearchClient(conf *config.Elasticsearch) (*elastic.Client, error)
 		return nil, errors.New("elasticsearch configuration is nil or empty")
 	}
 
-	es, err := elastic.NewClient(conf.Addresses, conf.Username, conf.Password)
+	es, err := elastic.NewClient(conf.Addresses, conf.Username, conf.Password,
+		elastic.WithCircuitBreaker(breaker.New("elasticsearch", conf.Breaker)))
 	if err != nil {
 		return nil, fmt.Errorf("elasticsearch client creation error: %w", err)
 	}
@@ -7,18 +7,87 @@ import (
 	"ncobase/common/data/config"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
 )
 
-// newKafkaConnection creates a new Kafka connection
+// newKafkaConnection creates a new Kafka connection to the first reachable broker
 func newKafkaConnection(conf *config.Kafka) (*kafka.Conn, error) {
 	if conf == nil || len(conf.Brokers) == 0 {
 		return nil, errors.New("kafka configuration is nil or empty")
 	}
 
-	conn, err := kafka.DialContext(context.Background(), "tcp", conf.Brokers[0])
+	dialer, err := newKafkaDialer(conf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+		return nil, err
 	}
 
-	return conn, nil
+	var errs []error
+	for _, broker := range conf.Brokers {
+		conn, err := dialer.DialContext(context.Background(), "tcp", broker)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", broker, err))
+	}
+	return nil, fmt.Errorf("failed to connect to Kafka: %w", errors.Join(errs...))
+}
+
+// newKafkaDialer creates the dialer of Kafka connections and consumer groups
+func newKafkaDialer(conf *config.Kafka) (*kafka.Dialer, error) {
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka TLS config: %w", err)
+	}
+	mechanism, err := newKafkaSASLMechanism(conf.SASL)
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		ClientID:      conf.ClientID,
+		Timeout:       conf.ConnectTimeout,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+// newKafkaTransport creates the transport of Kafka producers
+func newKafkaTransport(conf *config.Kafka) (*kafka.Transport, error) {
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka TLS config: %w", err)
+	}
+	mechanism, err := newKafkaSASLMechanism(conf.SASL)
+	if err != nil {
+		return nil, err
+	}
+	transport := &kafka.Transport{
+		ClientID: conf.ClientID,
+		TLS:      tlsConfig,
+		SASL:     mechanism,
+	}
+	if conf.ConnectTimeout > 0 {
+		transport.DialTimeout = conf.ConnectTimeout
+	}
+	return transport, nil
+}
+
+// newKafkaSASLMechanism creates the SASL mechanism, nil when SASL is disabled
+func newKafkaSASLMechanism(conf *config.KafkaSASL) (sasl.Mechanism, error) {
+	if conf == nil || conf.Mechanism == "" {
+		return nil, nil
+	}
+
+	switch conf.Mechanism {
+	case "plain":
+		return plain.Mechanism{Username: conf.Username, Password: conf.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, conf.Username, conf.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, conf.Username, conf.Password)
+	default:
+		return nil, fmt.Errorf("unsupported Kafka SASL mechanism %q", conf.Mechanism)
+	}
 }
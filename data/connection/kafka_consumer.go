@@ -0,0 +1,104 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ncobase/common/data/config"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaHandler handles a message of a consumer group
+type KafkaHandler func(ctx context.Context, m kafka.Message) error
+
+// KafkaConsumerGroup consumes topics as a member of the consumer group of a
+// config, committing each message once its handler succeeded
+type KafkaConsumerGroup struct {
+	reader  *kafka.Reader
+	handler KafkaHandler
+
+	mu      sync.Mutex
+	running bool
+}
+
+// NewKafkaConsumerGroup creates a member of the consumer group of conf
+// consuming topics, the topic of the config when none is given
+func NewKafkaConsumerGroup(conf *config.Kafka, handler KafkaHandler, topics ...string) (*KafkaConsumerGroup, error) {
+	if conf == nil || len(conf.Brokers) == 0 {
+		return nil, errors.New("kafka configuration is nil or empty")
+	}
+	if conf.ConsumerGroup == "" {
+		return nil, errors.New("kafka consumer group is empty")
+	}
+	if len(topics) == 0 && conf.Topic != "" {
+		topics = []string{conf.Topic}
+	}
+	if len(topics) == 0 {
+		return nil, errors.New("kafka consumer group has no topic")
+	}
+	dialer, err := newKafkaDialer(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     conf.Brokers,
+		GroupID:     conf.ConsumerGroup,
+		GroupTopics: topics,
+		Dialer:      dialer,
+		MinBytes:    10e3, // 10KB
+		MaxBytes:    10e6, // 10MB
+		MaxWait:     conf.ReadTimeout,
+	})
+	return &KafkaConsumerGroup{reader: reader, handler: handler}, nil
+}
+
+// Run handles messages until ctx is done. It stops at the first handler
+// error without committing the message, which the group consumes again
+// once this member is closed and its partitions are reassigned.
+func (g *KafkaConsumerGroup) Run(ctx context.Context) error {
+	g.mu.Lock()
+	if g.running {
+		g.mu.Unlock()
+		return errors.New("kafka consumer group is already running")
+	}
+	g.running = true
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.running = false
+		g.mu.Unlock()
+	}()
+
+	for {
+		m, err := g.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch message: %w", err)
+		}
+
+		if err := g.handler(ctx, m); err != nil {
+			return fmt.Errorf("failed to handle message %s/%d/%d: %w", m.Topic, m.Partition, m.Offset, err)
+		}
+		if err := g.reader.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to commit message: %w", err)
+		}
+	}
+}
+
+// Stats returns the reader statistics since the last call
+func (g *KafkaConsumerGroup) Stats() kafka.ReaderStats {
+	return g.reader.Stats()
+}
+
+// Close leaves the consumer group
+func (g *KafkaConsumerGroup) Close() error {
+	return g.reader.Close()
+}
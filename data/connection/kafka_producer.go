@@ -0,0 +1,99 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ncobase/common/data/config"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaProducerOption function type for configuring KafkaProducer
+type KafkaProducerOption func(*KafkaProducer)
+
+// WithRequiredAcks sets the acks awaited for each batch, kafka.RequireAll by default
+func WithRequiredAcks(acks kafka.RequiredAcks) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.writer.RequiredAcks = acks
+	}
+}
+
+// WithBalancer sets the partition balancer, kafka.LeastBytes by default
+func WithBalancer(b kafka.Balancer) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.writer.Balancer = b
+	}
+}
+
+// WithCompression sets the compression codec of the batches
+func WithCompression(c kafka.Compression) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.writer.Compression = c
+	}
+}
+
+// KafkaProducer produces Kafka messages to the brokers of a config, with
+// its client ID, SASL and TLS settings
+type KafkaProducer struct {
+	writer *kafka.Writer
+	topic  string
+}
+
+// NewKafkaProducer creates a producer from a Kafka config
+func NewKafkaProducer(conf *config.Kafka, opts ...KafkaProducerOption) (*KafkaProducer, error) {
+	if conf == nil || len(conf.Brokers) == 0 {
+		return nil, errors.New("kafka configuration is nil or empty")
+	}
+	transport, err := newKafkaTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &KafkaProducer{topic: conf.Topic}
+	p.writer = &kafka.Writer{
+		Addr:         kafka.TCP(conf.Brokers...),
+		Balancer:     &kafka.LeastBytes{},
+		BatchTimeout: 10 * time.Millisecond,
+		ReadTimeout:  conf.ReadTimeout,
+		WriteTimeout: conf.WriteTimeout,
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Produce writes messages and waits for their acks, messages without a
+// topic go to topic, or to the topic of the config when topic is empty
+func (p *KafkaProducer) Produce(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	if topic == "" {
+		topic = p.topic
+	}
+	for i := range msgs {
+		if msgs[i].Topic == "" {
+			msgs[i].Topic = topic
+		}
+		if msgs[i].Topic == "" {
+			return errors.New("kafka message has no topic")
+		}
+	}
+
+	if err := p.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("failed to write messages: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the writer statistics since the last call
+func (p *KafkaProducer) Stats() kafka.WriterStats {
+	return p.writer.Stats()
+}
+
+// Close flushes the pending messages and closes the producer
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
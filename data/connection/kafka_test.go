@@ -0,0 +1,110 @@
+package connection
+
+import (
+	"context"
+	"ncobase/common/data/config"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNewKafkaSASLMechanism(t *testing.T) {
+	testCases := []struct {
+		mechanism string
+		want      string
+		wantErr   bool
+	}{
+		{mechanism: "", want: ""},
+		{mechanism: "plain", want: "PLAIN"},
+		{mechanism: "scram-sha-256", want: "SCRAM-SHA-256"},
+		{mechanism: "scram-sha-512", want: "SCRAM-SHA-512"},
+		{mechanism: "gssapi", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		name := tc.mechanism
+		if name == "" {
+			name = "disabled"
+		}
+		t.Run(name, func(t *testing.T) {
+			m, err := newKafkaSASLMechanism(&config.KafkaSASL{Mechanism: tc.mechanism, Username: "svc", Password: "secret"})
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.want == "" {
+				if m != nil {
+					t.Errorf("expected no mechanism, got %s", m.Name())
+				}
+				return
+			}
+			if m == nil || m.Name() != tc.want {
+				t.Errorf("expected mechanism %s, got %v", tc.want, m)
+			}
+		})
+	}
+}
+
+func TestNewKafkaDialer(t *testing.T) {
+	conf := &config.Kafka{
+		Brokers:  []string{"localhost:9092"},
+		ClientID: "svc",
+		SASL:     &config.KafkaSASL{Mechanism: "plain", Username: "svc", Password: "secret"},
+		TLS:      &config.TLS{Enabled: true, ServerName: "kafka.test"},
+	}
+	dialer, err := newKafkaDialer(conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dialer.ClientID != "svc" || dialer.SASLMechanism == nil || dialer.TLS == nil || dialer.TLS.ServerName != "kafka.test" {
+		t.Errorf("unexpected dialer: %+v", dialer)
+	}
+
+	conf.TLS.CAFile = "missing.pem"
+	if _, err := newKafkaDialer(conf); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+	if _, err := newKafkaTransport(conf); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+}
+
+func TestKafkaProducerTopic(t *testing.T) {
+	p, err := NewKafkaProducer(&config.Kafka{Brokers: []string{"localhost:9092"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	// Rejected before any broker is contacted
+	if err := p.Produce(context.Background(), "", kafka.Message{Value: []byte("v")}); err == nil {
+		t.Error("expected error for message without topic")
+	}
+}
+
+func TestNewKafkaConsumerGroup(t *testing.T) {
+	handler := func(context.Context, kafka.Message) error { return nil }
+
+	if _, err := NewKafkaConsumerGroup(&config.Kafka{Brokers: []string{"localhost:9092"}, Topic: "events"}, handler); err == nil {
+		t.Error("expected error without consumer group")
+	}
+	if _, err := NewKafkaConsumerGroup(&config.Kafka{Brokers: []string{"localhost:9092"}, ConsumerGroup: "svc"}, handler); err == nil {
+		t.Error("expected error without topic")
+	}
+
+	g, err := NewKafkaConsumerGroup(&config.Kafka{Brokers: []string{"localhost:9092"}, ConsumerGroup: "svc", Topic: "events"}, handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.reader.Config().GroupTopics; len(got) != 1 || got[0] != "events" {
+		t.Errorf("expected topics [events], got %v", got)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("unexpected close error: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package connection
 
 import (
 	"fmt"
+	"ncobase/common/data/breaker"
 	"ncobase/common/data/config"
 	"ncobase/common/data/meili"
 )
@@ -12,7 +13,8 @@ func newMeilisearchClient(conf *config.Meilisearch) (*meili.Client, error) {
 		return nil, fmt.Errorf("meilisearch configuration is nil or empty")
 	}
 
-	ms := meili.NewMeilisearch(conf.Host, conf.APIKey)
+	ms := meili.NewMeilisearch(conf.Host, conf.APIKey,
+		meili.WithCircuitBreaker(breaker.New("meilisearch", conf.Breaker)))
 
 	if _, err := ms.GetClient().Health(); err != nil {
 		return nil, fmt.Errorf("meilisearch connect error: %v", err)
@@ -6,13 +6,21 @@ import (
 	"fmt"
 	"math/rand"
 	"ncobase/common/data/config"
+	"ncobase/common/logger"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
+// defaultMongoServerSelectionTimeout bounds the startup ping when no server
+// selection timeout is set
+const defaultMongoServerSelectionTimeout = 10 * time.Second
+
 // MongoManager represents a MongoDB connection manager
 type MongoManager struct {
 	master   *mongo.Client
@@ -28,7 +36,7 @@ func NewMongoManager(conf *config.MongoDB) (*MongoManager, error) {
 	}
 
 	// connect to master
-	master, err := newMongoClient(conf.Master)
+	master, err := newMongoConnection(conf.Master)
 	if err != nil {
 		return nil, err
 	}
@@ -36,9 +44,9 @@ func NewMongoManager(conf *config.MongoDB) (*MongoManager, error) {
 	// connect to slaves
 	var slaves []*mongo.Client
 	for i, slaveCfg := range conf.Slaves {
-		slave, err := newMongoClient(slaveCfg)
+		slave, err := newMongoConnection(slaveCfg)
 		if err != nil {
-			fmt.Printf("Failed to connect to slave MongoDB %d: %v", i, err)
+			logger.Warnf(context.Background(), "Failed to connect to slave MongoDB %d: %v", i, err)
 			continue
 		}
 		slaves = append(slaves, slave)
@@ -224,6 +232,15 @@ func (m *MongoManager) GetCollection(dbName, collName string, readOnly bool) (*m
 	return m.master.Database(dbName).Collection(collName), nil
 }
 
+// Healthcheck checks the master MongoDB is reachable, slaves are not
+// required as reads fall back to the master
+func (m *MongoManager) Healthcheck(ctx context.Context) error {
+	if err := m.master.Ping(ctx, readpref.Primary()); err != nil {
+		return fmt.Errorf("master mongodb health check failed: %w", err)
+	}
+	return nil
+}
+
 // Health checks the health of all MongoDB connections
 func (m *MongoManager) Health(ctx context.Context) error {
 	// Check master health
@@ -261,14 +278,14 @@ func (m *MongoManager) Close(ctx context.Context) error {
 	var errs []error
 
 	// Close master
-	if err := m.master.Disconnect(ctx); err != nil {
+	if err := disconnectMongo(ctx, m.master, "master"); err != nil {
 		errs = append(errs, fmt.Errorf("error closing master connection: %v", err))
 	}
 
 	// Close slaves
 	for i, slave := range m.slaves {
 		if slave != m.master { // Avoid double closing the master
-			if err := slave.Disconnect(ctx); err != nil {
+			if err := disconnectMongo(ctx, slave, fmt.Sprintf("slave %d", i)); err != nil {
 				errs = append(errs, fmt.Errorf("error closing slave %d connection: %v", i, err))
 			}
 		}
@@ -281,21 +298,97 @@ func (m *MongoManager) Close(ctx context.Context) error {
 	return nil
 }
 
-// newMongoClient creates a new MongoDB client
-func newMongoClient(conf *config.MongoNode) (*mongo.Client, error) {
+// newMongoConnection creates a MongoDB client and pings it, so a wrong URI,
+// credential or unreachable replica set fails at startup
+func newMongoConnection(conf *config.MongoNode) (*mongo.Client, error) {
 	if conf == nil || conf.URI == "" {
 		return nil, errors.New("mongodb configuration is nil or empty")
 	}
 
-	clientOptions := options.Client().ApplyURI(conf.URI)
+	opts, err := mongoClientOptions(conf)
+	if err != nil {
+		return nil, err
+	}
+	ctx := logger.WithField(context.Background(), "hosts", strings.Join(opts.Hosts, ","))
+	if conf.ReplicaSet != "" {
+		ctx = logger.WithField(ctx, "replica_set", conf.ReplicaSet)
+	}
 
-	client, err := mongo.Connect(context.Background(), clientOptions)
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("MongoDB connect error: %v", err)
 	}
-	if err := client.Ping(context.Background(), nil); err != nil {
+
+	timeout := conf.ServerSelectionTimeout
+	if timeout <= 0 {
+		timeout = defaultMongoServerSelectionTimeout
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := client.Ping(pingCtx, nil); err != nil {
+		_ = client.Disconnect(ctx)
 		return nil, fmt.Errorf("MongoDB ping error: %v", err)
 	}
 
+	logger.Infof(ctx, "MongoDB connected")
 	return client, nil
 }
+
+// mongoClientOptions returns the client options of the URI with the
+// configured fields applied over it
+func mongoClientOptions(conf *config.MongoNode) (*options.ClientOptions, error) {
+	opts := options.Client().ApplyURI(conf.URI)
+	if conf.Username != "" {
+		opts.SetAuth(options.Credential{
+			Username:      conf.Username,
+			Password:      conf.Password,
+			AuthSource:    conf.AuthSource,
+			AuthMechanism: conf.AuthMechanism,
+		})
+	}
+	if conf.ReplicaSet != "" {
+		opts.SetReplicaSet(conf.ReplicaSet)
+	}
+	if conf.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(conf.ReadPreference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MongoDB read preference: %w", err)
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MongoDB read preference: %w", err)
+		}
+		opts.SetReadPreference(rp)
+	}
+	if conf.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(conf.MaxPoolSize)
+	}
+	if conf.MinPoolSize > 0 {
+		opts.SetMinPoolSize(conf.MinPoolSize)
+	}
+	if conf.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(conf.MaxConnIdleTime)
+	}
+	if conf.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(conf.ConnectTimeout)
+	}
+	if conf.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(conf.ServerSelectionTimeout)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MongoDB options: %w", err)
+	}
+	return opts, nil
+}
+
+// disconnectMongo disconnects a MongoDB client, logging the outcome
+func disconnectMongo(ctx context.Context, client *mongo.Client, role string) error {
+	ctx = logger.WithField(ctx, "role", role)
+	if err := client.Disconnect(ctx); err != nil {
+		logger.Warnf(ctx, "MongoDB disconnect error: %v", err)
+		return err
+	}
+	logger.Infof(ctx, "MongoDB disconnected")
+	return nil
+}
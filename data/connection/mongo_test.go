@@ -0,0 +1,62 @@
+package connection
+
+import (
+	"ncobase/common/data/config"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestMongoClientOptions(t *testing.T) {
+	conf := &config.MongoNode{
+		URI:                    "mongodb://db1:27017,db2:27017/app?maxPoolSize=5",
+		Username:               "svc",
+		Password:               "secret",
+		AuthSource:             "admin",
+		ReplicaSet:             "rs0",
+		ReadPreference:         "secondaryPreferred",
+		MaxPoolSize:            50,
+		MinPoolSize:            5,
+		ServerSelectionTimeout: 3 * time.Second,
+	}
+	opts, err := mongoClientOptions(conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(opts.Hosts) != 2 {
+		t.Errorf("expected 2 hosts, got %v", opts.Hosts)
+	}
+	if opts.Auth == nil || opts.Auth.Username != "svc" || opts.Auth.AuthSource != "admin" {
+		t.Errorf("unexpected credential %+v", opts.Auth)
+	}
+	if opts.ReplicaSet == nil || *opts.ReplicaSet != "rs0" {
+		t.Errorf("expected replica set rs0, got %v", opts.ReplicaSet)
+	}
+	if opts.ReadPreference == nil || opts.ReadPreference.Mode() != readpref.SecondaryPreferredMode {
+		t.Errorf("expected secondaryPreferred read preference, got %v", opts.ReadPreference)
+	}
+	// Configured fields override the URI options
+	if opts.MaxPoolSize == nil || *opts.MaxPoolSize != 50 {
+		t.Errorf("expected max pool size 50, got %v", opts.MaxPoolSize)
+	}
+	if opts.ServerSelectionTimeout == nil || *opts.ServerSelectionTimeout != 3*time.Second {
+		t.Errorf("expected server selection timeout 3s, got %v", opts.ServerSelectionTimeout)
+	}
+}
+
+func TestMongoClientOptionsInvalid(t *testing.T) {
+	for name, conf := range map[string]*config.MongoNode{
+		"read preference": {URI: "mongodb://localhost:27017", ReadPreference: "fastest"},
+		"pool sizes":      {URI: "mongodb://localhost:27017", MaxPoolSize: 5, MinPoolSize: 10},
+		"uri":             {URI: "postgres://localhost:5432"},
+	} {
+		if _, err := mongoClientOptions(conf); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := newMongoConnection(&config.MongoNode{}); err == nil {
+		t.Error("expected error for empty URI")
+	}
+}
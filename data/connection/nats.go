@@ -0,0 +1,63 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"ncobase/common/data/config"
+
+	"github.com/nats-io/nats.go"
+)
+
+// newNATSConnection creates a new NATS connection, reconnecting on its own
+// when the server is lost
+func newNATSConnection(conf *config.NATS) (*nats.Conn, error) {
+	if conf == nil || conf.URL == "" {
+		return nil, errors.New("NATS configuration is nil or empty")
+	}
+
+	opts, err := natsOptions(conf)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := nats.Connect(conf.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	return conn, nil
+}
+
+// natsOptions returns the connect options of a NATS config
+func natsOptions(conf *config.NATS) ([]nats.Option, error) {
+	var opts []nats.Option
+	if conf.Name != "" {
+		opts = append(opts, nats.Name(conf.Name))
+	}
+	if conf.Username != "" || conf.Password != "" {
+		opts = append(opts, nats.UserInfo(conf.Username, conf.Password))
+	}
+	if conf.Token != "" {
+		opts = append(opts, nats.Token(conf.Token))
+	}
+	if conf.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(conf.CredsFile))
+	}
+	if conf.ConnectTimeout > 0 {
+		opts = append(opts, nats.Timeout(conf.ConnectTimeout))
+	}
+	if conf.ReconnectWait > 0 {
+		opts = append(opts, nats.ReconnectWait(conf.ReconnectWait))
+	}
+	if conf.MaxReconnects != 0 {
+		opts = append(opts, nats.MaxReconnects(conf.MaxReconnects))
+	}
+
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS TLS config: %w", err)
+	}
+	if tlsConfig != nil {
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+	return opts, nil
+}
@@ -0,0 +1,65 @@
+package connection
+
+import (
+	"ncobase/common/data/config"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestNATSOptions(t *testing.T) {
+	conf := &config.NATS{
+		URL:            "nats://127.0.0.1:4222",
+		Name:           "svc",
+		Username:       "svc",
+		Password:       "secret",
+		ConnectTimeout: 3 * time.Second,
+		ReconnectWait:  time.Second,
+		MaxReconnects:  -1,
+		TLS:            &config.TLS{Enabled: true, ServerName: "nats.test"},
+	}
+	opts, err := natsOptions(conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	o := nats.GetDefaultOptions()
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			t.Fatalf("unexpected option error: %v", err)
+		}
+	}
+	if o.Name != "svc" || o.User != "svc" || o.Password != "secret" {
+		t.Errorf("unexpected identity %q %q/%q", o.Name, o.User, o.Password)
+	}
+	if o.Timeout != 3*time.Second || o.ReconnectWait != time.Second || o.MaxReconnect != -1 {
+		t.Errorf("unexpected timeouts %v %v %d", o.Timeout, o.ReconnectWait, o.MaxReconnect)
+	}
+	if !o.Secure || o.TLSConfig == nil || o.TLSConfig.ServerName != "nats.test" {
+		t.Errorf("expected TLS for nats.test, got %+v", o.TLSConfig)
+	}
+
+	// Unset values keep the client defaults
+	opts, err = natsOptions(&config.NATS{URL: conf.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(opts) != 0 {
+		t.Errorf("expected no options, got %d", len(opts))
+	}
+
+	conf.TLS.CAFile = "missing.pem"
+	if _, err := natsOptions(conf); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+}
+
+func TestNewNATSConnectionEmpty(t *testing.T) {
+	if _, err := newNATSConnection(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := newNATSConnection(&config.NATS{}); err == nil {
+		t.Error("expected error for empty URL")
+	}
+}
@@ -0,0 +1,115 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"ncobase/common/data/config"
+	"ncobase/common/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// defaultPostgresConnectTimeout bounds the startup ping of a postgres pool
+const defaultPostgresConnectTimeout = 5 * time.Second
+
+// newPostgresConnection creates a pgx pool for a postgres node and a
+// database/sql handle on top of it. Closing the handle leaves the pool
+// open, both must be closed.
+func newPostgresConnection(conf *config.DBNode) (*sql.DB, *pgxpool.Pool, error) {
+	poolConfig, err := postgresPoolConfig(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPostgresConnectTimeout)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create postgres pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	return stdlib.OpenDBFromPool(pool), pool, nil
+}
+
+// postgresPoolConfig returns the pool config of a postgres node, pool
+// settings of the source are kept unless the node sets them
+func postgresPoolConfig(conf *config.DBNode) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(conf.Source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres source: %w", err)
+	}
+
+	if conf.MaxOpenConn > 0 {
+		poolConfig.MaxConns = int32(conf.MaxOpenConn)
+	}
+	if conf.MinConn > 0 {
+		poolConfig.MinConns = int32(conf.MinConn)
+	}
+	if poolConfig.MinConns > poolConfig.MaxConns {
+		return nil, fmt.Errorf("postgres min_conn %d exceeds max_open_conn %d", poolConfig.MinConns, poolConfig.MaxConns)
+	}
+	if conf.ConnMaxLifeTime > 0 {
+		poolConfig.MaxConnLifetime = conf.ConnMaxLifeTime
+	}
+	if conf.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = conf.MaxConnIdleTime
+	}
+	if conf.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = conf.HealthCheckPeriod
+	}
+	if conf.Logging || conf.SlowThreshold > 0 {
+		poolConfig.ConnConfig.Tracer = &pgxLogTracer{logging: conf.Logging, slowThreshold: conf.SlowThreshold}
+	}
+
+	return poolConfig, nil
+}
+
+// pgxQueryKey is the context key of the traced statement
+type pgxQueryKey struct{}
+
+// pgxQuery is a statement in flight
+type pgxQuery struct {
+	sql   string
+	start time.Time
+}
+
+// pgxLogTracer logs the statements of a pool. Failed statements are logged
+// as errors, statements slower than slowThreshold as warnings, and all other
+// statements at debug level when logging is on. Arguments are never logged.
+type pgxLogTracer struct {
+	logging       bool
+	slowThreshold time.Duration
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *pgxLogTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, pgxQueryKey{}, &pgxQuery{sql: data.SQL, start: time.Now()})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *pgxLogTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(pgxQueryKey{}).(*pgxQuery)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(q.start)
+	entry := logger.WithDuration(ctx, logger.SQLDurationKey, elapsed)
+
+	switch {
+	case data.Err != nil:
+		entry.Errorf("SQL failed: %s: %v", q.sql, data.Err)
+	case t.slowThreshold > 0 && elapsed >= t.slowThreshold:
+		entry.Warnf("Slow SQL (%s): %s", data.CommandTag, q.sql)
+	case t.logging:
+		entry.Debugf("SQL (%s): %s", data.CommandTag, q.sql)
+	}
+}
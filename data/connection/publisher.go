@@ -0,0 +1,326 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/metrics"
+	"ncobase/common/data/rabbitmq"
+	"ncobase/common/uuid"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	// ErrPublishNacked is returned when the broker rejects a published message
+	ErrPublishNacked = errors.New("rabbitmq message nacked by the broker")
+	// ErrPublishUnroutable is returned when a mandatory message matches no queue
+	ErrPublishUnroutable = errors.New("rabbitmq message unroutable")
+	// errPublishChannelClosed fails the messages awaiting a confirm on a closed channel
+	errPublishChannelClosed = errors.New("rabbitmq publish channel closed")
+)
+
+// RetryPolicy controls the retries of a publish, nacked messages and
+// channel or connection failures are retried, unroutable messages and
+// context errors are not
+type RetryPolicy struct {
+	MaxAttempts int           // attempts including the first one, 1 disables retries
+	Backoff     time.Duration // delay before the first retry, doubled after every retry
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a Publisher
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// PublisherOption function type for configuring Publisher
+type PublisherOption func(*Publisher)
+
+// WithRetryPolicy sets the retry policy of a Publisher
+func WithRetryPolicy(p RetryPolicy) PublisherOption {
+	return func(pub *Publisher) {
+		pub.retry = p
+	}
+}
+
+// WithConfirmTimeout sets the max wait for a confirm when the context of
+// Publish has no deadline, 5s by default
+func WithConfirmTimeout(d time.Duration) PublisherOption {
+	return func(pub *Publisher) {
+		pub.confirmTimeout = d
+	}
+}
+
+// WithMandatory sets whether messages are published as mandatory, so those
+// matching no queue fail with ErrPublishUnroutable, true by default
+func WithMandatory(mandatory bool) PublisherOption {
+	return func(pub *Publisher) {
+		pub.mandatory = mandatory
+	}
+}
+
+// WithPublisherCircuitBreaker fails publishes fast with breaker.ErrOpen once
+// the broker keeps failing them, unroutable messages do not count as failures
+func WithPublisherCircuitBreaker(b *breaker.Breaker) PublisherOption {
+	return func(pub *Publisher) {
+		pub.breaker = b
+	}
+}
+
+// Publisher publishes RabbitMQ messages on a channel in confirm mode and
+// waits for the broker to confirm each of them. The channel is shared by
+// concurrent publishes and reopened after it is closed.
+type Publisher struct {
+	conn           *rabbitmq.Conn
+	retry          RetryPolicy
+	confirmTimeout time.Duration
+	mandatory      bool
+	breaker        *breaker.Breaker
+
+	mu     sync.Mutex // serializes publishes so delivery tags follow the sequence
+	ch     *amqp.Channel
+	state  *confirmState
+	closed bool
+}
+
+// confirmState tracks the messages awaiting a confirm on a channel
+type confirmState struct {
+	mu       sync.Mutex
+	pending  map[uint64]*pendingPublish
+	returned map[string]amqp.Return
+	err      error // set once the channel is closed
+}
+
+// pendingPublish is a message awaiting its confirm
+type pendingPublish struct {
+	id   string
+	done chan error
+}
+
+// NewPublisher creates a publisher on a RabbitMQ connection
+func NewPublisher(conn *rabbitmq.Conn, opts ...PublisherOption) *Publisher {
+	p := &Publisher{
+		conn:           conn,
+		retry:          DefaultRetryPolicy,
+		confirmTimeout: 5 * time.Second,
+		mandatory:      true,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Publish publishes msg and waits until the broker confirms it, retrying
+// according to the retry policy. A message without MessageId gets a random
+// one, which identifies it when it is returned as unroutable.
+func (p *Publisher) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) (err error) {
+	defer func() {
+		if err != nil {
+			metrics.RabbitMQPublishFailed(exchange)
+		}
+	}()
+
+	if msg.MessageId == "" {
+		msg.MessageId = uuid.NewString()
+	}
+	if _, ok := ctx.Deadline(); !ok && p.confirmTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.confirmTimeout)
+		defer cancel()
+	}
+
+	backoff := p.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		err = p.attempt(ctx, exchange, key, msg)
+		if err == nil || attempt >= p.retry.MaxAttempts || !retryablePublish(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w, last error: %v", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, p.retry.MaxBackoff)
+	}
+}
+
+// attempt publishes msg once through the circuit breaker
+func (p *Publisher) attempt(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	var err error
+	if berr := p.breaker.Execute(func() error {
+		err = p.publish(ctx, exchange, key, msg)
+		if errors.Is(err, ErrPublishUnroutable) {
+			return nil
+		}
+		return err
+	}); berr != nil && err == nil {
+		return berr
+	}
+	return err
+}
+
+// publish publishes msg once and waits for its confirm
+func (p *Publisher) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	done := make(chan error, 1)
+
+	p.mu.Lock()
+	ch, st, err := p.channel()
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	tag := ch.GetNextPublishSeqNo()
+	st.add(tag, msg.MessageId, done)
+	err = ch.PublishWithContext(ctx, exchange, key, p.mandatory, false, msg)
+	p.mu.Unlock()
+	if err != nil {
+		st.remove(tag)
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		st.remove(tag)
+		return fmt.Errorf("failed to confirm message %s: %w", msg.MessageId, ctx.Err())
+	}
+}
+
+// channel returns the confirm mode channel, opening it when needed, the
+// caller holds p.mu
+func (p *Publisher) channel() (*amqp.Channel, *confirmState, error) {
+	if p.closed {
+		return nil, nil, rabbitmq.ErrConnectionClosed
+	}
+	if p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, p.state, nil
+	}
+	if p.conn == nil {
+		return nil, nil, rabbitmq.ErrConnectionClosed
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	st := &confirmState{pending: map[uint64]*pendingPublish{}, returned: map[string]amqp.Return{}}
+	// Unbuffered so returns are handled before the confirm that follows them
+	go st.dispatch(
+		ch.NotifyPublish(make(chan amqp.Confirmation)),
+		ch.NotifyReturn(make(chan amqp.Return)),
+		ch.NotifyClose(make(chan *amqp.Error, 1)),
+	)
+	p.ch, p.state = ch, st
+	return ch, st, nil
+}
+
+// Close closes the channel of the publisher, the connection stays open
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	if p.ch == nil || p.ch.IsClosed() {
+		return nil
+	}
+	return p.ch.Close()
+}
+
+// add registers a message awaiting its confirm, failing it right away if
+// the channel is already closed
+func (st *confirmState) add(tag uint64, id string, done chan error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.err != nil {
+		done <- st.err
+		return
+	}
+	st.pending[tag] = &pendingPublish{id: id, done: done}
+}
+
+// remove forgets a message that will not be confirmed
+func (st *confirmState) remove(tag uint64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if pp, ok := st.pending[tag]; ok {
+		delete(st.pending, tag)
+		delete(st.returned, pp.id)
+	}
+}
+
+// dispatch settles the pending messages with the confirms and returns of
+// the channel until it is closed, then fails the remaining ones
+func (st *confirmState) dispatch(confirms <-chan amqp.Confirmation, returns <-chan amqp.Return, closes <-chan *amqp.Error) {
+	for {
+		select {
+		case r, ok := <-returns:
+			if !ok {
+				returns = nil
+				continue
+			}
+			st.mu.Lock()
+			st.returned[r.MessageId] = r
+			st.mu.Unlock()
+
+		case c, ok := <-confirms:
+			if !ok {
+				st.fail(<-closes)
+				return
+			}
+			st.settle(c)
+		}
+	}
+}
+
+// settle completes the message of a confirm
+func (st *confirmState) settle(c amqp.Confirmation) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	pp, ok := st.pending[c.DeliveryTag]
+	if !ok {
+		return
+	}
+	delete(st.pending, c.DeliveryTag)
+	r, returned := st.returned[pp.id]
+	delete(st.returned, pp.id)
+
+	switch {
+	case !c.Ack:
+		pp.done <- fmt.Errorf("%w: %s", ErrPublishNacked, pp.id)
+	case returned:
+		pp.done <- fmt.Errorf("%w: %s (%d %s)", ErrPublishUnroutable, pp.id, r.ReplyCode, r.ReplyText)
+	default:
+		pp.done <- nil
+	}
+}
+
+// fail fails the pending messages once the channel is closed
+func (st *confirmState) fail(reason *amqp.Error) {
+	err := errPublishChannelClosed
+	if reason != nil {
+		err = fmt.Errorf("%w: %v", errPublishChannelClosed, reason)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.err = err
+	for tag, pp := range st.pending {
+		pp.done <- err
+		delete(st.pending, tag)
+	}
+}
+
+// retryablePublish reports whether a failed publish may succeed again
+func retryablePublish(err error) bool {
+	return errors.Is(err, ErrPublishNacked) ||
+		errors.Is(err, errPublishChannelClosed) ||
+		errors.Is(err, amqp.ErrClosed)
+}
@@ -0,0 +1,60 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"ncobase/common/data/rabbitmq"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConfirmState_Dispatch(t *testing.T) {
+	st := &confirmState{pending: map[uint64]*pendingPublish{}, returned: map[string]amqp.Return{}}
+	confirms := make(chan amqp.Confirmation)
+	returns := make(chan amqp.Return)
+	closes := make(chan *amqp.Error, 1)
+	go st.dispatch(confirms, returns, closes)
+
+	acked, unroutable, nacked, pending := make(chan error, 1), make(chan error, 1), make(chan error, 1), make(chan error, 1)
+	st.add(1, "acked", acked)
+	st.add(2, "unroutable", unroutable)
+	st.add(3, "nacked", nacked)
+	st.add(4, "pending", pending)
+
+	confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	// The broker returns an unroutable mandatory message before acking it
+	returns <- amqp.Return{MessageId: "unroutable", ReplyCode: 312, ReplyText: "NO_ROUTE"}
+	confirms <- amqp.Confirmation{DeliveryTag: 2, Ack: true}
+	confirms <- amqp.Confirmation{DeliveryTag: 3, Ack: false}
+
+	if err := <-acked; err != nil {
+		t.Errorf("expected the message to be confirmed, got %v", err)
+	}
+	if err := <-unroutable; !errors.Is(err, ErrPublishUnroutable) || retryablePublish(err) {
+		t.Errorf("expected a final ErrPublishUnroutable, got %v", err)
+	}
+	if err := <-nacked; !errors.Is(err, ErrPublishNacked) || !retryablePublish(err) {
+		t.Errorf("expected a retryable ErrPublishNacked, got %v", err)
+	}
+
+	closes <- &amqp.Error{Code: amqp.ChannelError, Reason: "channel closed"}
+	close(confirms)
+	close(returns)
+	if err := <-pending; !errors.Is(err, errPublishChannelClosed) || !retryablePublish(err) {
+		t.Errorf("expected a retryable channel error, got %v", err)
+	}
+
+	late := make(chan error, 1)
+	st.add(5, "late", late)
+	if err := <-late; !errors.Is(err, errPublishChannelClosed) {
+		t.Errorf("expected messages on a closed channel to fail, got %v", err)
+	}
+}
+
+func TestPublisher_Closed(t *testing.T) {
+	p := NewPublisher(nil, WithRetryPolicy(RetryPolicy{MaxAttempts: 5}))
+	if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{Body: []byte("msg")}); !errors.Is(err, rabbitmq.ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+}
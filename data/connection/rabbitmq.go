@@ -4,24 +4,119 @@ import (
 	"errors"
 	"fmt"
 	"ncobase/common/data/config"
+	"ncobase/common/data/rabbitmq"
+	"net"
+	"net/url"
+	"strconv"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 // newRabbitMQConnection creates a new RabbitMQ connection
 func newRabbitMQConnection(conf *config.RabbitMQ) (*amqp.Connection, error) {
-	if conf == nil || conf.URL == "" {
+	if conf == nil || (conf.URL == "" && conf.URI == "") {
 		return nil, errors.New("RabbitMQ configuration is nil or empty")
 	}
 
-	url := fmt.Sprintf("amqp://%s:%s@%s/%s", conf.Username, conf.Password, conf.URL, conf.Vhost)
-	conn, err := amqp.DialConfig(url, amqp.Config{
-		Heartbeat: conf.HeartbeatInterval,
-		Vhost:     conf.Vhost,
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RabbitMQ TLS config: %w", err)
+	}
+
+	dialURL, vhost := buildRabbitMQURL(conf), conf.Vhost
+	if conf.URI != "" {
+		u, err := amqp.ParseURI(conf.URI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RabbitMQ URI: %w", uriError(err))
+		}
+		// amqp091 only negotiates TLS for amqps URIs
+		if tlsConfig != nil && u.Scheme != "amqps" {
+			return nil, errors.New("RabbitMQ TLS is enabled but the URI scheme is not amqps")
+		}
+		// The vhost comes from the URI
+		dialURL, vhost = conf.URI, ""
+	}
+
+	conn, err := amqp.DialConfig(dialURL, amqp.Config{
+		Heartbeat:       conf.HeartbeatInterval,
+		Vhost:           vhost,
+		TLSClientConfig: tlsConfig,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		return nil, fmt.Errorf("failed to connect to RabbitMQ at %s: %w", redactURL(dialURL), err)
 	}
 
 	return conn, nil
 }
+
+// newRabbitMQConn creates a RabbitMQ connection redialed when lost
+func newRabbitMQConn(conf *config.RabbitMQ) (*rabbitmq.Conn, error) {
+	return rabbitmq.Dial(func() (*amqp.Connection, error) {
+		return newRabbitMQConnection(conf)
+	})
+}
+
+// buildRabbitMQURL builds the AMQP URL, escaping credentials and vhost,
+// with the amqps scheme when TLS is enabled
+//
+// The vhost is a single path segment, so the default vhost "/" becomes "%2F".
+func buildRabbitMQURL(conf *config.RabbitMQ) string {
+	u := url.URL{
+		Scheme: "amqp",
+		Host:   conf.URL,
+	}
+	if conf.TLS != nil && conf.TLS.Enabled {
+		u.Scheme = "amqps"
+	}
+	if conf.Username != "" || conf.Password != "" {
+		u.User = url.UserPassword(conf.Username, conf.Password)
+	}
+	if conf.Vhost != "" {
+		u.Path = "/" + conf.Vhost
+		u.RawPath = "/" + url.PathEscape(conf.Vhost)
+	}
+	return u.String()
+}
+
+// RedactRabbitMQURL returns the URL dialed for conf with the password
+// masked, safe to log
+func RedactRabbitMQURL(conf *config.RabbitMQ) string {
+	if conf.URI != "" {
+		return redactURL(conf.URI)
+	}
+	return redactURL(buildRabbitMQURL(conf))
+}
+
+// redactURL masks the password of a URL, an unparsable URL is hidden whole
+// as it may contain the password anywhere
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return u.Redacted()
+}
+
+// uriError drops the URI, and the password in it, from a URI parse error
+func uriError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// ParseRabbitMQURI parses an amqp:// or amqps:// URI into discrete RabbitMQ config fields
+func ParseRabbitMQURI(uri string) (*config.RabbitMQ, error) {
+	u, err := amqp.ParseURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RabbitMQ URI: %w", uriError(err))
+	}
+	return &config.RabbitMQ{
+		URL:      net.JoinHostPort(u.Host, strconv.Itoa(u.Port)),
+		Username: u.Username,
+		Password: u.Password,
+		Vhost:    u.Vhost,
+		TLS:      &config.TLS{Enabled: u.Scheme == "amqps"},
+	}, nil
+}
@@ -5,32 +5,112 @@ import (
 	"errors"
 	"fmt"
 	"ncobase/common/data/config"
+	"slices"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-// newRedisClient creates a new Redis client
-func newRedisClient(conf *config.Redis) (*redis.Client, error) {
-	if conf == nil || conf.Addr == "" {
+// defaultRedisDialTimeout bounds the first ping when no dial timeout is set
+const defaultRedisDialTimeout = 5 * time.Second
+
+// newRedisConnection creates a Redis client of the configured topology,
+// a standalone server, a Sentinel monitored master or a Cluster
+func newRedisConnection(conf *config.Redis) (redis.UniversalClient, error) {
+	if conf == nil || len(redisAddrs(conf)) == 0 {
 		return nil, errors.New("redis configuration is nil or empty")
 	}
 
-	rc := redis.NewClient(&redis.Options{
-		Addr:         conf.Addr,
-		Username:     conf.Username,
-		Password:     conf.Password,
-		DB:           conf.Db,
-		ReadTimeout:  conf.ReadTimeout,
-		WriteTimeout: conf.WriteTimeout,
-		DialTimeout:  conf.DialTimeout,
-		PoolSize:     10,
-	})
+	opts, err := redisOptions(conf)
+	if err != nil {
+		return nil, err
+	}
 
-	timeout, cancelFunc := context.WithTimeout(context.Background(), conf.DialTimeout)
+	var rc redis.UniversalClient
+	switch mode := redisMode(conf); mode {
+	case config.RedisSentinel:
+		if conf.MasterName == "" {
+			return nil, errors.New("redis sentinel mode needs a master name")
+		}
+		rc = redis.NewFailoverClient(opts.Failover())
+	case config.RedisCluster:
+		rc = redis.NewClusterClient(opts.Cluster())
+	case config.RedisStandalone:
+		if len(opts.Addrs) > 1 {
+			return nil, errors.New("redis standalone mode takes a single address")
+		}
+		rc = redis.NewClient(opts.Simple())
+	default:
+		return nil, fmt.Errorf("unsupported redis mode %q", mode)
+	}
+
+	timeout := conf.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultRedisDialTimeout
+	}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
 	defer cancelFunc()
-	if err := rc.Ping(timeout).Err(); err != nil {
+	if err := rc.Ping(ctx).Err(); err != nil {
+		_ = rc.Close()
 		return nil, fmt.Errorf("redis connect error: %v", err)
 	}
 
 	return rc, nil
 }
+
+// redisOptions returns the client options shared by all topologies
+func redisOptions(conf *config.Redis) (*redis.UniversalOptions, error) {
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis TLS config: %w", err)
+	}
+
+	poolSize := conf.PoolSize
+	if poolSize <= 0 {
+		poolSize = 10
+	}
+	return &redis.UniversalOptions{
+		Addrs:            redisAddrs(conf),
+		MasterName:       conf.MasterName,
+		Username:         conf.Username,
+		Password:         conf.Password,
+		SentinelUsername: conf.SentinelUsername,
+		SentinelPassword: conf.SentinelPassword,
+		DB:               conf.Db,
+		PoolSize:         poolSize,
+		MinIdleConns:     conf.MinIdleConns,
+		ReadOnly:         conf.ReadOnly,
+		ReadTimeout:      conf.ReadTimeout,
+		WriteTimeout:     conf.WriteTimeout,
+		DialTimeout:      conf.DialTimeout,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+// redisMode returns the configured topology, inferred when not set
+func redisMode(conf *config.Redis) string {
+	switch {
+	case conf.Mode != "":
+		return conf.Mode
+	case conf.MasterName != "":
+		return config.RedisSentinel
+	case len(redisAddrs(conf)) > 1:
+		return config.RedisCluster
+	default:
+		return config.RedisStandalone
+	}
+}
+
+// redisAddrs returns the addresses of the config, Addr first
+func redisAddrs(conf *config.Redis) []string {
+	var addrs []string
+	if conf.Addr != "" {
+		addrs = append(addrs, conf.Addr)
+	}
+	for _, addr := range conf.Addrs {
+		if addr != "" && !slices.Contains(addrs, addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,71 @@
+package connection
+
+import (
+	"ncobase/common/data/config"
+	"slices"
+	"testing"
+)
+
+func TestRedisMode(t *testing.T) {
+	testCases := []struct {
+		name string
+		conf *config.Redis
+		want string
+	}{
+		{name: "single address", conf: &config.Redis{Addr: "localhost:6379"}, want: config.RedisStandalone},
+		{name: "master name", conf: &config.Redis{Addrs: []string{"s1:26379", "s2:26379"}, MasterName: "mymaster"}, want: config.RedisSentinel},
+		{name: "several addresses", conf: &config.Redis{Addrs: []string{"n1:6379", "n2:6379"}}, want: config.RedisCluster},
+		{name: "explicit cluster seed", conf: &config.Redis{Mode: config.RedisCluster, Addr: "cluster.example.com:6379"}, want: config.RedisCluster},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := redisMode(tc.conf); got != tc.want {
+				t.Errorf("expected mode %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRedisOptions(t *testing.T) {
+	conf := &config.Redis{
+		Addr:       "n1:6379",
+		Addrs:      []string{"n1:6379", "n2:6379", ""},
+		MasterName: "mymaster",
+		Password:   "secret",
+		Db:         2,
+		TLS:        &config.TLS{Enabled: true, ServerName: "redis.test"},
+	}
+	opts, err := redisOptions(conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(opts.Addrs, []string{"n1:6379", "n2:6379"}) {
+		t.Errorf("unexpected addresses %v", opts.Addrs)
+	}
+	if opts.PoolSize != 10 || opts.DB != 2 || opts.Password != "secret" || opts.MasterName != "mymaster" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if opts.TLSConfig == nil || opts.TLSConfig.ServerName != "redis.test" {
+		t.Errorf("expected TLS for redis.test, got %+v", opts.TLSConfig)
+	}
+
+	conf.TLS.CAFile = "missing.pem"
+	if _, err := redisOptions(conf); err == nil {
+		t.Error("expected error for missing CA file")
+	}
+}
+
+func TestNewRedisConnectionInvalid(t *testing.T) {
+	for name, conf := range map[string]*config.Redis{
+		"nil":                   nil,
+		"no address":            {},
+		"sentinel no master":    {Mode: config.RedisSentinel, Addrs: []string{"s1:26379"}},
+		"standalone many addrs": {Mode: config.RedisStandalone, Addrs: []string{"n1:6379", "n2:6379"}},
+		"unknown mode":          {Mode: "ring", Addr: "localhost:6379"},
+	} {
+		if _, err := newRedisConnection(conf); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package connection
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"ncobase/common/data/config"
+	"os"
+)
+
+// newTLSConfig builds the TLS client config of a connection, nil when TLS
+// is not enabled
+func newTLSConfig(conf *config.TLS) (*tls.Config, error) {
+	if conf == nil || !conf.Enabled {
+		return nil, nil
+	}
+
+	tc := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         conf.ServerName,
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+	if conf.CAFile != "" {
+		pem, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", conf.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		if conf.CertFile == "" || conf.KeyFile == "" {
+			return nil, errors.New("client certificate needs both a cert and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
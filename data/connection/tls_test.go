@@ -0,0 +1,92 @@
+package connection
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"ncobase/common/data/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate and its key to dir
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mq.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir)
+
+	if tc, err := newTLSConfig(nil); tc != nil || err != nil {
+		t.Errorf("expected no TLS config when unset, got %v, %v", tc, err)
+	}
+	if tc, err := newTLSConfig(&config.TLS{CAFile: certFile}); tc != nil || err != nil {
+		t.Errorf("expected no TLS config when disabled, got %v, %v", tc, err)
+	}
+
+	tc, err := newTLSConfig(&config.TLS{
+		Enabled:    true,
+		CAFile:     certFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "mq.test",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tc.RootCAs == nil || len(tc.Certificates) != 1 || tc.ServerName != "mq.test" {
+		t.Errorf("unexpected TLS config: %+v", tc)
+	}
+
+	bad := filepath.Join(dir, "bad.pem")
+	if err := os.WriteFile(bad, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for name, conf := range map[string]*config.TLS{
+		"missing CA":   {Enabled: true, CAFile: filepath.Join(dir, "missing.pem")},
+		"invalid CA":   {Enabled: true, CAFile: bad},
+		"cert no key":  {Enabled: true, CertFile: certFile},
+		"invalid pair": {Enabled: true, CertFile: bad, KeyFile: keyFile},
+	} {
+		if _, err := newTLSConfig(conf); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
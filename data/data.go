@@ -5,11 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"ncobase/common/data/breaker"
 	"ncobase/common/data/config"
 	"ncobase/common/data/connection"
 	"ncobase/common/data/elastic"
 	"ncobase/common/data/kafka"
 	"ncobase/common/data/meili"
+	"ncobase/common/data/nats"
 	"ncobase/common/data/rabbitmq"
 
 	"github.com/redis/go-redis/v9"
@@ -32,6 +34,7 @@ type Data struct {
 	Conn     *connection.Connections
 	RabbitMQ *rabbitmq.RabbitMQ
 	Kafka    *kafka.Kafka
+	NATS     *nats.NATS
 }
 
 // Option function type for configuring Connections
@@ -60,8 +63,9 @@ func New(cfg *config.Config, createNewInstance ...bool) (*Data, func(name ...str
 
 	d := &Data{
 		Conn:     conn,
-		RabbitMQ: rabbitmq.NewRabbitMQ(conn.RMQ),
+		RabbitMQ: rabbitmq.NewManaged(conn.RMQ, rabbitMQOptions(cfg.RabbitMQ)...),
 		Kafka:    kafka.New(conn.KFK),
+		NATS:     nats.New(conn.NC),
 	}
 
 	if !createNew {
@@ -77,6 +81,14 @@ func New(cfg *config.Config, createNewInstance ...bool) (*Data, func(name ...str
 	return d, cleanup, nil
 }
 
+// rabbitMQOptions returns the RabbitMQ service options of conf
+func rabbitMQOptions(conf *config.RabbitMQ) []rabbitmq.Option {
+	if conf == nil {
+		return nil
+	}
+	return []rabbitmq.Option{rabbitmq.WithCircuitBreaker(breaker.New("rabbitmq", conf.Breaker))}
+}
+
 // GetTx retrieves transaction from context
 func GetTx(ctx context.Context) (*sql.Tx, error) {
 	tx, ok := ctx.Value(ContextKeyTransaction).(*sql.Tx)
@@ -157,8 +169,16 @@ func (d *Data) DBRead() (*sql.DB, error) {
 	return nil, errors.New("no database connection available")
 }
 
-// GetRedis get redis
-func (d *Data) GetRedis() *redis.Client {
+// DBRouter returns a handle routing writes to the master and reads to slaves
+func (d *Data) DBRouter() (*connection.DBRouter, error) {
+	if d.Conn != nil {
+		return d.Conn.DBRouter()
+	}
+	return nil, errors.New("no database connection available")
+}
+
+// GetRedis get redis, a standalone, sentinel or cluster client
+func (d *Data) GetRedis() redis.UniversalClient {
 	return d.Conn.RC
 }
 
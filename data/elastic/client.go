@@ -1,6 +1,7 @@
 package elastic
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -19,15 +20,21 @@ type Client struct {
 }
 
 // NewClient new Elasticsearch client
-func NewClient(addresses []string, username, password string) (*Client, error) {
+func NewClient(addresses []string, username, password string, opts ...Option) (*Client, error) {
 	if len(addresses) == 0 {
 		return &Client{client: nil}, nil
 	}
 
+	o := &clientOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	cfg := elasticsearch.Config{
 		Addresses: addresses,
 		Username:  username,
 		Password:  password,
+		Transport: o.transport,
 	}
 
 	es, err := elasticsearch.NewClient(cfg)
@@ -53,13 +60,17 @@ func (c *Client) Search(ctx context.Context, indexName, query string) (*esapi.Re
 	)
 	if err != nil {
 		log.Printf("Elasticsearch search error: %s", err)
-		return nil, err
+		return nil, unavailableError("search", err)
 	}
 
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(res.Body)
 
+	if res.IsError() {
+		return nil, statusError("search", res)
+	}
+
 	var sr esapi.Response
 	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
 		return nil, fmt.Errorf("elasticsearch parsing error: %s", err)
@@ -68,8 +79,54 @@ func (c *Client) Search(ctx context.Context, indexName, query string) (*esapi.Re
 	return &sr, nil
 }
 
+// writeOptions holds the optional parameters of index and update requests
+type writeOptions struct {
+	pipeline      string
+	ifSeqNo       *int
+	ifPrimaryTerm *int
+	version       *int
+	versionType   string
+}
+
+// IndexOption configures an index or update request
+type IndexOption func(*writeOptions)
+
+// WithPipeline runs the document through the given ingest pipeline, index only
+func WithPipeline(pipeline string) IndexOption {
+	return func(o *writeOptions) {
+		o.pipeline = pipeline
+	}
+}
+
+// WithIfSeqNo only writes if the document still has the given sequence number
+// and primary term, as returned by a previous read or write
+func WithIfSeqNo(seqNo, primaryTerm int) IndexOption {
+	return func(o *writeOptions) {
+		o.ifSeqNo = &seqNo
+		o.ifPrimaryTerm = &primaryTerm
+	}
+}
+
+// WithVersion uses external versioning, e.g. versionType "external" or
+// "external_gte", index only
+func WithVersion(version int, versionType string) IndexOption {
+	return func(o *writeOptions) {
+		o.version = &version
+		o.versionType = versionType
+	}
+}
+
+// newWriteOptions applies the options
+func newWriteOptions(opts []IndexOption) *writeOptions {
+	o := &writeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // IndexDocument index document to Elasticsearch
-func (c *Client) IndexDocument(ctx context.Context, indexName string, documentID string, document any) error {
+func (c *Client) IndexDocument(ctx context.Context, indexName string, documentID string, document any, opts ...IndexOption) error {
 	if c == nil || c.client == nil {
 		return errors.New("elasticsearch client is nil, cannot index documents")
 	}
@@ -80,16 +137,22 @@ func (c *Client) IndexDocument(ctx context.Context, indexName string, documentID
 		return fmt.Errorf("error encoding document: %s", err)
 	}
 
+	o := newWriteOptions(opts)
 	req := esapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: documentID,
-		Body:       strings.NewReader(b.String()),
-		Refresh:    "true",
+		Index:         indexName,
+		DocumentID:    documentID,
+		Body:          strings.NewReader(b.String()),
+		Refresh:       "true",
+		Pipeline:      o.pipeline,
+		IfSeqNo:       o.ifSeqNo,
+		IfPrimaryTerm: o.ifPrimaryTerm,
+		Version:       o.version,
+		VersionType:   o.versionType,
 	}
 
 	res, err := req.Do(ctx, c.client)
 	if err != nil {
-		return fmt.Errorf("elasticsearch indexing error: %s", err)
+		return unavailableError("index", err)
 	}
 
 	defer func(Body io.ReadCloser) {
@@ -103,7 +166,115 @@ func (c *Client) IndexDocument(ctx context.Context, indexName string, documentID
 		} else {
 			log.Printf("Elasticsearch indexing error: %s: %s", res.Status(), respBody["error"])
 		}
-		return fmt.Errorf("elasticsearch indexing error: %s", res.Status())
+		return statusError("index", res)
+	}
+
+	return nil
+}
+
+// BulkIndex indexes documents in a single bulk request, letting Elasticsearch assign the ids
+//
+// Only WithPipeline applies to bulk requests. When some documents fail the
+// error wraps ErrDocumentRejected and reports how many were rejected.
+func (c *Client) BulkIndex(ctx context.Context, indexName string, documents []any, opts ...IndexOption) error {
+	if c == nil || c.client == nil {
+		return errors.New("elasticsearch client is nil, cannot index documents")
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, doc := range documents {
+		if err := enc.Encode(map[string]any{"index": map[string]any{"_index": indexName}}); err != nil {
+			return fmt.Errorf("error encoding bulk action: %s", err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("error encoding document: %s", err)
+		}
+	}
+
+	o := newWriteOptions(opts)
+	req := esapi.BulkRequest{
+		Index:    indexName,
+		Body:     &b,
+		Pipeline: o.pipeline,
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return unavailableError("bulk", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return statusError("bulk", res)
+	}
+
+	var body struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return fmt.Errorf("error parsing bulk response: %s", err)
+	}
+	if body.Errors {
+		failed := 0
+		for _, item := range body.Items {
+			for _, r := range item {
+				if r.Status >= 300 {
+					failed++
+				}
+			}
+		}
+		return fmt.Errorf("%w: bulk: %d of %d documents failed", ErrDocumentRejected, failed, len(documents))
+	}
+
+	return nil
+}
+
+// UpdateDocument partially updates a document in Elasticsearch
+//
+// Use WithIfSeqNo for optimistic concurrency, ErrVersionConflict is returned
+// when the document was changed in the meantime.
+func (c *Client) UpdateDocument(ctx context.Context, indexName, documentID string, doc any, opts ...IndexOption) error {
+	if c == nil || c.client == nil {
+		return errors.New("elasticsearch client is nil, cannot update documents")
+	}
+
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	if err := enc.Encode(map[string]any{"doc": doc}); err != nil {
+		return fmt.Errorf("error encoding document: %s", err)
+	}
+
+	o := newWriteOptions(opts)
+	req := esapi.UpdateRequest{
+		Index:         indexName,
+		DocumentID:    documentID,
+		Body:          strings.NewReader(b.String()),
+		Refresh:       "true",
+		IfSeqNo:       o.ifSeqNo,
+		IfPrimaryTerm: o.ifPrimaryTerm,
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return unavailableError("update", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return statusError("update", res)
 	}
 
 	return nil
@@ -124,7 +295,7 @@ func (c *Client) DeleteDocument(ctx context.Context, indexName, documentID strin
 	res, err := req.Do(ctx, c.client)
 	if err != nil {
 		log.Printf("Error deleting document: %s", err)
-		return err
+		return unavailableError("delete", err)
 	}
 
 	defer func(Body io.ReadCloser) {
@@ -138,12 +309,32 @@ func (c *Client) DeleteDocument(ctx context.Context, indexName, documentID strin
 		} else {
 			log.Printf("Elasticsearch deletion error: %s: %s", res.Status(), respBody["error"])
 		}
-		return fmt.Errorf("elasticsearch deletion error: %s", res.Status())
+		return statusError("delete", res)
 	}
 
 	return nil
 }
 
+// Healthcheck pings the cluster
+func (c *Client) Healthcheck(ctx context.Context) error {
+	if c == nil || c.client == nil {
+		return errors.New("elasticsearch client is nil, cannot ping")
+	}
+
+	res, err := c.client.Ping(c.client.Ping.WithContext(ctx))
+	if err != nil {
+		return unavailableError("ping", err)
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return statusError("ping", res)
+	}
+	return nil
+}
+
 // GetClient get Elasticsearch client
 func (c *Client) GetClient() *elasticsearch.Client {
 	return c.client
@@ -0,0 +1,156 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRecordingServer starts a fake Elasticsearch node recording the last request
+func newRecordingServer(t *testing.T, status int) (*httptest.Server, *http.Request) {
+	t.Helper()
+
+	last := &http.Request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = *r.Clone(context.Background())
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"result":"created"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, last
+}
+
+func TestIndexDocument_WithPipeline(t *testing.T) {
+	srv, last := newRecordingServer(t, http.StatusCreated)
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	doc := map[string]any{"message": "hello"}
+	if err := client.IndexDocument(context.Background(), "logs", "1", doc, WithPipeline("logs-enrich")); err != nil {
+		t.Fatalf("unexpected index error: %v", err)
+	}
+
+	if got := last.URL.Query().Get("pipeline"); got != "logs-enrich" {
+		t.Errorf("expected pipeline logs-enrich, got %q", got)
+	}
+
+	if err := client.IndexDocument(context.Background(), "logs", "2", doc); err != nil {
+		t.Fatalf("unexpected index error: %v", err)
+	}
+	if last.URL.Query().Has("pipeline") {
+		t.Error("pipeline must not be sent when not configured")
+	}
+}
+
+func TestIndexDocument_VersionConflict(t *testing.T) {
+	srv, last := newRecordingServer(t, http.StatusConflict)
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	doc := map[string]any{"state": "approved"}
+
+	err = client.IndexDocument(ctx, "orders", "42", doc, WithIfSeqNo(7, 1))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if q := last.URL.Query(); q.Get("if_seq_no") != "7" || q.Get("if_primary_term") != "1" {
+		t.Errorf("expected if_seq_no=7 and if_primary_term=1, got %q", last.URL.RawQuery)
+	}
+
+	err = client.UpdateDocument(ctx, "orders", "42", doc, WithIfSeqNo(8, 1))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict on update, got %v", err)
+	}
+	if q := last.URL.Query(); q.Get("if_seq_no") != "8" {
+		t.Errorf("expected if_seq_no=8 on update, got %q", last.URL.RawQuery)
+	}
+
+	err = client.IndexDocument(ctx, "orders", "42", doc, WithVersion(3, "external"))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict with external version, got %v", err)
+	}
+	if q := last.URL.Query(); q.Get("version") != "3" || q.Get("version_type") != "external" {
+		t.Errorf("expected version=3 and version_type=external, got %q", last.URL.RawQuery)
+	}
+}
+
+func TestBulkIndex(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400}}]}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	docs := []any{map[string]any{"message": "a"}, map[string]any{"message": "b"}}
+	err = client.BulkIndex(context.Background(), "logs", docs)
+	if !errors.Is(err, ErrDocumentRejected) || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("expected partial rejection error, got %v", err)
+	}
+	if len(lines) != 4 || !strings.Contains(lines[0], `"_index":"logs"`) || !strings.Contains(lines[3], `"b"`) {
+		t.Errorf("unexpected bulk body: %q", lines)
+	}
+}
+
+func TestHealthcheck(t *testing.T) {
+	srv, last := newRecordingServer(t, http.StatusOK)
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if err := client.Healthcheck(context.Background()); err != nil {
+		t.Fatalf("unexpected healthcheck error: %v", err)
+	}
+	if last.Method != http.MethodHead || last.URL.Path != "/" {
+		t.Errorf("expected HEAD /, got %s %s", last.Method, last.URL.Path)
+	}
+
+	srv, _ = newRecordingServer(t, http.StatusServiceUnavailable)
+	client, _ = NewClient([]string{srv.URL}, "", "")
+	if err := client.Healthcheck(context.Background()); !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("expected ErrBackendUnavailable, got %v", err)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	srv, _ := newRecordingServer(t, http.StatusServiceUnavailable)
+
+	b := breaker.New("elasticsearch", &config.CircuitBreaker{Enabled: true, MinRequests: 1, FailureRatio: 1})
+	client, err := NewClient([]string{srv.URL}, "", "", WithCircuitBreaker(b))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// The client retries the 503, rejected once the breaker opened
+	if err := client.Healthcheck(context.Background()); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("expected breaker.ErrOpen, got %v", err)
+	}
+	if err := client.Healthcheck(context.Background()); !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("expected breaker.ErrOpen, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package elastic
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+var (
+	// ErrBackendUnavailable is returned when Elasticsearch cannot be reached or is overloaded
+	ErrBackendUnavailable = errors.New("elasticsearch backend unavailable")
+	// ErrDocumentRejected is returned when Elasticsearch refuses the request or document
+	ErrDocumentRejected = errors.New("elasticsearch document rejected")
+	// ErrVersionConflict is returned when a conditional write lost against a concurrent update
+	ErrVersionConflict = errors.New("elasticsearch version conflict")
+)
+
+// unavailableError wraps a transport error as ErrBackendUnavailable, the
+// transport error is kept so e.g. breaker.ErrOpen can be told apart
+func unavailableError(op string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrBackendUnavailable, op, err)
+}
+
+// statusError maps an error response to ErrVersionConflict, ErrBackendUnavailable or ErrDocumentRejected
+func statusError(op string, res *esapi.Response) error {
+	if res.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %s: %s", ErrVersionConflict, op, res.Status())
+	}
+	if res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s: %s", ErrBackendUnavailable, op, res.Status())
+	}
+	return fmt.Errorf("%w: %s: %s", ErrDocumentRejected, op, res.Status())
+}
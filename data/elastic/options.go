@@ -0,0 +1,54 @@
+package elastic
+
+import (
+	"net/http"
+	"strings"
+
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/metrics"
+)
+
+// clientOptions holds the optional client settings
+type clientOptions struct {
+	transport http.RoundTripper
+}
+
+// Option configures the client
+type Option func(*clientOptions)
+
+// WithTransport sets the HTTP transport used by the client
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = rt
+	}
+}
+
+// WithMetrics records Prometheus request metrics, see metrics.Collectors
+func WithMetrics() Option {
+	return func(o *clientOptions) {
+		o.transport = metrics.NewTransport("elasticsearch", o.transport, Operation)
+	}
+}
+
+// WithCircuitBreaker fails requests fast with breaker.ErrOpen once the
+// backend keeps failing, a nil breaker is ignored
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(o *clientOptions) {
+		if b != nil {
+			o.transport = b.Transport(o.transport)
+		}
+	}
+}
+
+// Operation returns the API endpoint of a request, e.g. "_search" or "_doc"
+func Operation(r *http.Request) string {
+	for _, segment := range strings.Split(r.URL.Path, "/") {
+		if strings.HasPrefix(segment, "_") {
+			return segment
+		}
+	}
+	if r.URL.Path == "" || r.URL.Path == "/" {
+		return "info"
+	}
+	return "index"
+}
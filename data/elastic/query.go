@@ -0,0 +1,225 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// Query is a clause of the Elasticsearch query DSL
+type Query interface {
+	Source() map[string]any
+}
+
+// rawQuery is a clause already in its final form
+type rawQuery map[string]any
+
+// Source implements Query
+func (q rawQuery) Source() map[string]any { return q }
+
+// Term matches documents whose field contains the exact value
+func Term(field string, value any) Query {
+	return rawQuery{"term": map[string]any{field: value}}
+}
+
+// Terms matches documents whose field contains any of the exact values
+func Terms(field string, values ...any) Query {
+	return rawQuery{"terms": map[string]any{field: values}}
+}
+
+// Match runs a full-text match query on the field
+func Match(field string, value any) Query {
+	return rawQuery{"match": map[string]any{field: value}}
+}
+
+// MatchAll matches every document
+func MatchAll() Query {
+	return rawQuery{"match_all": map[string]any{}}
+}
+
+// RangeQuery matches documents whose field is within the bounds
+type RangeQuery struct {
+	field  string
+	params map[string]any
+}
+
+// Range starts a range query on the field, e.g. Range("@timestamp").Gte("now-1h")
+func Range(field string) *RangeQuery {
+	return &RangeQuery{field: field, params: map[string]any{}}
+}
+
+// Gt sets the exclusive lower bound
+func (q *RangeQuery) Gt(v any) *RangeQuery { q.params["gt"] = v; return q }
+
+// Gte sets the inclusive lower bound
+func (q *RangeQuery) Gte(v any) *RangeQuery { q.params["gte"] = v; return q }
+
+// Lt sets the exclusive upper bound
+func (q *RangeQuery) Lt(v any) *RangeQuery { q.params["lt"] = v; return q }
+
+// Lte sets the inclusive upper bound
+func (q *RangeQuery) Lte(v any) *RangeQuery { q.params["lte"] = v; return q }
+
+// Format sets the date format of the bounds
+func (q *RangeQuery) Format(f string) *RangeQuery { q.params["format"] = f; return q }
+
+// Source implements Query
+func (q *RangeQuery) Source() map[string]any {
+	return map[string]any{"range": map[string]any{q.field: q.params}}
+}
+
+// BoolQuery combines clauses with must, should, filter and must_not
+type BoolQuery struct {
+	must    []Query
+	should  []Query
+	filter  []Query
+	mustNot []Query
+}
+
+// Bool starts a bool query
+func Bool() *BoolQuery {
+	return &BoolQuery{}
+}
+
+// Must adds clauses that must match and contribute to the score
+func (q *BoolQuery) Must(clauses ...Query) *BoolQuery { q.must = append(q.must, clauses...); return q }
+
+// Should adds clauses of which at least one should match
+func (q *BoolQuery) Should(clauses ...Query) *BoolQuery {
+	q.should = append(q.should, clauses...)
+	return q
+}
+
+// Filter adds clauses that must match without scoring
+func (q *BoolQuery) Filter(clauses ...Query) *BoolQuery {
+	q.filter = append(q.filter, clauses...)
+	return q
+}
+
+// MustNot adds clauses that must not match
+func (q *BoolQuery) MustNot(clauses ...Query) *BoolQuery {
+	q.mustNot = append(q.mustNot, clauses...)
+	return q
+}
+
+// empty reports whether the bool query has no clauses
+func (q *BoolQuery) empty() bool {
+	return len(q.must)+len(q.should)+len(q.filter)+len(q.mustNot) == 0
+}
+
+// Source implements Query
+func (q *BoolQuery) Source() map[string]any {
+	body := map[string]any{}
+	for key, clauses := range map[string][]Query{
+		"must":     q.must,
+		"should":   q.should,
+		"filter":   q.filter,
+		"must_not": q.mustNot,
+	} {
+		if len(clauses) > 0 {
+			body[key] = sources(clauses)
+		}
+	}
+	return map[string]any{"bool": body}
+}
+
+// sources converts clauses to their DSL form
+func sources(clauses []Query) []map[string]any {
+	out := make([]map[string]any, 0, len(clauses))
+	for _, c := range clauses {
+		out = append(out, c.Source())
+	}
+	return out
+}
+
+// QueryBuilder builds search request bodies for the common log-search cases
+//
+//	body := elastic.NewQueryBuilder().
+//		Must(elastic.Match("message", "timeout")).
+//		Filter(elastic.Term("level", "error"), elastic.Range("@timestamp").Gte("now-1h")).
+//		Sort("@timestamp", "desc").
+//		Size(50).
+//		Build()
+type QueryBuilder struct {
+	query Query
+	bool  *BoolQuery
+	sort  []map[string]any
+	size  *int
+	from  *int
+}
+
+// NewQueryBuilder creates a query builder, without clauses it matches all documents
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{bool: Bool()}
+}
+
+// Query sets the top-level query, taking precedence over Must/Should/Filter clauses
+func (b *QueryBuilder) Query(q Query) *QueryBuilder { b.query = q; return b }
+
+// Must adds clauses to the top-level bool query
+func (b *QueryBuilder) Must(clauses ...Query) *QueryBuilder { b.bool.Must(clauses...); return b }
+
+// Should adds clauses to the top-level bool query
+func (b *QueryBuilder) Should(clauses ...Query) *QueryBuilder { b.bool.Should(clauses...); return b }
+
+// Filter adds clauses to the top-level bool query
+func (b *QueryBuilder) Filter(clauses ...Query) *QueryBuilder { b.bool.Filter(clauses...); return b }
+
+// MustNot adds clauses to the top-level bool query
+func (b *QueryBuilder) MustNot(clauses ...Query) *QueryBuilder { b.bool.MustNot(clauses...); return b }
+
+// Sort adds a sort on field, order is "asc" or "desc"
+func (b *QueryBuilder) Sort(field, order string) *QueryBuilder {
+	b.sort = append(b.sort, map[string]any{field: map[string]any{"order": order}})
+	return b
+}
+
+// Size sets the number of hits to return
+func (b *QueryBuilder) Size(n int) *QueryBuilder { b.size = &n; return b }
+
+// From sets the offset of the first hit
+func (b *QueryBuilder) From(n int) *QueryBuilder { b.from = &n; return b }
+
+// Build returns the search request body
+func (b *QueryBuilder) Build() map[string]any {
+	query := b.query
+	if query == nil {
+		if b.bool.empty() {
+			query = MatchAll()
+		} else {
+			query = b.bool
+		}
+	}
+
+	body := map[string]any{"query": query.Source()}
+	if len(b.sort) > 0 {
+		body["sort"] = b.sort
+	}
+	if b.size != nil {
+		body["size"] = *b.size
+	}
+	if b.from != nil {
+		body["from"] = *b.from
+	}
+	return body
+}
+
+// JSON returns the search request body encoded as JSON
+func (b *QueryBuilder) JSON() (string, error) {
+	data, err := json.Marshal(b.Build())
+	if err != nil {
+		return "", fmt.Errorf("error encoding query: %w", err)
+	}
+	return string(data), nil
+}
+
+// SearchQuery searches with the body built by the query builder
+func (c *Client) SearchQuery(ctx context.Context, indexName string, b *QueryBuilder) (*esapi.Response, error) {
+	query, err := b.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return c.Search(ctx, indexName, query)
+}
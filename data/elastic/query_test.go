@@ -0,0 +1,83 @@
+package elastic
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *QueryBuilder
+		want    string
+	}{
+		{
+			name:    "match all",
+			builder: NewQueryBuilder(),
+			want:    `{"query":{"match_all":{}}}`,
+		},
+		{
+			name:    "single term with paging",
+			builder: NewQueryBuilder().Query(Term("level", "error")).Size(10).From(20),
+			want:    `{"from":20,"query":{"term":{"level":"error"}},"size":10}`,
+		},
+		{
+			name: "log search",
+			builder: NewQueryBuilder().
+				Must(Match("message", "connection timeout")).
+				Filter(Term("level", "error"), Range("@timestamp").Gte("now-1h").Lt("now")).
+				Sort("@timestamp", "desc").
+				Size(50),
+			want: `{
+				"query": {"bool": {
+					"must": [{"match": {"message": "connection timeout"}}],
+					"filter": [
+						{"term": {"level": "error"}},
+						{"range": {"@timestamp": {"gte": "now-1h", "lt": "now"}}}
+					]
+				}},
+				"sort": [{"@timestamp": {"order": "desc"}}],
+				"size": 50
+			}`,
+		},
+		{
+			name: "nested bool",
+			builder: NewQueryBuilder().
+				Should(Term("service", "api"), Term("service", "worker")).
+				MustNot(Bool().Filter(Term("level", "debug"))),
+			want: `{"query": {"bool": {
+				"should": [{"term": {"service": "api"}}, {"term": {"service": "worker"}}],
+				"must_not": [{"bool": {"filter": [{"term": {"level": "debug"}}]}}]
+			}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.builder.JSON()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertJSONEqual(t, tc.want, got)
+		})
+	}
+}
+
+// assertJSONEqual compares two JSON documents ignoring formatting and key order
+func assertJSONEqual(t *testing.T, want, got string) {
+	t.Helper()
+
+	var w, g any
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("invalid expected JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(got), &g); err != nil {
+		t.Fatalf("invalid generated JSON: %v", err)
+	}
+
+	wb, _ := json.Marshal(w)
+	gb, _ := json.Marshal(g)
+	if string(wb) != string(gb) {
+		t.Errorf("expected %s, got %s", wb, gb)
+	}
+}
@@ -0,0 +1,171 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// ScrollCursor iterates over a scrolled search result
+//
+// Scroll contexts hold memory on the cluster until they are cleared, callers
+// must always `defer cursor.Close(ctx)`. The cursor also clears the scroll on
+// its own once the result set is fully consumed.
+type ScrollCursor struct {
+	client    *Client
+	keepAlive time.Duration
+	scrollID  string
+	scrollIDs []string // every distinct scroll ID returned, they may rotate
+	pending   []json.RawMessage
+	done      bool
+	closed    bool
+}
+
+// scrollResponse is the subset of a search/scroll response we need
+type scrollResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []json.RawMessage `json:"hits"`
+	} `json:"hits"`
+}
+
+// Scroll starts a scrolled search and returns a cursor over its batches
+func (c *Client) Scroll(ctx context.Context, indexName, query string, size int, keepAlive time.Duration) (*ScrollCursor, error) {
+	if c == nil || c.client == nil {
+		return nil, errors.New("elasticsearch client is nil, cannot perform scroll")
+	}
+
+	res, err := c.client.Search(
+		c.client.Search.WithContext(ctx),
+		c.client.Search.WithIndex(indexName),
+		c.client.Search.WithBody(strings.NewReader(query)),
+		c.client.Search.WithSize(size),
+		c.client.Search.WithScroll(keepAlive),
+	)
+	if err != nil {
+		return nil, unavailableError("scroll", err)
+	}
+
+	sr, err := decodeScrollResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &ScrollCursor{client: c, keepAlive: keepAlive, pending: sr.Hits.Hits}
+	cursor.setScrollID(sr.ScrollID)
+	return cursor, nil
+}
+
+// Next returns the next batch of hits, io.EOF is returned once all hits
+// have been consumed, at which point the scroll is already cleared
+func (s *ScrollCursor) Next(ctx context.Context) ([]json.RawMessage, error) {
+	if s.closed || s.done {
+		return nil, io.EOF
+	}
+
+	if s.pending != nil {
+		hits := s.pending
+		s.pending = nil
+		if len(hits) > 0 {
+			return hits, nil
+		}
+		return nil, s.finish(ctx)
+	}
+
+	res, err := s.client.client.Scroll(
+		s.client.client.Scroll.WithContext(ctx),
+		s.client.client.Scroll.WithScrollID(s.scrollID),
+		s.client.client.Scroll.WithScroll(s.keepAlive),
+	)
+	if err != nil {
+		return nil, unavailableError("scroll", err)
+	}
+
+	sr, err := decodeScrollResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	s.setScrollID(sr.ScrollID)
+
+	if len(sr.Hits.Hits) == 0 {
+		return nil, s.finish(ctx)
+	}
+	return sr.Hits.Hits, nil
+}
+
+// Close clears the scroll context on the cluster, it is safe to call more than once
+func (s *ScrollCursor) Close(ctx context.Context) error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if len(s.scrollIDs) == 0 {
+		return nil
+	}
+
+	res, err := s.client.client.ClearScroll(
+		s.client.client.ClearScroll.WithContext(ctx),
+		s.client.client.ClearScroll.WithScrollID(s.scrollIDs...),
+	)
+	if err != nil {
+		return unavailableError("clear scroll", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	// 404 means the scroll already expired, which is what we want anyway
+	if res.IsError() && res.StatusCode != 404 {
+		return statusError("clear scroll", res)
+	}
+
+	return nil
+}
+
+// finish marks the cursor as consumed and clears the scroll
+func (s *ScrollCursor) finish(ctx context.Context) error {
+	s.done = true
+	if err := s.Close(ctx); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// setScrollID records the current scroll ID, keeping track of rotated ones
+func (s *ScrollCursor) setScrollID(id string) {
+	if id == "" {
+		return
+	}
+	s.scrollID = id
+	for _, existing := range s.scrollIDs {
+		if existing == id {
+			return
+		}
+	}
+	s.scrollIDs = append(s.scrollIDs, id)
+}
+
+// decodeScrollResponse decodes and closes a search/scroll response
+func decodeScrollResponse(res *esapi.Response) (*scrollResponse, error) {
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return nil, statusError("scroll", res)
+	}
+
+	var sr scrollResponse
+	if err := json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		return nil, fmt.Errorf("elasticsearch parsing error: %s", err)
+	}
+	return &sr, nil
+}
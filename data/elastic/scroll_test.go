@@ -0,0 +1,152 @@
+package elastic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer starts a fake Elasticsearch node serving batches via rotating scroll IDs
+func newTestServer(t *testing.T, batches [][]string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		cleared []string
+		page    int
+	)
+
+	writeBatch := func(w http.ResponseWriter) {
+		var hits []map[string]any
+		if page < len(batches) {
+			for _, id := range batches[page] {
+				hits = append(hits, map[string]any{"_id": id})
+			}
+		}
+		page++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"_scroll_id": fmt.Sprintf("scroll-%d", page),
+			"hits":       map[string]any{"hits": hits},
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/_search/scroll"):
+			ids := strings.TrimPrefix(r.URL.Path, "/_search/scroll")
+			ids = strings.Trim(ids, "/")
+			if ids == "" {
+				var body struct {
+					ScrollID []string `json:"scroll_id"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				cleared = append(cleared, body.ScrollID...)
+			} else {
+				cleared = append(cleared, strings.Split(ids, ",")...)
+			}
+			_, _ = io.WriteString(w, `{"succeeded":true}`)
+		case strings.HasPrefix(r.URL.Path, "/_search/scroll"):
+			writeBatch(w)
+		case strings.HasSuffix(r.URL.Path, "/_search"):
+			writeBatch(w)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &cleared
+}
+
+func TestScrollCursor_ClearsAfterConsumption(t *testing.T) {
+	srv, cleared := newTestServer(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}})
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	cursor, err := client.Scroll(ctx, "logs", `{"query":{"match_all":{}}}`, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to start scroll: %v", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var total int
+	for {
+		hits, err := cursor.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected scroll error: %v", err)
+		}
+		total += len(hits)
+	}
+
+	if total != 5 {
+		t.Errorf("expected 5 hits, got %d", total)
+	}
+
+	// Every rotated scroll ID must have been cleared exactly once
+	want := map[string]bool{"scroll-1": true, "scroll-2": true, "scroll-3": true, "scroll-4": true}
+	if len(*cleared) != len(want) {
+		t.Fatalf("expected %d cleared scroll IDs, got %v", len(want), *cleared)
+	}
+	for _, id := range *cleared {
+		if !want[id] {
+			t.Errorf("unexpected cleared scroll ID %q", id)
+		}
+	}
+
+	// Close after consumption must not send another request
+	if err := cursor.Close(ctx); err != nil {
+		t.Errorf("unexpected close error: %v", err)
+	}
+	if len(*cleared) != len(want) {
+		t.Errorf("expected no additional clear requests, got %v", *cleared)
+	}
+}
+
+func TestScrollCursor_CloseEarly(t *testing.T) {
+	srv, cleared := newTestServer(t, [][]string{{"1", "2"}, {"3"}})
+
+	client, err := NewClient([]string{srv.URL}, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx := context.Background()
+	cursor, err := client.Scroll(ctx, "logs", `{}`, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to start scroll: %v", err)
+	}
+
+	if _, err := cursor.Next(ctx); err != nil {
+		t.Fatalf("unexpected scroll error: %v", err)
+	}
+	if err := cursor.Close(ctx); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if len(*cleared) != 1 || (*cleared)[0] != "scroll-1" {
+		t.Errorf("expected scroll-1 to be cleared, got %v", *cleared)
+	}
+	if _, err := cursor.Next(ctx); err != io.EOF {
+		t.Errorf("expected io.EOF after close, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthStatus is the status of a backend or of a whole report
+type HealthStatus string
+
+const (
+	HealthUp   HealthStatus = "up"
+	HealthDown HealthStatus = "down"
+)
+
+// BackendHealth is the result of the health check of a backend
+type BackendHealth struct {
+	Status  HealthStatus  `json:"status"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// HealthReport is the health of all connections, up only when every backend
+// is up. It marshals to JSON for readiness endpoints, see StatusCode.
+type HealthReport struct {
+	Status   HealthStatus             `json:"status"`
+	Backends map[string]BackendHealth `json:"backends"`
+}
+
+// Healthy reports whether all backends are up
+func (r *HealthReport) Healthy() bool {
+	return r.Status == HealthUp
+}
+
+// StatusCode returns the HTTP status of a readiness probe reporting r
+func (r *HealthReport) StatusCode() int {
+	if r.Healthy() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// Healthcheck checks all connections concurrently, ctx bounds the checks
+func (d *Data) Healthcheck(ctx context.Context) *HealthReport {
+	if d.Conn == nil {
+		return &HealthReport{Status: HealthDown, Backends: map[string]BackendHealth{}}
+	}
+	return runHealthchecks(ctx, d.Conn.Healthchecks())
+}
+
+// runHealthchecks runs checks concurrently and reports their results
+func runHealthchecks(ctx context.Context, checks map[string]func(ctx context.Context) error) *HealthReport {
+	report := &HealthReport{
+		Status:   HealthUp,
+		Backends: make(map[string]BackendHealth, len(checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(ctx context.Context) error) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check(ctx)
+			health := BackendHealth{Status: HealthUp, Latency: time.Since(start)}
+			if err != nil {
+				health.Status = HealthDown
+				health.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Backends[name] = health
+			if err != nil {
+				report.Status = HealthDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
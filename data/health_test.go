@@ -0,0 +1,30 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRunHealthchecks(t *testing.T) {
+	report := runHealthchecks(context.Background(), map[string]func(ctx context.Context) error{
+		"database": func(context.Context) error { return nil },
+		"redis":    func(context.Context) error { return errors.New("connection refused") },
+	})
+
+	if report.Healthy() || report.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("expected report to be down, got %s", report.Status)
+	}
+	if db := report.Backends["database"]; db.Status != HealthUp || db.Error != "" {
+		t.Errorf("unexpected database health %+v", db)
+	}
+	if rc := report.Backends["redis"]; rc.Status != HealthDown || rc.Error != "connection refused" {
+		t.Errorf("unexpected redis health %+v", rc)
+	}
+
+	report = runHealthchecks(context.Background(), nil)
+	if !report.Healthy() || report.StatusCode() != http.StatusOK {
+		t.Errorf("expected report without backends to be up, got %s", report.Status)
+	}
+}
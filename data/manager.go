@@ -0,0 +1,208 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"ncobase/common/data/config"
+	"ncobase/common/data/connection"
+	"ncobase/common/data/elastic"
+	"ncobase/common/data/meili"
+	"ncobase/common/data/rabbitmq"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotStarted is returned by the ConnectionManager before Start succeeded
+var ErrNotStarted = errors.New("connection manager is not started")
+
+// ConnectionManager owns the connections of a config for the lifetime of a
+// service. Start opens them in dependency order, data stores first, then
+// search engines and brokers, and Stop closes them in reverse order:
+//
+//	m := data.NewConnectionManager(conf.Data)
+//	if err := m.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer m.Stop(context.Background())
+//
+// Getters return nil for connections which are not configured, or while the
+// manager is stopped.
+type ConnectionManager struct {
+	conf *config.Config
+	mu   sync.RWMutex
+	data *Data
+}
+
+// NewConnectionManager creates a new connection manager, connections are
+// opened by Start
+func NewConnectionManager(conf *config.Config) *ConnectionManager {
+	return &ConnectionManager{conf: conf}
+}
+
+// Start opens the configured connections. Connections opened before a
+// failure are closed again, so Start may be retried. When ctx is done first
+// Start returns its error and the connections are closed once they open.
+func (m *ConnectionManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data != nil {
+		return errors.New("connection manager is already started")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	type result struct {
+		d   *Data
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		d, _, err := New(m.conf, true)
+		done <- result{d: d, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return fmt.Errorf("failed to start connections: %w", r.err)
+		}
+		m.data = r.d
+		return nil
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.d != nil {
+				r.d.Close()
+			}
+		}()
+		return fmt.Errorf("failed to start connections: %w", ctx.Err())
+	}
+}
+
+// Stop closes the connections, it returns once they are closed or ctx is
+// done, whichever comes first. Stopping a stopped manager does nothing.
+func (m *ConnectionManager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	d := m.data
+	m.data = nil
+	m.mu.Unlock()
+
+	if d == nil {
+		return nil
+	}
+
+	done := make(chan []error, 1)
+	go func() {
+		done <- d.Close()
+	}()
+
+	select {
+	case errs := <-done:
+		return errors.Join(errs...)
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop connections: %w", ctx.Err())
+	}
+}
+
+// Data returns the data layer of the started manager
+func (m *ConnectionManager) Data() (*Data, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.data == nil {
+		return nil, ErrNotStarted
+	}
+	return m.data, nil
+}
+
+// conn returns the connections of the started manager, nil when stopped
+func (m *ConnectionManager) conn() *connection.Connections {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.data == nil {
+		return nil
+	}
+	return m.data.Conn
+}
+
+// DB returns the master database connection for write operations
+func (m *ConnectionManager) DB() *sql.DB {
+	if c := m.conn(); c != nil {
+		return c.DB()
+	}
+	return nil
+}
+
+// DBRead returns a slave database connection for read operations
+func (m *ConnectionManager) DBRead() (*sql.DB, error) {
+	if c := m.conn(); c != nil {
+		return c.DBRead()
+	}
+	return nil, ErrNotStarted
+}
+
+// GetDBManager get database manager
+func (m *ConnectionManager) GetDBManager() *connection.DBManager {
+	if c := m.conn(); c != nil {
+		return c.DBM
+	}
+	return nil
+}
+
+// GetRedis get redis, a standalone, sentinel or cluster client
+func (m *ConnectionManager) GetRedis() redis.UniversalClient {
+	if c := m.conn(); c != nil {
+		return c.RC
+	}
+	return nil
+}
+
+// GetMongoManager get mongo manager
+func (m *ConnectionManager) GetMongoManager() *connection.MongoManager {
+	if c := m.conn(); c != nil {
+		return c.MGM
+	}
+	return nil
+}
+
+// GetElasticsearch get elasticsearch
+func (m *ConnectionManager) GetElasticsearch() *elastic.Client {
+	if c := m.conn(); c != nil {
+		return c.ES
+	}
+	return nil
+}
+
+// GetMeilisearch get meilisearch
+func (m *ConnectionManager) GetMeilisearch() *meili.Client {
+	if c := m.conn(); c != nil {
+		return c.MS
+	}
+	return nil
+}
+
+// GetRabbitMQ get the RabbitMQ service
+func (m *ConnectionManager) GetRabbitMQ() *rabbitmq.RabbitMQ {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// The service is created without a connection too
+	if m.data == nil || m.data.Conn.RMQ == nil {
+		return nil
+	}
+	return m.data.RabbitMQ
+}
+
+// Healthcheck checks all connections concurrently, a stopped manager is down
+func (m *ConnectionManager) Healthcheck(ctx context.Context) *HealthReport {
+	d, err := m.Data()
+	if err != nil {
+		return &HealthReport{Status: HealthDown, Backends: map[string]BackendHealth{}}
+	}
+	return d.Healthcheck(ctx)
+}
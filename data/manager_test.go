@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"ncobase/common/data/config"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConnectionManagerLifecycle(t *testing.T) {
+	m := NewConnectionManager(&config.Config{
+		Database: &config.Database{
+			Master: &config.DBNode{Driver: "sqlite3", Source: filepath.Join(t.TempDir(), "app.db")},
+		},
+	})
+	ctx := context.Background()
+
+	if _, err := m.Data(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("expected ErrNotStarted, got %v", err)
+	}
+	if m.DB() != nil || m.GetRedis() != nil {
+		t.Error("expected no connections before start")
+	}
+
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("unexpected start error: %v", err)
+	}
+	if err := m.Start(ctx); err == nil {
+		t.Error("expected error for second start")
+	}
+	if m.DB() == nil || m.GetDBManager() == nil {
+		t.Fatal("expected database connection")
+	}
+	if err := m.DB().PingContext(ctx); err != nil {
+		t.Errorf("unexpected ping error: %v", err)
+	}
+	if report := m.Healthcheck(ctx); !report.Healthy() || report.Backends["database"].Status != HealthUp {
+		t.Errorf("expected healthy database, got %+v", report)
+	}
+	// Not configured
+	if m.GetRedis() != nil || m.GetMongoManager() != nil || m.GetRabbitMQ() != nil {
+		t.Error("expected no connections which are not configured")
+	}
+
+	if err := m.Stop(ctx); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+	if m.DB() != nil {
+		t.Error("expected no connections after stop")
+	}
+	if err := m.Stop(ctx); err != nil {
+		t.Errorf("unexpected error stopping a stopped manager: %v", err)
+	}
+	if m.Healthcheck(ctx).Healthy() {
+		t.Error("expected a stopped manager to be down")
+	}
+}
+
+func TestConnectionManagerStartFailure(t *testing.T) {
+	m := NewConnectionManager(&config.Config{
+		Database: &config.Database{Master: &config.DBNode{Driver: "oracle", Source: "app"}},
+	})
+	if err := m.Start(context.Background()); err == nil {
+		t.Fatal("expected error for unsupported driver")
+	}
+	if _, err := m.Data(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("expected ErrNotStarted after failed start, got %v", err)
+	}
+}
+
+func TestConnectionManagerStartCancelled(t *testing.T) {
+	// A listener which never answers keeps the redis ping waiting
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	m := NewConnectionManager(&config.Config{
+		Redis: &config.Redis{Addr: ln.Addr().String(), DialTimeout: 2 * time.Second},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := m.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Start to return on cancellation, took %v", elapsed)
+	}
+	if _, err := m.Data(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("expected ErrNotStarted after cancelled start, got %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package meili
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/meilisearch/meilisearch-go"
 )
@@ -13,11 +15,22 @@ type Client struct {
 }
 
 // NewMeilisearch new Meilisearch client
-func NewMeilisearch(host, apiKey string) *Client {
+func NewMeilisearch(host, apiKey string, opts ...Option) *Client {
 	if host == "" {
 		return &Client{client: nil}
 	}
-	ms := meilisearch.New(host, meilisearch.WithAPIKey(apiKey))
+
+	o := &clientOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// meilisearch.New applies options to shared defaults, the client is always
+	// set so a client never reuses the transport of another one
+	ms := meilisearch.New(host,
+		meilisearch.WithAPIKey(apiKey),
+		meilisearch.WithCustomClient(&http.Client{Transport: o.transport}),
+	)
 	return &Client{client: ms}
 }
 
@@ -28,7 +41,7 @@ func (c *Client) Search(index, query string, options *meilisearch.SearchRequest)
 	}
 	resp, err := c.client.Index(index).Search(query, options)
 	if err != nil {
-		return nil, fmt.Errorf("meilisearch search error: %v", err)
+		return nil, wrapError("search", err)
 	}
 	return resp, nil
 }
@@ -40,7 +53,7 @@ func (c *Client) IndexDocuments(index string, document any, primaryKey ...string
 	}
 	_, err := c.client.Index(index).AddDocuments(document, primaryKey...)
 	if err != nil {
-		return fmt.Errorf("meilisearch index document error: %v", err)
+		return wrapError("index document", err)
 	}
 	return nil
 }
@@ -52,7 +65,7 @@ func (c *Client) UpdateDocuments(index string, document any, documentID string)
 	}
 	_, err := c.client.Index(index).UpdateDocuments(document, documentID)
 	if err != nil {
-		return fmt.Errorf("meilisearch update document error: %v", err)
+		return wrapError("update document", err)
 	}
 	return nil
 }
@@ -64,7 +77,53 @@ func (c *Client) DeleteDocuments(index, documentID string) error {
 	}
 	_, err := c.client.Index(index).DeleteDocument(documentID)
 	if err != nil {
-		return fmt.Errorf("meilisearch delete document error: %v", err)
+		return wrapError("delete document", err)
+	}
+	return nil
+}
+
+// DeleteDocumentsByFilter deletes every document matching the filter and
+// returns the task UID of the asynchronous deletion
+//
+// The attributes used in the filter must be filterable and range filters only
+// compare numbers. Log documents indexed by the logger carry the entry time as
+// unix seconds in "time", so retention calls SetFilterableAttributes(index,
+// "time") once, then deletes older entries with e.g. "time < 1700000000".
+func (c *Client) DeleteDocumentsByFilter(index, filter string) (int64, error) {
+	if c == nil || c.client == nil {
+		return 0, errors.New("meilisearch client is nil, cannot delete documents")
+	}
+	task, err := c.client.Index(index).DeleteDocumentsByFilter(filter)
+	if err != nil {
+		return 0, wrapError("delete documents by filter", err)
+	}
+	return task.TaskUID, nil
+}
+
+// SetFilterableAttributes sets the filterable attributes of an index and
+// returns the task UID of the settings update
+func (c *Client) SetFilterableAttributes(index string, attributes ...string) (int64, error) {
+	if c == nil || c.client == nil {
+		return 0, errors.New("meilisearch client is nil, cannot update settings")
+	}
+	task, err := c.client.Index(index).UpdateFilterableAttributes(&attributes)
+	if err != nil {
+		return 0, wrapError("update filterable attributes", err)
+	}
+	return task.TaskUID, nil
+}
+
+// Healthcheck checks the server is available
+func (c *Client) Healthcheck(ctx context.Context) error {
+	if c == nil || c.client == nil {
+		return errors.New("meilisearch client is nil, cannot check health")
+	}
+	health, err := c.client.HealthWithContext(ctx)
+	if err != nil {
+		return wrapError("health", err)
+	}
+	if health.Status != "available" {
+		return fmt.Errorf("%w: health: status %s", ErrBackendUnavailable, health.Status)
 	}
 	return nil
 }
@@ -0,0 +1,99 @@
+package meili
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/config"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// fakeMeili is a minimal Meilisearch server recording settings and deletions
+type fakeMeili struct {
+	mu         sync.Mutex
+	filterable []string
+	lastFilter string
+}
+
+func (f *fakeMeili) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.URL.Path == "/indexes/logs/settings/filterable-attributes":
+		_ = json.Unmarshal(body, &f.filterable)
+	case r.URL.Path == "/indexes/logs/documents/delete":
+		var req struct {
+			Filter string `json:"filter"`
+		}
+		_ = json.Unmarshal(body, &req)
+		f.lastFilter = req.Filter
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message":"not found","code":"not_found","type":"invalid_request"}`)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = io.WriteString(w, `{"taskUid":7,"indexUid":"logs","status":"enqueued","type":"documentDeletion"}`)
+}
+
+func TestDeleteDocumentsByFilter(t *testing.T) {
+	fake := &fakeMeili{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := NewMeilisearch(srv.URL, "")
+
+	if _, err := client.SetFilterableAttributes("logs", "time"); err != nil {
+		t.Fatalf("unexpected settings error: %v", err)
+	}
+	if len(fake.filterable) != 1 || fake.filterable[0] != "time" {
+		t.Errorf("expected time to be filterable, got %v", fake.filterable)
+	}
+
+	taskUID, err := client.DeleteDocumentsByFilter("logs", "time < 1700000000")
+	if err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if taskUID != 7 {
+		t.Errorf("expected task UID 7, got %d", taskUID)
+	}
+	if fake.lastFilter != "time < 1700000000" {
+		t.Errorf("expected the filter to be sent as is, got %q", fake.lastFilter)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	b := breaker.New("meilisearch", &config.CircuitBreaker{Enabled: true, MinRequests: 1, FailureRatio: 1})
+	client := NewMeilisearch(srv.URL, "", WithCircuitBreaker(b))
+
+	if _, err := client.Search("logs", "", &meilisearch.SearchRequest{}); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("expected ErrBackendUnavailable, got %v", err)
+	}
+	requests := hits
+
+	_, err := client.Search("logs", "", &meilisearch.SearchRequest{})
+	if !errors.Is(err, ErrBackendUnavailable) || !errors.Is(err, breaker.ErrOpen) {
+		t.Errorf("expected breaker.ErrOpen, got %v", err)
+	}
+	if hits != requests {
+		t.Errorf("expected no request while open, got %d more", hits-requests)
+	}
+}
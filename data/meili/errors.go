@@ -0,0 +1,47 @@
+package meili
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+var (
+	// ErrBackendUnavailable is returned when Meilisearch cannot be reached or is overloaded
+	ErrBackendUnavailable = errors.New("meilisearch backend unavailable")
+	// ErrDocumentRejected is returned when Meilisearch refuses the request or document
+	ErrDocumentRejected = errors.New("meilisearch document rejected")
+)
+
+// wrapError classifies a client error as ErrBackendUnavailable or ErrDocumentRejected
+//
+// Communication and timeout errors carry no status code, those and 5xx/429
+// responses mean the backend is unavailable, any other API error is a rejection.
+func wrapError(op string, err error) error {
+	var me *meilisearch.Error
+	if errors.As(err, &me) && me.StatusCode != 0 &&
+		me.StatusCode < http.StatusInternalServerError && me.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s: %v", ErrDocumentRejected, op, err)
+	}
+	if me != nil {
+		err = originError{err: me}
+	}
+	return fmt.Errorf("%w: %s: %w", ErrBackendUnavailable, op, err)
+}
+
+// originError unwraps to the origin error of a client error, so e.g.
+// breaker.ErrOpen can be told apart
+type originError struct {
+	err *meilisearch.Error
+}
+
+func (e originError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the origin error
+func (e originError) Unwrap() error {
+	return e.err.OriginError
+}
@@ -0,0 +1,54 @@
+package meili
+
+import (
+	"net/http"
+	"strings"
+
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/metrics"
+)
+
+// clientOptions holds the optional client settings
+type clientOptions struct {
+	transport http.RoundTripper
+}
+
+// Option configures the client
+type Option func(*clientOptions)
+
+// WithTransport sets the HTTP transport used by the client
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = rt
+	}
+}
+
+// WithMetrics records Prometheus request metrics, see metrics.Collectors
+func WithMetrics() Option {
+	return func(o *clientOptions) {
+		o.transport = metrics.NewTransport("meilisearch", o.transport, Operation)
+	}
+}
+
+// WithCircuitBreaker fails requests fast with breaker.ErrOpen once the
+// backend keeps failing, a nil breaker is ignored
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(o *clientOptions) {
+		if b != nil {
+			o.transport = b.Transport(o.transport)
+		}
+	}
+}
+
+// Operation returns the API resource of a request, e.g. "documents" for
+// /indexes/{uid}/documents or "health" for /health
+func Operation(r *http.Request) string {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) >= 3 && segments[0] == "indexes" {
+		return segments[2]
+	}
+	if segments[0] == "" {
+		return "root"
+	}
+	return segments[0]
+}
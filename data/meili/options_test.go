@@ -0,0 +1,40 @@
+package meili
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// countingTransport counts the requests passing through it
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"available"}`))
+	}))
+	defer srv.Close()
+
+	rt := &countingTransport{}
+	custom := NewMeilisearch(srv.URL, "", WithTransport(rt))
+	plain := NewMeilisearch(srv.URL, "")
+
+	if _, err := custom.client.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+	if _, err := plain.client.Health(); err != nil {
+		t.Fatalf("unexpected health error: %v", err)
+	}
+	if got := rt.requests.Load(); got != 1 {
+		t.Errorf("expected only the custom client to use the transport, got %d requests", got)
+	}
+}
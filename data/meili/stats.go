@@ -0,0 +1,96 @@
+package meili
+
+import (
+	"errors"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// IndexStats Meilisearch index stats
+type IndexStats struct {
+	NumberOfDocuments int64            `json:"numberOfDocuments"`
+	IsIndexing        bool             `json:"isIndexing"`
+	FieldDistribution map[string]int64 `json:"fieldDistribution"`
+}
+
+// Stats returns document count, indexing state and field distribution of an index
+func (c *Client) Stats(index string) (*IndexStats, error) {
+	if c == nil || c.client == nil {
+		return nil, errors.New("meilisearch client is nil, cannot get stats")
+	}
+	stats, err := c.client.Index(index).GetStats()
+	if err != nil {
+		return nil, wrapError("get stats", err)
+	}
+	return newIndexStats(stats), nil
+}
+
+// DocumentCount returns the number of documents in an index
+func (c *Client) DocumentCount(index string) (int64, error) {
+	stats, err := c.Stats(index)
+	if err != nil {
+		return 0, err
+	}
+	return stats.NumberOfDocuments, nil
+}
+
+// newIndexStats converts client stats
+func newIndexStats(s *meilisearch.StatsIndex) *IndexStats {
+	return &IndexStats{
+		NumberOfDocuments: s.NumberOfDocuments,
+		IsIndexing:        s.IsIndexing,
+		FieldDistribution: s.FieldDistribution,
+	}
+}
+
+// StatsCollector exposes index stats as Prometheus gauges, it is optional
+// and only queries Meilisearch when scraped, register it with
+//
+//	prometheus.MustRegister(meili.NewStatsCollector(client, "app_log"))
+type StatsCollector struct {
+	client     *Client
+	indexes    []string
+	documents  *prometheus.Desc
+	isIndexing *prometheus.Desc
+}
+
+// NewStatsCollector creates a stats collector for the given indexes
+func NewStatsCollector(c *Client, indexes ...string) *StatsCollector {
+	return &StatsCollector{
+		client:  c,
+		indexes: indexes,
+		documents: prometheus.NewDesc(
+			"meilisearch_index_documents",
+			"Number of documents in the Meilisearch index.",
+			[]string{"index"}, nil,
+		),
+		isIndexing: prometheus.NewDesc(
+			"meilisearch_index_is_indexing",
+			"Whether the Meilisearch index is currently indexing (1) or not (0).",
+			[]string{"index"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (sc *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sc.documents
+	ch <- sc.isIndexing
+}
+
+// Collect implements prometheus.Collector, indexes that fail are skipped
+func (sc *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, index := range sc.indexes {
+		stats, err := sc.client.Stats(index)
+		if err != nil {
+			continue
+		}
+		indexing := 0.0
+		if stats.IsIndexing {
+			indexing = 1
+		}
+		ch <- prometheus.MustNewConstMetric(sc.documents, prometheus.GaugeValue, float64(stats.NumberOfDocuments), index)
+		ch <- prometheus.MustNewConstMetric(sc.isIndexing, prometheus.GaugeValue, indexing, index)
+	}
+}
@@ -0,0 +1,77 @@
+package meili
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newStatsServer serves the stats of the logs index, other indexes are missing
+func newStatsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/indexes/logs/stats" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"message":"index not found","code":"index_not_found","type":"invalid_request"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"numberOfDocuments":42,"isIndexing":true,"fieldDistribution":{"message":42,"level":40}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStats(t *testing.T) {
+	client := NewMeilisearch(newStatsServer(t).URL, "")
+
+	stats, err := client.Stats("logs")
+	if err != nil {
+		t.Fatalf("unexpected stats error: %v", err)
+	}
+	if stats.NumberOfDocuments != 42 || !stats.IsIndexing {
+		t.Errorf("expected 42 documents while indexing, got %+v", stats)
+	}
+	if stats.FieldDistribution["level"] != 40 {
+		t.Errorf("expected field distribution, got %v", stats.FieldDistribution)
+	}
+
+	count, err := client.DocumentCount("logs")
+	if err != nil || count != 42 {
+		t.Errorf("expected 42 documents, got %d, %v", count, err)
+	}
+	if _, err := client.DocumentCount("missing"); err == nil {
+		t.Error("expected error for a missing index")
+	}
+
+	var nilClient *Client
+	if _, err := nilClient.Stats("logs"); err == nil {
+		t.Error("expected error for nil client")
+	}
+}
+
+func TestStatsCollector(t *testing.T) {
+	client := NewMeilisearch(newStatsServer(t).URL, "")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(NewStatsCollector(client, "logs", "missing"))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("unexpected gather error: %v", err)
+	}
+	got := map[string]float64{}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			if len(m.GetLabel()) != 1 || m.GetLabel()[0].GetValue() != "logs" {
+				t.Errorf("expected only the logs index, got %v", m.GetLabel())
+			}
+			got[mf.GetName()] = m.GetGauge().GetValue()
+		}
+	}
+	if got["meilisearch_index_documents"] != 42 || got["meilisearch_index_is_indexing"] != 1 {
+		t.Errorf("expected document and indexing gauges, got %v", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 var (
 	ErrRabbitMQNotInitialized = errors.New("RabbitMQ service not initialized")
 	ErrKafkaNotInitialized    = errors.New("kafka service not initialized")
+	ErrNATSNotInitialized     = errors.New("NATS service not initialized")
 )
 
 // PublishToRabbitMQ publishes message to RabbitMQ
@@ -41,3 +42,12 @@ func (d *Data) ConsumeFromKafka(ctx context.Context, topic, groupID string, hand
 	}
 	return d.Kafka.ConsumeMessages(ctx, topic, groupID, handler)
 }
+
+// PublishToNATS publishes message to a NATS JetStream subject and waits for its ack
+func (d *Data) PublishToNATS(ctx context.Context, subject string, data []byte) error {
+	if d.NATS == nil {
+		return ErrNATSNotInitialized
+	}
+	_, err := d.NATS.Publish(ctx, subject, data)
+	return err
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	dbOpenDesc = prometheus.NewDesc("db_pool_open_connections",
+		"Number of established connections of a database pool.", []string{"name"}, nil)
+	dbInUseDesc = prometheus.NewDesc("db_pool_in_use_connections",
+		"Number of connections of a database pool currently in use.", []string{"name"}, nil)
+	dbIdleDesc = prometheus.NewDesc("db_pool_idle_connections",
+		"Number of idle connections of a database pool.", []string{"name"}, nil)
+	dbMaxOpenDesc = prometheus.NewDesc("db_pool_max_open_connections",
+		"Maximum number of open connections of a database pool, 0 is unlimited.", []string{"name"}, nil)
+	dbWaitCountDesc = prometheus.NewDesc("db_pool_wait_count_total",
+		"Number of times a database pool was waited on for a connection.", []string{"name"}, nil)
+	dbWaitDurationDesc = prometheus.NewDesc("db_pool_wait_duration_seconds_total",
+		"Time spent waiting on a database pool for a connection.", []string{"name"}, nil)
+
+	redisTotalDesc = prometheus.NewDesc("redis_pool_total_connections",
+		"Number of connections of a Redis pool.", []string{"name"}, nil)
+	redisIdleDesc = prometheus.NewDesc("redis_pool_idle_connections",
+		"Number of idle connections of a Redis pool.", []string{"name"}, nil)
+	redisStaleDesc = prometheus.NewDesc("redis_pool_stale_connections_total",
+		"Number of stale connections removed from a Redis pool.", []string{"name"}, nil)
+	redisHitsDesc = prometheus.NewDesc("redis_pool_hits_total",
+		"Number of times a free connection was found in a Redis pool.", []string{"name"}, nil)
+	redisMissesDesc = prometheus.NewDesc("redis_pool_misses_total",
+		"Number of times no free connection was found in a Redis pool.", []string{"name"}, nil)
+	redisTimeoutsDesc = prometheus.NewDesc("redis_pool_timeouts_total",
+		"Number of times waiting on a Redis pool for a connection timed out.", []string{"name"}, nil)
+)
+
+// pools collects the stats of the registered pools when scraped
+var pools = &poolCollector{
+	dbs:   make(map[string]*dbPool),
+	redis: make(map[string]*redisPool),
+}
+
+type dbPool struct {
+	stats func() sql.DBStats
+}
+
+type redisPool struct {
+	stats func() *redis.PoolStats
+}
+
+// poolCollector is a prometheus.Collector of database and Redis pools
+type poolCollector struct {
+	mu    sync.RWMutex
+	dbs   map[string]*dbPool
+	redis map[string]*redisPool
+	once  sync.Once
+}
+
+// RegisterDBPool collects the stats of a database pool, e.g. sql.DB.Stats,
+// under name until the returned func is called. A pool registered under the
+// same name before is replaced.
+func RegisterDBPool(name string, stats func() sql.DBStats) (unregister func()) {
+	pools.register()
+
+	p := &dbPool{stats: stats}
+	pools.mu.Lock()
+	pools.dbs[name] = p
+	pools.mu.Unlock()
+
+	return func() {
+		pools.mu.Lock()
+		defer pools.mu.Unlock()
+		if pools.dbs[name] == p {
+			delete(pools.dbs, name)
+		}
+	}
+}
+
+// RegisterRedisPool collects the stats of a Redis pool, e.g.
+// redis.UniversalClient.PoolStats, under name until the returned func is
+// called. A pool registered under the same name before is replaced.
+func RegisterRedisPool(name string, stats func() *redis.PoolStats) (unregister func()) {
+	pools.register()
+
+	p := &redisPool{stats: stats}
+	pools.mu.Lock()
+	pools.redis[name] = p
+	pools.mu.Unlock()
+
+	return func() {
+		pools.mu.Lock()
+		defer pools.mu.Unlock()
+		if pools.redis[name] == p {
+			delete(pools.redis, name)
+		}
+	}
+}
+
+// register registers the collector with the first pool
+func (c *poolCollector) register() {
+	c.once.Do(func() {
+		_ = register(c)
+	})
+}
+
+// Describe implements prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		dbOpenDesc, dbInUseDesc, dbIdleDesc, dbMaxOpenDesc, dbWaitCountDesc, dbWaitDurationDesc,
+		redisTotalDesc, redisIdleDesc, redisStaleDesc, redisHitsDesc, redisMissesDesc, redisTimeoutsDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, p := range c.dbs {
+		s := p.stats()
+		ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(s.InUse), name)
+		ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(s.Idle), name)
+		ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds(), name)
+	}
+
+	for name, p := range c.redis {
+		s := p.stats()
+		if s == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(redisTotalDesc, prometheus.GaugeValue, float64(s.TotalConns), name)
+		ch <- prometheus.MustNewConstMetric(redisIdleDesc, prometheus.GaugeValue, float64(s.IdleConns), name)
+		ch <- prometheus.MustNewConstMetric(redisStaleDesc, prometheus.CounterValue, float64(s.StaleConns), name)
+		ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(s.Hits), name)
+		ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(s.Misses), name)
+		ch <- prometheus.MustNewConstMetric(redisTimeoutsDesc, prometheus.CounterValue, float64(s.Timeouts), name)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPoolCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	Registerer = reg
+	defer func() { Registerer = prometheus.DefaultRegisterer }()
+
+	unregisterDB := RegisterDBPool("master", func() sql.DBStats {
+		return sql.DBStats{OpenConnections: 3, InUse: 2, Idle: 1, WaitCount: 5, WaitDuration: 2 * time.Second}
+	})
+	unregisterRedis := RegisterRedisPool("redis", func() *redis.PoolStats {
+		return &redis.PoolStats{TotalConns: 4, IdleConns: 3, Hits: 10}
+	})
+
+	expected := `
+# HELP db_pool_in_use_connections Number of connections of a database pool currently in use.
+# TYPE db_pool_in_use_connections gauge
+db_pool_in_use_connections{name="master"} 2
+# HELP db_pool_wait_duration_seconds_total Time spent waiting on a database pool for a connection.
+# TYPE db_pool_wait_duration_seconds_total counter
+db_pool_wait_duration_seconds_total{name="master"} 2
+# HELP redis_pool_idle_connections Number of idle connections of a Redis pool.
+# TYPE redis_pool_idle_connections gauge
+redis_pool_idle_connections{name="redis"} 3
+`
+	if err := testutil.CollectAndCompare(pools, strings.NewReader(expected),
+		"db_pool_in_use_connections", "db_pool_wait_duration_seconds_total", "redis_pool_idle_connections"); err != nil {
+		t.Error(err)
+	}
+
+	// A pool replaced under the same name is kept by the first unregister
+	unregisterReplaced := RegisterDBPool("master", func() sql.DBStats { return sql.DBStats{} })
+	unregisterDB()
+	if got := testutil.CollectAndCount(pools, "db_pool_open_connections"); got != 1 {
+		t.Errorf("expected the replacing pool to be kept, got %d series", got)
+	}
+
+	unregisterReplaced()
+	unregisterRedis()
+	if got := testutil.CollectAndCount(pools); got != 0 {
+		t.Errorf("expected no series after unregister, got %d", got)
+	}
+}
+
+func TestRabbitMQMetrics(t *testing.T) {
+	RabbitMQPublishFailed("events")
+	if got := testutil.ToFloat64(rabbitmqPublishFailures.WithLabelValues("events")); got != 1 {
+		t.Errorf("expected 1 publish failure, got %v", got)
+	}
+
+	RabbitMQChannelOpened()
+	RabbitMQChannelOpened()
+	RabbitMQChannelClosed()
+	if got := testutil.ToFloat64(rabbitmqChannels); got != 1 {
+		t.Errorf("expected 1 open channel, got %v", got)
+	}
+}
+
+func TestRegister_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	Registerer = reg
+	defer func() { Registerer = prometheus.DefaultRegisterer }()
+
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Test counter."})
+	if err := register(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := register(c); err != nil {
+		t.Errorf("expected registering twice to be ignored, got %v", err)
+	}
+
+	Registerer = nil
+	if err := register(prometheus.NewCounter(prometheus.CounterOpts{Name: "other_total", Help: "Other."})); err != nil {
+		t.Errorf("unexpected error without registerer: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	rabbitmqReconnects = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rabbitmq_reconnects_total",
+		Help: "Number of times a lost RabbitMQ connection was redialed.",
+	})
+	rabbitmqChannels = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rabbitmq_channels",
+		Help: "Number of open RabbitMQ channels.",
+	})
+	rabbitmqPublishFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rabbitmq_publish_failures_total",
+			Help: "Number of RabbitMQ messages which failed to be published.",
+		},
+		[]string{"exchange"},
+	)
+
+	rabbitmqOnce sync.Once
+)
+
+// RegisterRabbitMQ registers the RabbitMQ collectors, once a connection is
+// created
+func RegisterRabbitMQ() {
+	rabbitmqOnce.Do(func() {
+		_ = register(rabbitmqReconnects, rabbitmqChannels, rabbitmqPublishFailures)
+	})
+}
+
+// RabbitMQReconnected counts a redialed connection
+func RabbitMQReconnected() {
+	rabbitmqReconnects.Inc()
+}
+
+// RabbitMQChannelOpened counts an open channel until RabbitMQChannelClosed
+func RabbitMQChannelOpened() {
+	rabbitmqChannels.Inc()
+}
+
+// RabbitMQChannelClosed stops counting a channel counted by
+// RabbitMQChannelOpened
+func RabbitMQChannelClosed() {
+	rabbitmqChannels.Dec()
+}
+
+// RabbitMQPublishFailed counts a message which failed to be published to
+// exchange
+func RabbitMQPublishFailed(exchange string) {
+	rabbitmqPublishFailures.WithLabelValues(exchange).Inc()
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Registerer registers the connection collectors, which unlike the
+// transport collectors are registered automatically once a connection is
+// created. Set it before creating connections to use another registry, or to
+// nil to not register them.
+var Registerer prometheus.Registerer = prometheus.DefaultRegisterer
+
+// register registers collectors with Registerer, collectors registered
+// before are kept
+func register(cs ...prometheus.Collector) error {
+	if Registerer == nil {
+		return nil
+	}
+	var errs []error
+	for _, c := range cs {
+		if err := Registerer.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OperationFunc derives a low-cardinality operation name from a request
+type OperationFunc func(*http.Request) string
+
+var (
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "search_backend_request_duration_seconds",
+			Help:    "Duration of HTTP requests made to search backends.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"backend", "operation", "status"},
+	)
+	requestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "search_backend_request_errors_total",
+			Help: "Number of HTTP requests to search backends that failed or returned a 5xx status.",
+		},
+		[]string{"backend", "operation"},
+	)
+)
+
+// Collectors returns the collectors used by instrumented transports, they
+// are not registered automatically, register them once at startup with
+//
+//	prometheus.MustRegister(metrics.Collectors()...)
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{requestDuration, requestErrors}
+}
+
+// Transport is an http.RoundTripper recording request duration and status
+type Transport struct {
+	backend   string
+	next      http.RoundTripper
+	operation OperationFunc
+}
+
+// NewTransport wraps next, http.DefaultTransport if nil, labeling metrics
+// with backend and the operation returned by op
+func NewTransport(backend string, next http.RoundTripper, op OperationFunc) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if op == nil {
+		op = func(r *http.Request) string { return r.Method }
+	}
+	return &Transport{backend: backend, next: next, operation: op}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := t.operation(req)
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	requestDuration.WithLabelValues(t.backend, op, status).Observe(time.Since(start).Seconds())
+	if err != nil || res.StatusCode >= http.StatusInternalServerError {
+		requestErrors.WithLabelValues(t.backend, op).Inc()
+	}
+
+	return res, err
+}
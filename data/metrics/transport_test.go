@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransport_RecordsMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport("test", nil, func(r *http.Request) string {
+		return r.URL.Path[1:]
+	})}
+
+	for _, path := range []string{"/ok", "/ok", "/fail"} {
+		res, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected request error: %v", err)
+		}
+		_ = res.Body.Close()
+	}
+
+	if got := testutil.CollectAndCount(requestDuration, "search_backend_request_duration_seconds"); got != 2 {
+		t.Errorf("expected 2 duration series, got %d", got)
+	}
+	if got := testutil.ToFloat64(requestErrors.WithLabelValues("test", "fail")); got != 1 {
+		t.Errorf("expected 1 error for fail operation, got %v", got)
+	}
+	if got := testutil.ToFloat64(requestErrors.WithLabelValues("test", "ok")); got != 0 {
+		t.Errorf("expected no errors for ok operation, got %v", got)
+	}
+}
@@ -0,0 +1,132 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ncobase/common/consts"
+	"ncobase/common/helper"
+	"ncobase/common/logger"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Handler handles a JetStream message, ctx carries the trace id of the
+// message, taken from its x-md-trace header, so log entries are correlated
+type Handler func(ctx context.Context, msg jetstream.Msg) error
+
+// NATS represents NATS implementation with JetStream helpers
+type NATS struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+// New creates new NATS service
+func New(conn *nats.Conn) *NATS {
+	if conn == nil {
+		return nil
+	}
+	// Only fails on invalid options
+	js, _ := jetstream.New(conn)
+	return &NATS{conn: conn, js: js}
+}
+
+// Conn returns the NATS connection, e.g. for core pub/sub
+func (s *NATS) Conn() *nats.Conn {
+	return s.conn
+}
+
+// JetStream returns the JetStream context
+func (s *NATS) JetStream() jetstream.JetStream {
+	return s.js
+}
+
+// DeclareStream creates a stream, or updates the stream of the same name
+// to cfg
+func (s *NATS) DeclareStream(ctx context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	stream, err := s.js.CreateOrUpdateStream(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare stream %s: %w", cfg.Name, err)
+	}
+	return stream, nil
+}
+
+// DeclareConsumer creates a durable pull consumer of a stream, or updates
+// the consumer of the same name to cfg. Consumers ack explicitly unless
+// cfg sets another AckPolicy.
+func (s *NATS) DeclareConsumer(ctx context.Context, stream string, cfg jetstream.ConsumerConfig) (jetstream.Consumer, error) {
+	if cfg.Durable == "" {
+		return nil, errors.New("durable consumer name is empty")
+	}
+	consumer, err := s.js.CreateOrUpdateConsumer(ctx, stream, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare consumer %s of stream %s: %w", cfg.Durable, stream, err)
+	}
+	return consumer, nil
+}
+
+// Publish publishes data to a stream subject and waits for the ack of the
+// stream, see PublishMsg
+func (s *NATS) Publish(ctx context.Context, subject string, data []byte, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	return s.PublishMsg(ctx, &nats.Msg{Subject: subject, Data: data}, opts...)
+}
+
+// PublishMsg publishes msg and waits for the ack of the stream storing it.
+// The trace id of ctx is sent in the x-md-trace header, and
+// jetstream.WithMsgID lets the stream drop duplicates of retried publishes.
+func (s *NATS) PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if id := helper.GetTraceID(ctx); id != "" && msg.Header.Get(consts.TraceKey) == "" {
+		if msg.Header == nil {
+			msg.Header = nats.Header{}
+		}
+		msg.Header.Set(consts.TraceKey, id)
+	}
+	ack, err := s.js.PublishMsg(ctx, msg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message to %s: %w", msg.Subject, err)
+	}
+	return ack, nil
+}
+
+// Subscribe handles the messages of a durable pull consumer declared with
+// DeclareConsumer until the returned context is stopped. Messages are acked
+// once the handler returns nil and nacked for redelivery otherwise, unless
+// the handler settled them itself.
+func (s *NATS) Subscribe(ctx context.Context, stream, durable string, handler Handler, opts ...jetstream.PullConsumeOpt) (jetstream.ConsumeContext, error) {
+	consumer, err := s.js.Consumer(ctx, stream, durable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer %s of stream %s: %w", durable, stream, err)
+	}
+
+	cc, err := consumer.Consume(func(msg jetstream.Msg) {
+		ctx := messageContext(msg, stream, durable)
+		if err := handler(ctx, msg); err != nil {
+			logger.Errorf(ctx, "Failed to handle message of %s: %v", msg.Subject(), err)
+			if err := msg.Nak(); err != nil && !errors.Is(err, jetstream.ErrMsgAlreadyAckd) {
+				logger.Errorf(ctx, "Failed to nack message of %s: %v", msg.Subject(), err)
+			}
+			return
+		}
+		if err := msg.Ack(); err != nil && !errors.Is(err, jetstream.ErrMsgAlreadyAckd) {
+			logger.Errorf(ctx, "Failed to ack message of %s: %v", msg.Subject(), err)
+		}
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume %s of stream %s: %w", durable, stream, err)
+	}
+	return cc, nil
+}
+
+// messageContext returns the context of a message, carrying its trace id,
+// generated when the message has none, and its stream and consumer fields
+func messageContext(msg jetstream.Msg, stream, durable string) context.Context {
+	ctx := context.Background()
+	if id := msg.Headers().Get(consts.TraceKey); id != "" {
+		ctx = helper.SetTraceID(ctx, id)
+	} else {
+		ctx, _ = logger.EnsureTraceID(ctx)
+	}
+	ctx = logger.WithField(ctx, "stream", stream)
+	return logger.WithField(ctx, "consumer", durable)
+}
@@ -0,0 +1,36 @@
+package nats
+
+import (
+	"ncobase/common/consts"
+	"ncobase/common/helper"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// stubMsg is a message with headers only
+type stubMsg struct {
+	jetstream.Msg
+	header nats.Header
+}
+
+func (m stubMsg) Headers() nats.Header { return m.header }
+
+func TestNewNil(t *testing.T) {
+	if New(nil) != nil {
+		t.Error("expected nil service without connection")
+	}
+}
+
+func TestMessageContext(t *testing.T) {
+	ctx := messageContext(stubMsg{header: nats.Header{consts.TraceKey: []string{"trace-1"}}}, "orders", "billing")
+	if got := helper.GetTraceID(ctx); got != "trace-1" {
+		t.Errorf("expected trace id trace-1, got %q", got)
+	}
+
+	ctx = messageContext(stubMsg{}, "orders", "billing")
+	if helper.GetTraceID(ctx) == "" {
+		t.Error("expected a generated trace id")
+	}
+}
@@ -0,0 +1,250 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"ncobase/common/data/metrics"
+	"ncobase/common/logger"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	reconnectMinBackoff = 500 * time.Millisecond
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// ErrConnectionClosed is returned when using a connection closed by Close
+var ErrConnectionClosed = errors.New("rabbitmq connection is closed")
+
+// Conn is a RabbitMQ connection that redials when the broker closes it, e.g.
+// on a broker restart, with exponential backoff and jitter. Channels opened
+// on a lost connection are closed too, consumers of a RabbitMQ service built
+// with NewManaged subscribe again once the connection is back.
+type Conn struct {
+	dial       func() (*amqp.Connection, error)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu          sync.Mutex
+	conn        *amqp.Connection
+	ready       chan struct{} // closed while conn is connected
+	closed      bool
+	done        chan struct{}
+	onReconnect []func(*amqp.Connection)
+}
+
+// ConnOption function type for configuring Conn
+type ConnOption func(*Conn)
+
+// WithReconnectBackoff sets the first and the maximum delay between redials,
+// 500ms and 30s by default
+func WithReconnectBackoff(min, max time.Duration) ConnOption {
+	return func(c *Conn) {
+		c.minBackoff, c.maxBackoff = min, max
+	}
+}
+
+// Dial connects with dial and redials with it whenever the connection is lost
+func Dial(dial func() (*amqp.Connection, error), opts ...ConnOption) (*Conn, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	c := &Conn{
+		dial:       dial,
+		minBackoff: reconnectMinBackoff,
+		maxBackoff: reconnectMaxBackoff,
+		conn:       conn,
+		ready:      make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	metrics.RegisterRabbitMQ()
+	close(c.ready)
+	go c.watch(conn)
+	return c, nil
+}
+
+// unmanaged wraps a connection that is not redialed
+func unmanaged(conn *amqp.Connection) *Conn {
+	c := &Conn{conn: conn, ready: make(chan struct{}), done: make(chan struct{})}
+	metrics.RegisterRabbitMQ()
+	close(c.ready)
+	return c
+}
+
+// Connection returns the current connection, it may be closed while redialing
+func (c *Conn) Connection() *amqp.Connection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// Channel opens a channel on the current connection
+func (c *Conn) Channel() (*amqp.Channel, error) {
+	c.mu.Lock()
+	conn, closed := c.conn, c.closed
+	c.mu.Unlock()
+	if closed || conn == nil {
+		return nil, ErrConnectionClosed
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	// Counted until closed by either side, or with the connection
+	metrics.RabbitMQChannelOpened()
+	closes := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		<-closes
+		metrics.RabbitMQChannelClosed()
+	}()
+	return ch, nil
+}
+
+// Wait blocks until the connection is connected, it fails once the
+// connection is closed or ctx is done
+func (c *Conn) Wait(ctx context.Context) error {
+	c.mu.Lock()
+	ready := c.ready
+	c.mu.Unlock()
+	select {
+	case <-ready:
+		return nil
+	case <-c.done:
+		return ErrConnectionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NotifyReconnect registers fn to be called with every redialed connection,
+// before Wait returns
+func (c *Conn) NotifyReconnect(fn func(*amqp.Connection)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReconnect = append(c.onReconnect, fn)
+}
+
+// managed reports whether the connection is redialed when lost
+func (c *Conn) managed() bool {
+	return c != nil && c.dial != nil
+}
+
+// IsClosed reports whether Close was called or an unmanaged connection is closed
+func (c *Conn) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed || (c.dial == nil && (c.conn == nil || c.conn.IsClosed()))
+}
+
+// Healthcheck reports whether the connection is up, a managed connection is
+// down while it is redialed
+func (c *Conn) Healthcheck(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	conn, closed := c.conn, c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrConnectionClosed
+	}
+	if conn == nil || conn.IsClosed() {
+		return errors.New("rabbitmq connection is down")
+	}
+	return nil
+}
+
+// Close closes the connection and stops redialing
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil || conn.IsClosed() {
+		return nil
+	}
+	return conn.Close()
+}
+
+// watch redials once conn is closed, unless Close was called
+func (c *Conn) watch(conn *amqp.Connection) {
+	err := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.ready = make(chan struct{})
+	c.mu.Unlock()
+
+	logger.Warnf(context.Background(), "RabbitMQ connection lost: %v, reconnecting", err)
+	c.redial()
+}
+
+// redial dials until it succeeds or the connection is closed
+func (c *Conn) redial() {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.backoff(attempt)):
+		}
+
+		conn, err := c.dial()
+		if err != nil {
+			logger.Warnf(context.Background(), "RabbitMQ reconnect attempt %d failed: %v", attempt+1, err)
+			continue
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		c.conn = conn
+		metrics.RabbitMQReconnected()
+		ready := c.ready
+		hooks := make([]func(*amqp.Connection), len(c.onReconnect))
+		copy(hooks, c.onReconnect)
+		c.mu.Unlock()
+
+		go c.watch(conn)
+		for _, fn := range hooks {
+			fn(conn)
+		}
+		close(ready)
+		logger.Infof(context.Background(), "RabbitMQ reconnected after %d attempts", attempt+1)
+		return
+	}
+}
+
+// backoff returns the delay before a redial attempt, doubling from the
+// minimum up to the maximum, with a random jitter of up to half of it
+func (c *Conn) backoff(attempt int) time.Duration {
+	d := c.minBackoff
+	for i := 0; i < attempt && d < c.maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, c.maxBackoff)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,84 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConn_Backoff(t *testing.T) {
+	c := &Conn{minBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		got := c.backoff(attempt)
+		if got < want/2 || got > want {
+			t.Errorf("attempt %d: backoff %v not within [%v, %v]", attempt, got, want/2, want)
+		}
+	}
+}
+
+func TestConn_CloseStopsRedial(t *testing.T) {
+	var dials atomic.Int64
+	c := &Conn{
+		dial: func() (*amqp.Connection, error) {
+			dials.Add(1)
+			return nil, errors.New("connection refused")
+		},
+		minBackoff: time.Millisecond,
+		maxBackoff: 5 * time.Millisecond,
+		ready:      make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		c.redial()
+		close(stopped)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Wait to block while redialing, got %v", err)
+	}
+	if err := c.Healthcheck(context.Background()); err == nil {
+		t.Error("expected the connection to be down while redialing")
+	}
+	if dials.Load() < 2 {
+		t.Errorf("expected repeated dials, got %d", dials.Load())
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("redial must stop once the connection is closed")
+	}
+	if err := c.Wait(context.Background()); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+	if _, err := c.Channel(); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+	if err := c.Healthcheck(context.Background()); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+	if !c.IsClosed() {
+		t.Error("expected the connection to be closed")
+	}
+}
+
+func TestRabbitMQ_NilConn(t *testing.T) {
+	s := NewRabbitMQ(nil)
+	if err := s.PublishMessage("ex", "key", []byte("msg")); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+	if err := s.ConsumeMessages("queue", func([]byte) error { return nil }); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+}
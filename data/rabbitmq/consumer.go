@@ -0,0 +1,352 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ncobase/common/consts"
+	"ncobase/common/helper"
+	"ncobase/common/logger"
+	"ncobase/common/uuid"
+	"net/http"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Consumer message headers
+const (
+	RetryCountHeader    = "x-retry-count"    // times a message was retried by a Consumer
+	ErrorHeader         = "x-error"          // last handler error of a dead-lettered message
+	OriginalQueueHeader = "x-original-queue" // queue a dead-lettered message was consumed from
+)
+
+// republishTimeout is the max wait for the confirm of a retried or dead-lettered message
+const republishTimeout = 5 * time.Second
+
+// ErrConsumerStopped is returned when starting a consumer after Stop
+var ErrConsumerStopped = errors.New("rabbitmq consumer is stopped")
+
+// Handler handles a message, ctx carries the trace id of the message, taken
+// from its x-md-trace, traceparent or b3 header, so log entries are correlated
+type Handler func(ctx context.Context, m *Message) error
+
+// AckMode decides when messages are acknowledged
+type AckMode int
+
+const (
+	// AckOnSuccess acks the message once the handler returns nil
+	AckOnSuccess AckMode = iota
+	// AckManual leaves acking to the handler, which may ack after returning,
+	// a handler error still applies the ErrorPolicy to unsettled messages
+	AckManual
+)
+
+// ErrorPolicy decides what happens to a message whose handler failed
+type ErrorPolicy int
+
+const (
+	// ErrorRetry publishes the message again to the queue with an incremented
+	// x-retry-count header, up to MaxRetries, then dead-letters it
+	ErrorRetry ErrorPolicy = iota
+	// ErrorRequeue nacks the message with requeue, it is redelivered
+	// without limit, unless the queue sets a delivery limit
+	ErrorRequeue
+	// ErrorDeadLetter dead-letters the message without retrying
+	ErrorDeadLetter
+	// ErrorDrop acknowledges the message so it is discarded
+	ErrorDrop
+)
+
+// ConsumerConfig configures a Consumer
+type ConsumerConfig struct {
+	Queue       string
+	Tag         string // consumer tag, generated when empty
+	Prefetch    int    // QoS prefetch count, raised to Concurrency if lower
+	Concurrency int    // workers handling messages, 1 by default
+	AckMode     AckMode
+	OnError     ErrorPolicy
+	MaxRetries  int // retries of ErrorRetry before dead-lettering
+	PanicPolicy PanicPolicy
+
+	// DeadLetterExchange receives the dead-lettered messages with the
+	// x-error and x-original-queue headers, when empty they are nacked
+	// without requeue so the queue's dead-letter exchange, if any, gets them
+	DeadLetterExchange   string
+	DeadLetterRoutingKey string // routing key of dead-lettered messages, the queue by default
+}
+
+// Consumer consumes a queue with a pool of workers, settling messages
+// according to its AckMode and ErrorPolicy. On a connection created by Dial
+// it subscribes again once a lost connection is redialed.
+type Consumer struct {
+	svc     *RabbitMQ
+	cfg     ConsumerConfig
+	handler Handler
+
+	mu      sync.Mutex
+	ch      *amqp.Channel
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	pubMu sync.Mutex
+	pubCh *amqp.Channel // confirm mode channel of retries and dead letters
+}
+
+// NewConsumer creates a consumer of cfg.Queue, call Start to consume
+func NewConsumer(conn *Conn, cfg ConsumerConfig, handler Handler) *Consumer {
+	if cfg.Tag == "" {
+		cfg.Tag = "ctag-" + uuid.NewString()
+	}
+	return &Consumer{
+		svc: &RabbitMQ{
+			conn:        conn,
+			panicPolicy: cfg.PanicPolicy,
+			concurrency: cfg.Concurrency,
+			prefetch:    cfg.Prefetch,
+		},
+		cfg:     cfg,
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start subscribes to the queue and handles messages until Stop
+func (c *Consumer) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return ErrConsumerStopped
+	}
+	if c.started {
+		return nil
+	}
+
+	ch, msgs, err := c.svc.subscribe(c.cfg.Queue, c.cfg.Tag)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ch, c.started, c.cancel = ch, true, cancel
+	go c.run(ctx, ch, msgs)
+	return nil
+}
+
+// run handles deliveries, subscribing again after the channel is lost
+func (c *Consumer) run(ctx context.Context, ch *amqp.Channel, msgs <-chan amqp.Delivery) {
+	defer close(c.done)
+	for {
+		c.svc.handleManualDeliveries(msgs, c.handle)
+		_ = ch.Close()
+		if ctx.Err() != nil || !c.svc.conn.managed() {
+			return
+		}
+
+		var err error
+		if ch, msgs, err = c.svc.resubscribe(ctx, c.cfg.Queue, c.cfg.Tag); err != nil {
+			return
+		}
+		c.mu.Lock()
+		if c.stopped {
+			c.mu.Unlock()
+			_ = ch.Close()
+			return
+		}
+		c.ch = ch
+		c.mu.Unlock()
+	}
+}
+
+// Stop cancels the subscription and waits until the in-flight messages are
+// handled or ctx is done, unacked messages are redelivered by the broker
+func (c *Consumer) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		return nil
+	}
+	c.stopped = true
+	if !c.started {
+		c.mu.Unlock()
+		return nil
+	}
+	c.cancel()
+	ch := c.ch
+	c.mu.Unlock()
+
+	// Cancelling ends the deliveries while the channel stays open, so the
+	// workers can still settle their message
+	_ = ch.Cancel(c.cfg.Tag, false)
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.pubMu.Lock()
+	defer c.pubMu.Unlock()
+	if c.pubCh != nil {
+		_ = c.pubCh.Close()
+	}
+	return nil
+}
+
+// handle runs the handler of a message and settles it
+func (c *Consumer) handle(m *Message) error {
+	ctx := messageContext(m.Delivery, c.cfg.Queue)
+	err := c.handler(ctx, m)
+	if m.Settled() {
+		return err
+	}
+	if err == nil {
+		if c.cfg.AckMode == AckOnSuccess {
+			return m.Ack()
+		}
+		return nil
+	}
+
+	if settleErr := c.fail(ctx, m, err); settleErr != nil {
+		logger.Errorf(ctx, "Failed to settle message %s: %v", m.MessageId, settleErr)
+	}
+	return err
+}
+
+// fail settles a message whose handler failed according to the ErrorPolicy
+func (c *Consumer) fail(ctx context.Context, m *Message, cause error) error {
+	switch c.cfg.OnError {
+	case ErrorRequeue:
+		return m.Nack(true)
+	case ErrorDrop:
+		return m.Ack()
+	case ErrorRetry:
+		if n := retryCount(m.Headers); n < c.cfg.MaxRetries {
+			msg := publishing(m.Delivery)
+			msg.Headers[RetryCountHeader] = int32(n + 1)
+			if err := c.republish(ctx, "", c.cfg.Queue, msg); err != nil {
+				_ = m.Nack(true)
+				return fmt.Errorf("failed to retry message: %w", err)
+			}
+			return m.Ack()
+		}
+	}
+	return c.deadLetter(ctx, m, cause)
+}
+
+// deadLetter routes a message to the dead-letter exchange
+func (c *Consumer) deadLetter(ctx context.Context, m *Message, cause error) error {
+	if c.cfg.DeadLetterExchange == "" {
+		return m.Nack(false)
+	}
+	msg := publishing(m.Delivery)
+	msg.Headers[ErrorHeader] = cause.Error()
+	msg.Headers[OriginalQueueHeader] = c.cfg.Queue
+	key := c.cfg.DeadLetterRoutingKey
+	if key == "" {
+		key = c.cfg.Queue
+	}
+	if err := c.republish(ctx, c.cfg.DeadLetterExchange, key, msg); err != nil {
+		_ = m.Nack(true)
+		return fmt.Errorf("failed to dead-letter message: %w", err)
+	}
+	return m.Ack()
+}
+
+// republish publishes a message and waits for the broker confirm, so the
+// original is only acked once its copy is safe
+func (c *Consumer) republish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(ctx, republishTimeout)
+	defer cancel()
+
+	c.pubMu.Lock()
+	defer c.pubMu.Unlock()
+	if c.pubCh == nil || c.pubCh.IsClosed() {
+		ch, err := c.svc.conn.Channel()
+		if err != nil {
+			return err
+		}
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return err
+		}
+		c.pubCh = ch
+	}
+
+	dc, err := c.pubCh.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, msg)
+	if err != nil {
+		return err
+	}
+	acked, err := dc.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errors.New("message nacked by the broker")
+	}
+	return nil
+}
+
+// messageContext returns the context of a delivery, carrying its trace id,
+// generated when the message has none, and its queue and message id fields
+func messageContext(d amqp.Delivery, queue string) context.Context {
+	h := http.Header{}
+	for k, v := range d.Headers {
+		if s, ok := v.(string); ok {
+			h.Set(k, s)
+		}
+	}
+
+	ctx := context.Background()
+	if id := h.Get(consts.TraceKey); id != "" {
+		ctx = helper.SetTraceID(ctx, id)
+	} else if p, ok := logger.ExtractHTTP(h); ok {
+		ctx = helper.SetTraceID(ctx, p.TraceID)
+	} else {
+		ctx, _ = logger.EnsureTraceID(ctx)
+	}
+	ctx = logger.WithField(ctx, "queue", queue)
+	if d.MessageId != "" {
+		ctx = logger.WithField(ctx, "message_id", d.MessageId)
+	}
+	return ctx
+}
+
+// retryCount returns the x-retry-count header of a message
+func retryCount(headers amqp.Table) int {
+	switch n := headers[RetryCountHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	default:
+		return 0
+	}
+}
+
+// publishing copies a delivery to publish it again
+func publishing(d amqp.Delivery) amqp.Publishing {
+	headers := make(amqp.Table, len(d.Headers)+2)
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    d.DeliveryMode,
+		Priority:        d.Priority,
+		CorrelationId:   d.CorrelationId,
+		ReplyTo:         d.ReplyTo,
+		Expiration:      d.Expiration,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		UserId:          d.UserId,
+		AppId:           d.AppId,
+		Body:            d.Body,
+	}
+}
@@ -0,0 +1,82 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"ncobase/common/consts"
+	"ncobase/common/helper"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestConsumer_Handle(t *testing.T) {
+	failing := func(context.Context, *Message) error { return errors.New("boom") }
+	testCases := []struct {
+		name        string
+		cfg         ConsumerConfig
+		handler     Handler
+		wantAcked   bool
+		wantNacked  bool
+		wantRequeue bool
+	}{
+		{name: "ack on success", handler: func(context.Context, *Message) error { return nil }, wantAcked: true},
+		{name: "manual leaves unsettled", cfg: ConsumerConfig{AckMode: AckManual}, handler: func(context.Context, *Message) error { return nil }},
+		{name: "requeue", cfg: ConsumerConfig{OnError: ErrorRequeue}, handler: failing, wantNacked: true, wantRequeue: true},
+		{name: "drop", cfg: ConsumerConfig{OnError: ErrorDrop}, handler: failing, wantAcked: true},
+		{name: "dead letter", cfg: ConsumerConfig{OnError: ErrorDeadLetter}, handler: failing, wantNacked: true},
+		{name: "retries exhausted", cfg: ConsumerConfig{OnError: ErrorRetry, MaxRetries: 2}, handler: failing, wantNacked: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Queue = "orders"
+			c := NewConsumer(nil, tc.cfg, tc.handler)
+			ack := &fakeAcknowledger{}
+			m := &Message{Delivery: amqp.Delivery{
+				Acknowledger: ack,
+				DeliveryTag:  1,
+				Headers:      amqp.Table{RetryCountHeader: int32(2)},
+			}}
+			_ = c.handle(m)
+
+			if got := len(ack.acked) == 1; got != tc.wantAcked {
+				t.Errorf("acked = %v, want %v", got, tc.wantAcked)
+			}
+			if got := len(ack.nacked) == 1; got != tc.wantNacked {
+				t.Fatalf("nacked = %v, want %v", got, tc.wantNacked)
+			}
+			if tc.wantNacked && ack.requeue[0] != tc.wantRequeue {
+				t.Errorf("requeue = %v, want %v", ack.requeue[0], tc.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestMessageContext(t *testing.T) {
+	ctx := messageContext(amqp.Delivery{Headers: amqp.Table{consts.TraceKey: "trace-1"}}, "orders")
+	if got := helper.GetTraceID(ctx); got != "trace-1" {
+		t.Errorf("expected the x-md-trace id, got %q", got)
+	}
+
+	ctx = messageContext(amqp.Delivery{Headers: amqp.Table{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}}, "orders")
+	if got := helper.GetTraceID(ctx); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the traceparent id, got %q", got)
+	}
+
+	if got := helper.GetTraceID(messageContext(amqp.Delivery{}, "orders")); got == "" {
+		t.Error("expected a generated trace id")
+	}
+}
+
+func TestConsumer_StopBeforeStart(t *testing.T) {
+	c := NewConsumer(nil, ConsumerConfig{Queue: "orders"}, nil)
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Start(); !errors.Is(err, ErrConsumerStopped) {
+		t.Errorf("expected ErrConsumerStopped, got %v", err)
+	}
+}
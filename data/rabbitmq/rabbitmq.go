@@ -1,23 +1,159 @@
 package rabbitmq
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"ncobase/common/data/breaker"
+	"ncobase/common/data/metrics"
+	"ncobase/common/logger"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	// ErrConnectionBlocked is returned when publishing while the broker blocks the connection
+	ErrConnectionBlocked = errors.New("rabbitmq connection is blocked by the broker")
+	// ErrAlreadySettled is returned when acking or nacking a message a second time
+	ErrAlreadySettled = errors.New("rabbitmq message already settled")
+)
+
+// PanicPolicy decides what happens to a message whose handler panicked
+type PanicPolicy int
+
+const (
+	// PanicDeadLetter rejects the message without requeue, so the broker
+	// routes it to the queue's dead-letter exchange if one is configured
+	PanicDeadLetter PanicPolicy = iota
+	// PanicRequeue rejects the message and puts it back on the queue
+	PanicRequeue
+	// PanicDrop acknowledges the message so it is discarded
+	PanicDrop
+)
+
 // RabbitMQ represents RabbitMQ implementation
 type RabbitMQ struct {
-	conn *amqp.Connection
+	conn        *Conn
+	panicPolicy PanicPolicy
+	concurrency int
+	prefetch    int
+	breaker     *breaker.Breaker
+	blocked     atomic.Bool
+}
+
+// Option function type for configuring RabbitMQ
+type Option func(*RabbitMQ)
+
+// WithPanicPolicy sets the behavior when a consumer handler panics
+func WithPanicPolicy(p PanicPolicy) Option {
+	return func(s *RabbitMQ) {
+		s.panicPolicy = p
+	}
+}
+
+// WithConcurrency sets the number of workers handling deliveries of a consumer
+//
+// With more than one worker messages are processed in parallel, so ordering
+// is no longer guaranteed, even within a single queue.
+func WithConcurrency(n int) Option {
+	return func(s *RabbitMQ) {
+		s.concurrency = n
+	}
+}
+
+// WithPrefetch sets the consumer QoS prefetch count, it is raised to the
+// concurrency if lower so that every worker can be kept busy
+func WithPrefetch(n int) Option {
+	return func(s *RabbitMQ) {
+		s.prefetch = n
+	}
+}
+
+// WithCircuitBreaker fails publishes fast with breaker.ErrOpen once the
+// broker keeps failing them
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(s *RabbitMQ) {
+		s.breaker = b
+	}
 }
 
 // NewRabbitMQ creates new RabbitMQ connection
-func NewRabbitMQ(conn *amqp.Connection) *RabbitMQ {
-	return &RabbitMQ{conn: conn}
+//
+// The connection is not redialed when lost, see NewManaged.
+func NewRabbitMQ(conn *amqp.Connection, opts ...Option) *RabbitMQ {
+	var c *Conn
+	if conn != nil {
+		c = unmanaged(conn)
+	}
+	return NewManaged(c, opts...)
+}
+
+// NewManaged creates a RabbitMQ service on a connection created by Dial,
+// consumers subscribe again once a lost connection is redialed
+func NewManaged(conn *Conn, opts ...Option) *RabbitMQ {
+	s := &RabbitMQ{conn: conn}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if conn != nil {
+		conn.NotifyReconnect(func(c *amqp.Connection) {
+			go s.watchBlocked(c.NotifyBlocked(make(chan amqp.Blocking, 1)))
+		})
+		if c := conn.Connection(); c != nil {
+			go s.watchBlocked(c.NotifyBlocked(make(chan amqp.Blocking, 1)))
+		}
+	}
+	return s
+}
+
+// IsBlocked reports whether the broker currently blocks the connection,
+// e.g. because of a memory or disk alarm
+func (s *RabbitMQ) IsBlocked() bool {
+	return s.blocked.Load()
+}
+
+// watchBlocked tracks blocked/unblocked notifications until the channel is closed
+func (s *RabbitMQ) watchBlocked(notify <-chan amqp.Blocking) {
+	for b := range notify {
+		s.blocked.Store(b.Active)
+		if b.Active {
+			logger.Warnf(context.Background(), "RabbitMQ connection blocked by broker: %s", b.Reason)
+		} else {
+			logger.Infof(context.Background(), "RabbitMQ connection unblocked")
+		}
+	}
+	s.blocked.Store(false)
 }
 
 // PublishMessage publishes message to RabbitMQ
-func (s *RabbitMQ) PublishMessage(exchange, routingKey string, body []byte) error {
+//
+// It fails fast with ErrConnectionBlocked instead of hanging while the broker blocks publishers,
+// and with breaker.ErrOpen while the circuit breaker is open.
+func (s *RabbitMQ) PublishMessage(exchange, routingKey string, body []byte) (err error) {
+	defer func() {
+		if err != nil {
+			metrics.RabbitMQPublishFailed(exchange)
+		}
+	}()
+
+	if s.IsBlocked() {
+		return ErrConnectionBlocked
+	}
+	if s.conn == nil {
+		return ErrConnectionClosed
+	}
+
+	return s.breaker.Execute(func() error {
+		return s.publish(exchange, routingKey, body)
+	})
+}
+
+// publish publishes message on a new channel
+func (s *RabbitMQ) publish(exchange, routingKey string, body []byte) error {
 	ch, err := s.conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel: %w", err)
@@ -43,43 +179,243 @@ func (s *RabbitMQ) PublishMessage(exchange, routingKey string, body []byte) erro
 	return nil
 }
 
+// Message is a delivery whose acknowledgement is controlled by the handler
+type Message struct {
+	amqp.Delivery
+	settled atomic.Bool
+}
+
+// Ack acknowledges the message, it can be called after the handler returned,
+// e.g. once a downstream transaction committed
+func (m *Message) Ack() error {
+	if !m.settled.CompareAndSwap(false, true) {
+		return ErrAlreadySettled
+	}
+	if err := m.Delivery.Ack(false); err != nil {
+		return fmt.Errorf("failed to ack message: %w", err)
+	}
+	return nil
+}
+
+// Nack rejects the message, with requeue it is delivered again, otherwise it
+// is dead-lettered if the queue has a dead-letter exchange
+func (m *Message) Nack(requeue bool) error {
+	if !m.settled.CompareAndSwap(false, true) {
+		return ErrAlreadySettled
+	}
+	if err := m.Delivery.Nack(false, requeue); err != nil {
+		return fmt.Errorf("failed to nack message: %w", err)
+	}
+	return nil
+}
+
+// Settled reports whether Ack or Nack has been called
+func (m *Message) Settled() bool {
+	return m.settled.Load()
+}
+
+// ManualHandler handles a message and settles it explicitly
+type ManualHandler func(m *Message) error
+
 // ConsumeMessages consumes messages from RabbitMQ
+//
+// Each message is acked once the handler returns nil, so a message in flight
+// when the process or connection dies is redelivered. A handler error nacks
+// the message without requeue, routing it to the queue's dead-letter exchange
+// if one is configured and discarding it otherwise, a handler panic is
+// recovered and the message is settled according to the configured
+// PanicPolicy. Use ConsumeMessagesManual to settle messages explicitly.
 func (s *RabbitMQ) ConsumeMessages(queue string, handler func([]byte) error) error {
+	return s.consume(queue, func(msgs <-chan amqp.Delivery) {
+		s.handleDeliveries(msgs, handler)
+	})
+}
+
+// ConsumeMessagesManual consumes messages from RabbitMQ, leaving acknowledgement to the handler
+//
+// The handler calls m.Ack or m.Nack whenever the message is safely processed,
+// possibly after returning, giving at-least-once delivery: a message that is
+// not acked before the channel or connection drops is redelivered by the
+// broker with Redelivered set. Handlers must therefore be idempotent, and
+// since redelivered messages go back to the queue, ordering is not preserved.
+// Unacked messages count against the prefetch limit, so deferring acks for too
+// long stalls the consumer. A handler error nacks the message without requeue
+// and a panic settles it according to the PanicPolicy, unless it was already settled.
+//
+// On a connection created by Dial the consumer subscribes again once a lost
+// connection is redialed, or after its channel is closed by the broker.
+func (s *RabbitMQ) ConsumeMessagesManual(queue string, handler ManualHandler) error {
+	return s.consume(queue, func(msgs <-chan amqp.Delivery) {
+		s.handleManualDeliveries(msgs, handler)
+	})
+}
+
+// consume subscribes to queue and runs handle on the deliveries in the
+// background, subscribing again once a managed connection is back
+func (s *RabbitMQ) consume(queue string, handle func(msgs <-chan amqp.Delivery)) error {
+	ch, msgs, err := s.subscribe(queue, "")
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			handle(msgs)
+			_ = ch.Close()
+			if !s.conn.managed() {
+				return
+			}
+			if ch, msgs, err = s.resubscribe(context.Background(), queue, ""); err != nil {
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// subscribe opens a channel and registers a consumer of queue, the broker
+// generates the consumer tag when empty
+func (s *RabbitMQ) subscribe(queue, tag string) (*amqp.Channel, <-chan amqp.Delivery, error) {
+	if s.conn == nil {
+		return nil, nil, ErrConnectionClosed
+	}
 	ch, err := s.conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
+		return nil, nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	defer func(ch *amqp.Channel) {
-		_ = ch.Close()
-	}(ch)
+	if prefetch := s.prefetchCount(); prefetch > 0 {
+		if err := ch.Qos(prefetch, 0, false); err != nil {
+			_ = ch.Close()
+			return nil, nil, fmt.Errorf("failed to set QoS: %w", err)
+		}
+	}
 
 	msgs, err := ch.Consume(
 		queue, // queue
-		"",    // consumer
-		true,  // auto-ack
+		tag,   // consumer
+		false, // auto-ack
 		false, // exclusive
 		false, // no-local
 		false, // no-wait
 		nil,   // args
 	)
 	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
+		_ = ch.Close()
+		return nil, nil, fmt.Errorf("failed to register consumer: %w", err)
 	}
+	return ch, msgs, nil
+}
 
-	go func() {
-		for d := range msgs {
-			if err := handler(d.Body); err != nil {
-				fmt.Printf("Failed to process message: %v\n", err)
+// resubscribe subscribes to queue again once the connection is back,
+// retrying with backoff until it succeeds, the connection is closed or ctx
+// is done
+func (s *RabbitMQ) resubscribe(ctx context.Context, queue, tag string) (*amqp.Channel, <-chan amqp.Delivery, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.conn.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+		ch, msgs, err := s.subscribe(queue, tag)
+		if err == nil {
+			logger.Infof(context.Background(), "RabbitMQ consumer of %s subscribed again", queue)
+			return ch, msgs, nil
+		}
+		logger.Warnf(context.Background(), "RabbitMQ consumer of %s failed to subscribe again: %v", queue, err)
+
+		select {
+		case <-s.conn.done:
+			return nil, nil, ErrConnectionClosed
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(s.conn.backoff(attempt)):
+		}
+	}
+}
+
+// autoAck adapts a body handler to a manual handler that acks on success
+func autoAck(handler func([]byte) error) ManualHandler {
+	return func(m *Message) error {
+		if err := handler(m.Body); err != nil {
+			return err
+		}
+		return m.Ack()
+	}
+}
+
+// prefetchCount returns the QoS prefetch count aligned with the worker count
+func (s *RabbitMQ) prefetchCount() int {
+	return max(s.prefetch, s.workers())
+}
+
+// workers returns the number of consumer workers, at least one
+func (s *RabbitMQ) workers() int {
+	return max(s.concurrency, 1)
+}
+
+// handleDeliveries runs the handler for every delivery until the channel is
+// closed, acking each message once the handler returns successfully
+func (s *RabbitMQ) handleDeliveries(msgs <-chan amqp.Delivery, handler func([]byte) error) {
+	s.handleManualDeliveries(msgs, autoAck(handler))
+}
+
+// handleManualDeliveries runs the handler for every delivery until the channel
+// is closed, it returns only after every worker finished its in-flight message
+func (s *RabbitMQ) handleManualDeliveries(msgs <-chan amqp.Delivery, handler ManualHandler) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range msgs {
+				s.handleDelivery(&Message{Delivery: d}, handler)
 			}
+		}()
+	}
+	wg.Wait()
+}
+
+// handleDelivery runs the handler for a single message and settles it on error or panic
+func (s *RabbitMQ) handleDelivery(m *Message, handler ManualHandler) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(context.Background(), "RabbitMQ handler panic, message_id: %s, panic: %v\n%s", m.MessageId, r, debug.Stack())
+			s.settlePanicked(m)
 		}
 	}()
 
-	return nil
+	if err := handler(m); err != nil {
+		logger.Errorf(context.Background(), "Failed to process message %s: %v", m.MessageId, err)
+		if !m.Settled() {
+			_ = m.Nack(false)
+		}
+	}
+}
+
+// settlePanicked settles a delivery whose handler panicked
+func (s *RabbitMQ) settlePanicked(m *Message) {
+	if m.Settled() {
+		return
+	}
+	var err error
+	switch s.panicPolicy {
+	case PanicRequeue:
+		err = m.Nack(true)
+	case PanicDrop:
+		err = m.Ack()
+	default:
+		err = m.Nack(false)
+	}
+	if err != nil {
+		logger.Errorf(context.Background(), "Failed to settle panicked message %s: %v", m.MessageId, err)
+	}
 }
 
 // Close closes the RabbitMQ service
 func (s *RabbitMQ) Close() error {
+	if s.conn == nil {
+		return nil
+	}
 	if err := s.conn.Close(); err != nil {
 		return fmt.Errorf("failed to close RabbitMQ connection: %w", err)
 	}
@@ -0,0 +1,285 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// fakeAcknowledger records how deliveries were settled
+type fakeAcknowledger struct {
+	mu      sync.Mutex
+	acked   []uint64
+	nacked  []uint64
+	requeue []bool
+	closed  bool // simulates a dropped channel, settling fails like on a real closed channel
+}
+
+func (f *fakeAcknowledger) Ack(tag uint64, _ bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return amqp.ErrClosed
+	}
+	f.acked = append(f.acked, tag)
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(tag uint64, _ bool, requeue bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return amqp.ErrClosed
+	}
+	f.nacked = append(f.nacked, tag)
+	f.requeue = append(f.requeue, requeue)
+	return nil
+}
+
+func (f *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return f.Nack(tag, false, requeue)
+}
+
+func TestHandleDeliveries_PanicRecovery(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      PanicPolicy
+		wantNacked  bool
+		wantRequeue bool
+	}{
+		{name: "dead letter", policy: PanicDeadLetter, wantNacked: true},
+		{name: "requeue", policy: PanicRequeue, wantNacked: true, wantRequeue: true},
+		{name: "drop", policy: PanicDrop},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewRabbitMQ(nil, WithPanicPolicy(tc.policy))
+			ack := &fakeAcknowledger{}
+
+			msgs := make(chan amqp.Delivery, 2)
+			msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "bad", Body: []byte("panic")}
+			msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, MessageId: "good", Body: []byte("ok")}
+			close(msgs)
+
+			var processed []string
+			s.handleDeliveries(msgs, func(body []byte) error {
+				if string(body) == "panic" {
+					panic("malformed message")
+				}
+				processed = append(processed, string(body))
+				return nil
+			})
+
+			if len(processed) != 1 || processed[0] != "ok" {
+				t.Fatalf("expected subsequent message to be processed, got %v", processed)
+			}
+
+			if tc.wantNacked {
+				if len(ack.nacked) != 1 || ack.nacked[0] != 1 {
+					t.Fatalf("expected panicked message to be nacked, got %v", ack.nacked)
+				}
+				if ack.requeue[0] != tc.wantRequeue {
+					t.Errorf("expected requeue %v, got %v", tc.wantRequeue, ack.requeue[0])
+				}
+			} else if len(ack.nacked) != 0 {
+				t.Errorf("expected no nacks, got %v", ack.nacked)
+			}
+
+			if ack.acked[len(ack.acked)-1] != 2 {
+				t.Errorf("expected good message to be acked, got %v", ack.acked)
+			}
+		})
+	}
+}
+
+func TestHandleDeliveries_AckSemantics(t *testing.T) {
+	s := NewRabbitMQ(nil)
+	ack := &fakeAcknowledger{}
+
+	msgs := make(chan amqp.Delivery, 2)
+	msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: []byte("ok")}
+	msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: []byte("fail")}
+	close(msgs)
+
+	s.handleDeliveries(msgs, func(body []byte) error {
+		if string(body) == "fail" {
+			return errors.New("invalid payload")
+		}
+		return nil
+	})
+
+	if len(ack.acked) != 1 || ack.acked[0] != 1 {
+		t.Errorf("expected the handled message to be acked, got %v", ack.acked)
+	}
+	if len(ack.nacked) != 1 || ack.nacked[0] != 2 || ack.requeue[0] {
+		t.Errorf("expected the failed message to be nacked without requeue, got %v %v", ack.nacked, ack.requeue)
+	}
+}
+
+func TestWatchBlocked(t *testing.T) {
+	s := NewRabbitMQ(nil)
+	notify := make(chan amqp.Blocking)
+	done := make(chan struct{})
+
+	go func() {
+		s.watchBlocked(notify)
+		close(done)
+	}()
+
+	notify <- amqp.Blocking{Active: true, Reason: "low on disk"}
+	notify <- amqp.Blocking{Active: true, Reason: "low on disk"} // ensure the first was processed
+	if !s.IsBlocked() {
+		t.Fatal("expected connection to be blocked")
+	}
+	if err := s.PublishMessage("ex", "key", []byte("msg")); !errors.Is(err, ErrConnectionBlocked) {
+		t.Errorf("expected ErrConnectionBlocked, got %v", err)
+	}
+
+	notify <- amqp.Blocking{Active: false}
+	close(notify)
+	<-done
+	if s.IsBlocked() {
+		t.Error("expected connection to be unblocked")
+	}
+}
+
+func TestHandleDeliveries_Concurrency(t *testing.T) {
+	s := NewRabbitMQ(nil, WithConcurrency(4), WithPrefetch(2))
+	if got := s.prefetchCount(); got != 4 {
+		t.Errorf("expected prefetch raised to concurrency 4, got %d", got)
+	}
+
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 100)
+	for i := 1; i <= 100; i++ {
+		msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(i)}
+	}
+	close(msgs)
+
+	var handled atomic.Int64
+	s.handleDeliveries(msgs, func([]byte) error {
+		time.Sleep(time.Millisecond)
+		handled.Add(1)
+		return nil
+	})
+
+	// handleDeliveries must drain all workers before returning
+	if got := handled.Load(); got != 100 {
+		t.Errorf("expected 100 handled messages, got %d", got)
+	}
+	if len(ack.acked) != 100 {
+		t.Errorf("expected 100 acks, got %d", len(ack.acked))
+	}
+}
+
+func TestHandleManualDeliveries_DeferredAck(t *testing.T) {
+	s := NewRabbitMQ(nil)
+	ack := &fakeAcknowledger{}
+
+	msgs := make(chan amqp.Delivery, 1)
+	msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "order-1", Body: []byte("order")}
+	close(msgs)
+
+	var pending *Message
+	s.handleManualDeliveries(msgs, func(m *Message) error {
+		pending = m // ack later, once the downstream commit succeeded
+		return nil
+	})
+
+	if len(ack.acked) != 0 || len(ack.nacked) != 0 {
+		t.Fatalf("message must stay unsettled until acked, got acked=%v nacked=%v", ack.acked, ack.nacked)
+	}
+
+	if err := pending.Ack(); err != nil {
+		t.Fatalf("unexpected ack error: %v", err)
+	}
+	if len(ack.acked) != 1 || ack.acked[0] != 1 {
+		t.Errorf("expected message to be acked, got %v", ack.acked)
+	}
+	if err := pending.Nack(true); !errors.Is(err, ErrAlreadySettled) {
+		t.Errorf("expected ErrAlreadySettled, got %v", err)
+	}
+}
+
+func TestHandleManualDeliveries_RedeliveryAfterDrop(t *testing.T) {
+	s := NewRabbitMQ(nil)
+
+	// First delivery: the channel drops before the downstream commit acks
+	dropped := &fakeAcknowledger{}
+	first := make(chan amqp.Delivery, 1)
+	first <- amqp.Delivery{Acknowledger: dropped, DeliveryTag: 1, MessageId: "order-1", Body: []byte("order")}
+	close(first)
+
+	var pending *Message
+	s.handleManualDeliveries(first, func(m *Message) error {
+		pending = m
+		return nil
+	})
+	dropped.closed = true
+	if err := pending.Ack(); !errors.Is(err, amqp.ErrClosed) {
+		t.Fatalf("expected ack on dropped channel to fail, got %v", err)
+	}
+
+	// The broker redelivers the unacked message on a new channel
+	ack := &fakeAcknowledger{}
+	second := make(chan amqp.Delivery, 1)
+	second <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, MessageId: "order-1", Redelivered: true, Body: []byte("order")}
+	close(second)
+
+	var redelivered bool
+	s.handleManualDeliveries(second, func(m *Message) error {
+		redelivered = m.Redelivered
+		return m.Ack()
+	})
+
+	if !redelivered {
+		t.Error("expected the handler to see the redelivered flag")
+	}
+	if len(ack.acked) != 1 {
+		t.Errorf("expected redelivered message to be acked, got %v", ack.acked)
+	}
+}
+
+func TestHandleManualDeliveries_ErrorAfterSettle(t *testing.T) {
+	s := NewRabbitMQ(nil)
+	ack := &fakeAcknowledger{}
+
+	msgs := make(chan amqp.Delivery, 1)
+	msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: []byte("x")}
+	close(msgs)
+
+	s.handleManualDeliveries(msgs, func(m *Message) error {
+		_ = m.Nack(true)
+		return errors.New("downstream failed")
+	})
+
+	if len(ack.nacked) != 1 || !ack.requeue[0] {
+		t.Errorf("expected only the handler's requeue nack, got nacked=%v requeue=%v", ack.nacked, ack.requeue)
+	}
+}
+
+func BenchmarkHandleDeliveries(b *testing.B) {
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			s := NewRabbitMQ(nil, WithConcurrency(concurrency))
+			ack := &fakeAcknowledger{}
+			msgs := make(chan amqp.Delivery, b.N)
+			for i := 0; i < b.N; i++ {
+				msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(i)}
+			}
+			close(msgs)
+
+			b.ResetTimer()
+			s.handleDeliveries(msgs, func([]byte) error {
+				time.Sleep(100 * time.Microsecond) // simulated I/O bound handler
+				return nil
+			})
+		})
+	}
+}
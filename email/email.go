@@ -9,8 +9,8 @@ type Email struct {
 	Provider     string
 	Mailgun      *MailgunConfig
 	Aliyun       *AliyunConfig
-	NetEase      *NetEaseConfig
-	SendGrid     *SendGridConfig
+	NetEase      *NetEaseConfig  `config:"netease"`
+	SendGrid     *SendGridConfig `config:"sendgrid"`
 	SMTP         *SMTPConfig
 	TencentCloud *TencentCloudConfig
 }
@@ -9,8 +9,8 @@ import (
 
 // SMTPConfig holds the configuration for local email sending
 type SMTPConfig struct {
-	SMTPHost string
-	SMTPPort string
+	SMTPHost string `config:"host"`
+	SMTPPort string `config:"port"`
 	Username string
 	Password string
 	From     string
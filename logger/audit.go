@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrAuditDisabled is returned by Audit when no audit log is configured
+	ErrAuditDisabled = errors.New("audit log not configured")
+	// ErrAuditField is returned by Audit when a mandatory field is empty
+	ErrAuditField = errors.New("audit action, actor and target are required")
+	// ErrAuditTampered is returned by VerifyAudit when the hash chain is broken
+	ErrAuditTampered = errors.New("audit hash chain broken")
+)
+
+// AuditRecord is a record of the audit log
+//
+// Hash is the SHA-256 of the record encoded without it, PrevHash the hash of
+// the previous record, empty for the first one, so changing, removing or
+// reordering records breaks the chain.
+type AuditRecord struct {
+	Seq      uint64          `json:"seq"`
+	Time     string          `json:"time"` // RFC 3339 in UTC
+	Action   string          `json:"action"`
+	Actor    string          `json:"actor"`
+	Target   string          `json:"target"`
+	TraceID  string          `json:"trace_id,omitempty"`
+	Details  json.RawMessage `json:"details,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash,omitempty"`
+}
+
+// digest returns the hash of the record, ignoring its Hash
+func (r AuditRecord) digest() (string, error) {
+	r.Hash = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditLog is an append-only file of hash chained audit records, one JSON
+// object per line, kept apart from the application logs
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  uint64
+	prev string
+}
+
+// OpenAuditLog opens the audit log at path, creating it and its directory,
+// and continues the chain of its last record
+func OpenAuditLog(path string) (*AuditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	a := &AuditLog{file: f}
+	if err := a.resume(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// resume reads the last record to continue its sequence and hash
+func (a *AuditLog) resume() error {
+	var last []byte
+	sc := bufio.NewScanner(a.file)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*megabyte)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if last == nil {
+		return nil
+	}
+	var rec AuditRecord
+	if err := json.Unmarshal(last, &rec); err != nil || rec.Hash == "" {
+		return fmt.Errorf("failed to resume audit log, last record is invalid: %w", ErrAuditTampered)
+	}
+	a.seq, a.prev = rec.Seq, rec.Hash
+	return nil
+}
+
+// Record appends a record chained to the previous one and syncs it to disk
+func (a *AuditLog) Record(ctx context.Context, action, actor, target string, details map[string]any) (*AuditRecord, error) {
+	if action == "" || actor == "" || target == "" {
+		return nil, ErrAuditField
+	}
+	rec := AuditRecord{
+		Action: action,
+		Actor:  actor,
+		Target: target,
+	}
+	trace := logrus.Fields{}
+	if id := getTraceID(ctx); id != "" {
+		trace[traceKey] = id
+	}
+	withSpan(ctx, trace)
+	if id, ok := trace[traceKey].(string); ok {
+		rec.TraceID = id
+	}
+	if len(details) > 0 {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal audit details: %w", err)
+		}
+		rec.Details = b
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rec.Seq = a.seq + 1
+	rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	rec.PrevHash = a.prev
+	hash, err := rec.digest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash audit record: %w", err)
+	}
+	rec.Hash = hash
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	a.seq, a.prev = rec.Seq, rec.Hash
+	return &rec, nil
+}
+
+// Close closes the audit log file
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// VerifyAudit checks the hash chain of an audit log and returns the number of
+// valid records, the error wraps ErrAuditTampered and names the first record
+// breaking the chain
+func VerifyAudit(r io.Reader) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*megabyte)
+	prev := ""
+	n := 0
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return n, fmt.Errorf("audit record %d is not valid JSON: %w", n+1, ErrAuditTampered)
+		}
+		hash, err := rec.digest()
+		if err != nil {
+			return n, fmt.Errorf("failed to hash audit record %d: %w", rec.Seq, err)
+		}
+		if rec.Seq != uint64(n+1) || rec.PrevHash != prev || rec.Hash != hash {
+			return n, fmt.Errorf("audit record %d: %w", n+1, ErrAuditTampered)
+		}
+		prev = rec.Hash
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return n, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return n, nil
+}
+
+// Audit appends a record of actor performing action on target to the audit
+// log, details are redacted like log fields
+//
+// Unlike log entries, audit records are never sampled or filtered by level,
+// and the error must be handled since a lost record breaks the trail.
+func (l *Logger) Audit(ctx context.Context, action, actor, target string, details map[string]any) error {
+	root := l.root()
+	if root.audit == nil {
+		return ErrAuditDisabled
+	}
+	if root.redactor != nil && len(details) > 0 {
+		details = root.redactor.redactMap(details)
+	}
+	_, err := root.audit.Record(ctx, action, actor, target, details)
+	return err
+}
+
+// Audit appends a record to the audit log of the package-level logger
+func Audit(ctx context.Context, action, actor, target string, details map[string]any) error {
+	return StdLogger().Audit(ctx, action, actor, target, details)
+}
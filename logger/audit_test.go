@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog_Chain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	a, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	ctx := setTraceID(context.Background(), "trace-1")
+	first, err := a.Record(ctx, "user.delete", "admin", "user:42", map[string]any{"reason": "gdpr"})
+	if err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	if first.Seq != 1 || first.PrevHash != "" || first.TraceID != "trace-1" {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	if _, err := a.Record(ctx, "", "admin", "user:42", nil); !errors.Is(err, ErrAuditField) {
+		t.Fatalf("expected ErrAuditField, got %v", err)
+	}
+	_ = a.Close()
+
+	// Reopening continues the chain
+	a, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("failed to reopen audit log: %v", err)
+	}
+	second, err := a.Record(context.Background(), "role.grant", "admin", "user:7", nil)
+	if err != nil {
+		t.Fatalf("unexpected record error: %v", err)
+	}
+	_ = a.Close()
+	if second.Seq != 2 || second.PrevHash != first.Hash {
+		t.Fatalf("second record not chained: %+v", second)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := VerifyAudit(bytes.NewReader(data)); err != nil || n != 2 {
+		t.Fatalf("VerifyAudit = %d, %v, want 2, nil", n, err)
+	}
+
+	tampered := strings.Replace(string(data), "user:42", "user:43", 1)
+	if n, err := VerifyAudit(strings.NewReader(tampered)); !errors.Is(err, ErrAuditTampered) || n != 0 {
+		t.Fatalf("VerifyAudit of changed record = %d, %v, want 0, ErrAuditTampered", n, err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if n, err := VerifyAudit(strings.NewReader(lines[1])); !errors.Is(err, ErrAuditTampered) || n != 0 {
+		t.Fatalf("VerifyAudit without first record = %d, %v, want 0, ErrAuditTampered", n, err)
+	}
+}
+
+func TestLogger_Audit(t *testing.T) {
+	l := newLogger()
+	if err := l.Audit(context.Background(), "a", "b", "c", nil); !errors.Is(err, ErrAuditDisabled) {
+		t.Fatalf("expected ErrAuditDisabled, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	a, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer a.Close()
+	l.audit = a
+	l.redactor = &Redactor{fields: map[string]struct{}{"password": {}}}
+
+	if err := l.Named("auth").Audit(context.Background(), "password.reset", "admin", "user:1", map[string]any{"password": "hunter2"}); err != nil {
+		t.Fatalf("unexpected audit error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), RedactMask) {
+		t.Fatalf("expected redacted details, got %s", data)
+	}
+}
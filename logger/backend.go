@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"ncobase/common/helper"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Backend writes log records on behalf of the Logger
+//
+// By default the Logger writes through logrus, setting a backend routes the
+// Trace..Panicf methods to it instead, so call sites stay unchanged when a
+// service swaps logrus for another library such as zap. Records pass the level
+// of the Logger (SetLevel and the module levels) and sampling before the
+// backend is asked. Context fields (trace id, version, static and extracted
+// fields) are resolved into typed fields without building a map, so a backend
+// like zap keeps its low-allocation path. Logrus hooks, formatters and the
+// entries returned by WithFields are not involved when a backend is set.
+type Backend interface {
+	// Enabled reports whether records at level are written
+	Enabled(level logrus.Level) bool
+	// Log writes a record, it must not exit or panic for Fatal and Panic
+	// levels, the Logger takes care of that once the record is written. The
+	// fields are reused after Log returns and must not be retained.
+	Log(level logrus.Level, fields []Field, msg string)
+}
+
+// FieldKind is the type of the value carried by a Field
+type FieldKind uint8
+
+// Field kinds
+const (
+	StringKind FieldKind = iota // value in Field.Str
+	IntKind                     // value in Field.Int
+	AnyKind                     // value in Field.Any
+)
+
+// Field is a key/value of a backend record, strings and integers are carried
+// unboxed so backends encode them without reflection
+type Field struct {
+	Key  string
+	Kind FieldKind
+	Str  string
+	Int  int64
+	Any  any
+}
+
+// Value returns the value of the field
+func (f Field) Value() any {
+	switch f.Kind {
+	case StringKind:
+		return f.Str
+	case IntKind:
+		return f.Int
+	default:
+		return f.Any
+	}
+}
+
+// stringField creates a string field
+func stringField(key, value string) Field {
+	return Field{Key: key, Kind: StringKind, Str: value}
+}
+
+// anyField creates a field of v, strings and integers get their typed kind
+func anyField(key string, v any) Field {
+	switch val := v.(type) {
+	case string:
+		return stringField(key, val)
+	case int:
+		return Field{Key: key, Kind: IntKind, Int: int64(val)}
+	case int64:
+		return Field{Key: key, Kind: IntKind, Int: val}
+	default:
+		return Field{Key: key, Kind: AnyKind, Any: v}
+	}
+}
+
+// setField sets f in fields, replacing a field of the same key
+func setField(fields []Field, f Field) []Field {
+	for i := range fields {
+		if fields[i].Key == f.Key {
+			fields[i] = f
+			return fields
+		}
+	}
+	return append(fields, f)
+}
+
+// fieldPool recycles the field slices of backend records
+var fieldPool = sync.Pool{
+	New: func() any {
+		fields := make([]Field, 0, 16)
+		return &fields
+	},
+}
+
+// backendHolder wraps the backend so it can be swapped atomically while logging
+type backendHolder struct {
+	b Backend
+}
+
+// SetBackend routes log records of the logger and its module children to b,
+// nil restores the logrus output, on a child it sets the backend of the root
+func (l *Logger) SetBackend(b Backend) {
+	l.root().backend.Store(&backendHolder{b: b})
+}
+
+// currentBackend returns the backend of the root logger or nil, so module
+// children created by Named follow SetBackend on the root
+func (l *Logger) currentBackend() Backend {
+	if h := l.root().backend.Load(); h != nil {
+		return h.b
+	}
+	return nil
+}
+
+// backendFields appends the fields of a backend record to fields, they are
+// the fields contextFields, withCaller and withStack resolve for logrus
+func (l *Logger) backendFields(ctx context.Context, level logrus.Level, fields []Field) []Field {
+	root := l.root()
+	for k, v := range root.static {
+		fields = append(fields, anyField(k, v))
+	}
+	if l.module != "" {
+		fields = append(fields, stringField(ModuleKey, l.module))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			stringField(traceKey, sc.TraceID().String()),
+			stringField(SpanIDKey, sc.SpanID().String()),
+		)
+	} else if traceID := getTraceID(ctx); traceID != "" {
+		fields = append(fields, stringField(traceKey, traceID))
+	}
+
+	if root.version != "" {
+		fields = append(fields, stringField(VersionKey, root.version))
+	}
+	if id := helper.GetUserID(ctx); id != "" {
+		fields = append(fields, stringField(UserIDKey, id))
+	}
+	if id := helper.GetTenantID(ctx); id != "" {
+		fields = append(fields, stringField(TenantIDKey, id))
+	}
+
+	// Extracted and WithField values replace earlier fields of the same key
+	for _, e := range contextExtractors() {
+		if v, ok := e.fn(ctx); ok {
+			fields = setField(fields, anyField(e.name, v))
+		}
+	}
+	for k, v := range contextFieldValues(ctx) {
+		fields = setField(fields, anyField(k, v))
+	}
+
+	if root.reportCaller {
+		if frame, ok := caller(); ok {
+			fields = setField(fields, stringField(CallerFileKey, shortFile(frame.File)))
+			fields = setField(fields, Field{Key: CallerLineKey, Kind: IntKind, Int: int64(frame.Line)})
+			fields = setField(fields, stringField(CallerFuncKey, frame.Function))
+		}
+	}
+	if opts := root.stack; opts.Enabled && level <= logrus.ErrorLevel {
+		fields = setField(fields, stringField(StackKey, captureStack(opts.Skip, opts.Depth)))
+	}
+	return fields
+}
+
+// LogrusBackend writes records to a logrus logger
+type LogrusBackend struct {
+	logger *logrus.Logger
+}
+
+// NewLogrusBackend creates a backend writing to the given logrus logger
+func NewLogrusBackend(l *logrus.Logger) *LogrusBackend {
+	return &LogrusBackend{logger: l}
+}
+
+// Enabled implements Backend
+func (b *LogrusBackend) Enabled(level logrus.Level) bool {
+	return b.logger.IsLevelEnabled(level)
+}
+
+// Log implements Backend
+func (b *LogrusBackend) Log(level logrus.Level, fields []Field, msg string) {
+	// logrus entries panic on PanicLevel, the Logger does that after Log returns
+	if level == logrus.PanicLevel {
+		defer func() { _ = recover() }()
+	}
+	data := make(logrus.Fields, len(fields))
+	for _, f := range fields {
+		data[f.Key] = f.Value()
+	}
+	b.logger.WithFields(data).Log(level, msg)
+}
+
+// SetBackend routes records of the package-level logger to b
+func SetBackend(b Backend) { StdLogger().SetBackend(b) }
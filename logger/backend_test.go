@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSetBackend_Zap(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newLogger()
+	l.SetVersion("1.2.3")
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	ctx := setTraceID(context.Background(), "trace-1")
+	l.Debugf(ctx, "filtered")
+	l.Infof(ctx, "hello %s", "zap")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "hello zap" || e.Level != zapcore.InfoLevel {
+		t.Errorf("unexpected entry: %s %q", e.Level, e.Message)
+	}
+	fields := e.ContextMap()
+	if fields[traceKey] != "trace-1" || fields[VersionKey] != "1.2.3" {
+		t.Errorf("expected context fields, got %v", fields)
+	}
+}
+
+func TestSetBackend_Named(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newLogger()
+	payments := l.Named("payments")
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	payments.Info(context.Background(), "charged")
+	l.Named("orders").Info(context.Background(), "placed")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if module := entries[0].ContextMap()[ModuleKey]; module != "payments" {
+		t.Errorf("expected module payments, got %v", module)
+	}
+}
+
+func TestSetBackend_Levels(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLogger()
+	l.SetLevel(logrus.WarnLevel)
+	if err := l.setModuleLevels(map[string]string{"payments": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	ctx := context.Background()
+	l.Info(ctx, "filtered by the logger level")
+	l.Named("orders").Info(ctx, "filtered by the root level")
+	l.Named("payments").Debug(ctx, "module debug")
+	l.Warn(ctx, "root warn")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Message != "module debug" || entries[1].Message != "root warn" {
+		t.Errorf("unexpected entries: %q, %q", entries[0].Message, entries[1].Message)
+	}
+}
+
+func TestSetBackend_TypedFields(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newLogger()
+	l.static = logrus.Fields{PIDKey: 42}
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	ctx := WithField(context.Background(), "retries", 3)
+	ctx = WithField(ctx, PIDKey, 7)
+	l.Info(ctx, "typed")
+
+	fields := logs.All()[0].Context
+	types := map[string]zapcore.FieldType{}
+	for _, f := range fields {
+		if _, ok := types[f.Key]; ok {
+			t.Errorf("duplicate field %s", f.Key)
+		}
+		types[f.Key] = f.Type
+	}
+	if types[PIDKey] != zapcore.Int64Type || types["retries"] != zapcore.Int64Type {
+		t.Errorf("expected integer fields, got %v", types)
+	}
+	if pid := logs.All()[0].ContextMap()[PIDKey]; pid != int64(7) {
+		t.Errorf("expected the context pid to replace the static one, got %v", pid)
+	}
+}
+
+func TestSetBackend_Panic(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLogger()
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Panic to panic")
+		}
+		if logs.Len() != 1 {
+			t.Errorf("expected the record to be written before panicking, got %d", logs.Len())
+		}
+	}()
+	l.Panic(context.Background(), "boom")
+}
+
+func TestSetBackend_Reset(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLogger()
+	l.SetLevel(logrus.InfoLevel)
+	l.SetOutput(io.Discard)
+	l.SetBackend(NewZapBackend(zap.New(core)))
+	l.SetBackend(nil)
+
+	l.Info(context.Background(), "to logrus")
+	if logs.Len() != 0 {
+		t.Errorf("expected no records in zap after reset, got %d", logs.Len())
+	}
+}
+
+func BenchmarkZapBackend(b *testing.B) {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	l := newLogger()
+	l.SetVersion("1.2.3")
+	l.SetBackend(NewZapBackend(zap.New(zapcore.NewCore(enc, zapcore.AddSync(io.Discard), zapcore.InfoLevel))))
+	ctx := setTraceID(context.Background(), "trace-1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info(ctx, "hello")
+	}
+}
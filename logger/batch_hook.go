@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrHookBufferFull is returned by Fire when an entry is dropped because the buffer is full
+	ErrHookBufferFull = errors.New("log hook buffer full, entry dropped")
+	// ErrHookClosed is returned by Fire after the hook was closed
+	ErrHookClosed = errors.New("log hook closed")
+)
+
+// BatchHook queues entries and sends them in batches from a background worker,
+// so logging calls never wait on the network
+//
+// A batch is sent once BatchSize entries are pending or FlushInterval has
+// elapsed. When the buffer is full entries are dropped, or the caller blocks
+// if BlockOnFull is set. Close sends the remaining entries. With a spool,
+// batches that fail are kept on disk and re-sent periodically, including
+// after a restart.
+type BatchHook struct {
+	send     func(docs []any) error
+	doc      func(entry *logrus.Entry) any // builds the queued document, entry data by default
+	paused   *atomic.Bool
+	entries  chan any
+	size     int
+	interval time.Duration
+	block    bool
+	onError  func(error)
+	spool    *Spool        // keeps failed batches for retry, nil loses them
+	retry    time.Duration // interval between spool drains
+	dropped  atomic.Int64
+	failed   atomic.Int64
+	done     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+// NewBatchHook creates a batch hook and starts its worker, send receives
+// the entry data of each batch
+func NewBatchHook(c *config.LoggerSearch, send func(docs []any) error) *BatchHook {
+	h := newBatchHook(c, send)
+	h.start()
+	return h
+}
+
+// newBatchHook creates a batch hook without starting its worker
+func newBatchHook(c *config.LoggerSearch, send func(docs []any) error) *BatchHook {
+	return &BatchHook{
+		send:     send,
+		entries:  make(chan any, c.BufferSize),
+		size:     c.BatchSize,
+		interval: c.FlushInterval,
+		block:    c.BlockOnFull,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// start starts the worker, fields must not change afterwards
+func (h *BatchHook) start() {
+	go h.run()
+}
+
+// Levels returns all log levels
+func (h *BatchHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire queues the entry data
+func (h *BatchHook) Fire(entry *logrus.Entry) error {
+	if h.paused != nil && h.paused.Load() {
+		return nil
+	}
+
+	select {
+	case <-h.done:
+		return ErrHookClosed
+	default:
+	}
+
+	var doc any
+	if h.doc != nil {
+		doc = h.doc(entry)
+	} else {
+		doc = util.CopyMap(entry.Data)
+	}
+	if h.block {
+		select {
+		case h.entries <- doc:
+			return nil
+		case <-h.done:
+			return ErrHookClosed
+		}
+	}
+
+	select {
+	case h.entries <- doc:
+		return nil
+	default:
+		h.dropped.Add(1)
+		return ErrHookBufferFull
+	}
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (h *BatchHook) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Failed returns the number of entries in batches that could not be sent nor spooled
+func (h *BatchHook) Failed() int64 {
+	return h.failed.Load()
+}
+
+// Close stops the worker after sending the pending entries
+func (h *BatchHook) Close() {
+	h.once.Do(func() {
+		close(h.done)
+	})
+	<-h.stopped
+}
+
+// run collects entries into batches until the hook is closed
+func (h *BatchHook) run() {
+	defer close(h.stopped)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	batch := make([]any, 0, h.size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := h.send(batch); err != nil {
+			if h.spool == nil || h.spool.Append(batch) != nil {
+				h.failed.Add(int64(len(batch)))
+			}
+			if h.onError != nil {
+				h.onError(err)
+			}
+		}
+		batch = make([]any, 0, h.size)
+	}
+
+	var retry <-chan time.Time
+	if h.spool != nil {
+		retryTicker := time.NewTicker(h.retry)
+		defer retryTicker.Stop()
+		retry = retryTicker.C
+	}
+
+	for {
+		select {
+		case doc := <-h.entries:
+			batch = append(batch, doc)
+			if len(batch) >= h.size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-retry:
+			if err := h.spool.Drain(h.send); err != nil && h.onError != nil {
+				h.onError(err)
+			}
+		case <-h.done:
+			for {
+				select {
+				case doc := <-h.entries:
+					batch = append(batch, doc)
+					if len(batch) >= h.size {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// batchRecorder records the batches sent by a hook
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]any
+	release chan struct{} // when set, send blocks until it is closed
+}
+
+func (r *batchRecorder) send(docs []any) error {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, docs)
+	return nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sizes []int
+	for _, b := range r.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func fireN(t *testing.T, h *BatchHook, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		entry := logrus.NewEntry(logrus.New()).WithField("n", i)
+		if err := h.Fire(entry); err != nil {
+			t.Fatalf("unexpected fire error: %v", err)
+		}
+	}
+}
+
+func TestBatchHook_FlushBySizeAndClose(t *testing.T) {
+	rec := &batchRecorder{}
+	h := NewBatchHook(&config.LoggerSearch{BatchSize: 3, BufferSize: 10, FlushInterval: time.Hour}, rec.send)
+
+	fireN(t, h, 7)
+	h.Close()
+
+	sizes := rec.sizes()
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Errorf("expected batches of 3, 3 and 1, got %v", sizes)
+	}
+	if err := h.Fire(logrus.NewEntry(logrus.New())); !errors.Is(err, ErrHookClosed) {
+		t.Errorf("expected ErrHookClosed after close, got %v", err)
+	}
+}
+
+func TestBatchHook_FlushByInterval(t *testing.T) {
+	rec := &batchRecorder{}
+	h := NewBatchHook(&config.LoggerSearch{BatchSize: 100, BufferSize: 100, FlushInterval: 10 * time.Millisecond}, rec.send)
+	defer h.Close()
+
+	fireN(t, h, 2)
+
+	deadline := time.Now().Add(time.Second)
+	for len(rec.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("expected one batch of 2 after the interval, got %v", sizes)
+	}
+}
+
+func TestBatchHook_DropWhenFull(t *testing.T) {
+	rec := &batchRecorder{release: make(chan struct{})}
+	h := NewBatchHook(&config.LoggerSearch{BatchSize: 1, BufferSize: 2, FlushInterval: time.Hour}, rec.send)
+
+	// The worker holds one entry in the blocked send, the buffer takes two more
+	fireN(t, h, 1)
+	time.Sleep(20 * time.Millisecond)
+	fireN(t, h, 2)
+
+	err := h.Fire(logrus.NewEntry(logrus.New()))
+	if !errors.Is(err, ErrHookBufferFull) {
+		t.Fatalf("expected ErrHookBufferFull, got %v", err)
+	}
+	if h.Dropped() != 1 {
+		t.Errorf("expected 1 dropped entry, got %d", h.Dropped())
+	}
+
+	close(rec.release)
+	h.Close()
+	if sizes := rec.sizes(); len(sizes) != 3 {
+		t.Errorf("expected the 3 buffered entries to be sent, got %v", sizes)
+	}
+}
+
+func TestBatchHook_BlockOnFull(t *testing.T) {
+	rec := &batchRecorder{release: make(chan struct{})}
+	h := NewBatchHook(&config.LoggerSearch{BatchSize: 1, BufferSize: 1, FlushInterval: time.Hour, BlockOnFull: true}, rec.send)
+
+	fireN(t, h, 1)
+	time.Sleep(20 * time.Millisecond)
+	fireN(t, h, 1)
+
+	fired := make(chan error, 1)
+	go func() { fired <- h.Fire(logrus.NewEntry(logrus.New())) }()
+
+	select {
+	case <-fired:
+		t.Fatal("expected Fire to block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(rec.release)
+	if err := <-fired; err != nil {
+		t.Errorf("unexpected fire error: %v", err)
+	}
+	h.Close()
+	if h.Dropped() != 0 {
+		t.Errorf("expected no dropped entries with backpressure, got %d", h.Dropped())
+	}
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default file buffer settings
+const (
+	DefaultFlushInterval = time.Second
+	DefaultFlushLevel    = logrus.ErrorLevel
+)
+
+// BufferedWriter buffers writes to w so entries don't cost a syscall each
+//
+// The buffer is written out when full, by Flush, and right after the write
+// following FlushNext, which flushFormatter calls for severe entries so they
+// reach the file without waiting for the next periodic flush.
+type BufferedWriter struct {
+	mu        sync.Mutex
+	buf       *bufio.Writer
+	flushNext bool
+}
+
+// NewBufferedWriter returns a writer buffering up to size bytes for w
+func NewBufferedWriter(w io.Writer, size int) *BufferedWriter {
+	return &BufferedWriter{buf: bufio.NewWriterSize(w, size)}
+}
+
+// Write buffers p, flushing when FlushNext was called
+func (w *BufferedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n, err := w.buf.Write(p)
+	if err == nil && w.flushNext {
+		w.flushNext = false
+		err = w.buf.Flush()
+	}
+	return n, err
+}
+
+// FlushNext makes the next write flush the buffer
+func (w *BufferedWriter) FlushNext() {
+	w.mu.Lock()
+	w.flushNext = true
+	w.mu.Unlock()
+}
+
+// Flush writes the buffered data out
+func (w *BufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// periodicFlush flushes the buffer every interval until done is closed
+func (w *BufferedWriter) periodicFlush(interval time.Duration, done <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				onError(err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// flushFormatter marks the buffered writer to flush after entries at level
+// or above, logrus formats and writes an entry under the same lock so the
+// flush follows the very entry that was formatted
+type flushFormatter struct {
+	logrus.Formatter
+	w     *BufferedWriter
+	level logrus.Level
+}
+
+// Format formats the entry with the wrapped formatter
+func (f *flushFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err == nil && entry.Level <= f.level {
+		f.w.FlushNext()
+	}
+	return b, err
+}
+
+// bufferLogFile buffers the writes to the log file, flushing them every
+// interval and after entries at the flush level or above
+func (l *Logger) bufferLogFile(c *config.LoggerFileBuffer, done <-chan struct{}) error {
+	level, err := l.startFileBuffer(c, done)
+	if err != nil {
+		return err
+	}
+	l.SetOutput(l.fileBuf)
+	l.SetFormatter(&flushFormatter{Formatter: l.Formatter, w: l.fileBuf, level: level})
+	return nil
+}
+
+// startFileBuffer creates the buffer of the log file and starts flushing it
+// every interval, it returns the level of the entries flushed immediately
+func (l *Logger) startFileBuffer(c *config.LoggerFileBuffer, done <-chan struct{}) (logrus.Level, error) {
+	level := DefaultFlushLevel
+	if c.FlushLevel != "" {
+		var err error
+		if level, err = logrus.ParseLevel(c.FlushLevel); err != nil {
+			return 0, err
+		}
+	}
+	interval := c.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	l.fileBuf = NewBufferedWriter(l.logFile, c.SizeKB*1024)
+	go l.fileBuf.periodicFlush(interval, done, func(err error) {
+		l.Logger.Errorf("Error flushing log file: %v", err)
+	})
+	return level, nil
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBufferedWriter_FlushLevel(t *testing.T) {
+	var out bytes.Buffer
+	w := NewBufferedWriter(&out, 4096)
+
+	l := logrus.New()
+	l.SetOutput(w)
+	l.SetFormatter(&flushFormatter{Formatter: &logrus.TextFormatter{DisableTimestamp: true}, w: w, level: logrus.ErrorLevel})
+
+	l.Info("buffered")
+	if out.Len() != 0 {
+		t.Fatalf("expected info entry to stay buffered, got %q", out.String())
+	}
+	l.Error("failed")
+	if !strings.Contains(out.String(), "buffered") || !strings.Contains(out.String(), "failed") {
+		t.Fatalf("expected error entry to flush the buffer, got %q", out.String())
+	}
+
+	out.Reset()
+	l.Warn("pending")
+	if out.Len() != 0 {
+		t.Fatalf("expected warn entry to stay buffered, got %q", out.String())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if !strings.Contains(out.String(), "pending") {
+		t.Fatalf("expected flushed entry, got %q", out.String())
+	}
+}
+
+func TestBufferedWriter_PeriodicFlush(t *testing.T) {
+	var out lockedBuffer
+	w := NewBufferedWriter(&out, 4096)
+	done := make(chan struct{})
+	defer close(done)
+	go w.periodicFlush(10*time.Millisecond, done, func(err error) { t.Errorf("unexpected flush error: %v", err) })
+
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(out.String(), "line") {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the buffer to be flushed periodically")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// lockedBuffer is a buffer safe for the flushing goroutine
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"path"
+	"runtime"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Caller field names
+const (
+	CallerFileKey = "file"
+	CallerLineKey = "line"
+	CallerFuncKey = "func"
+)
+
+// maxCallerDepth bounds the frames searched for the logging call site
+const maxCallerDepth = 16
+
+// callerFrames caches the frames of a program counter, symbolizing a PC is
+// far more expensive than runtime.Callers and call sites are few
+var callerFrames sync.Map // uintptr -> []runtime.Frame
+
+// withCaller adds the source location of the logging call when enabled
+func (l *Logger) withCaller(fields logrus.Fields) logrus.Fields {
+	if !l.root().reportCaller {
+		return fields
+	}
+	if frame, ok := caller(); ok {
+		fields[CallerFileKey] = shortFile(frame.File)
+		fields[CallerLineKey] = frame.Line
+		fields[CallerFuncKey] = frame.Function
+	}
+	return fields
+}
+
+// caller returns the first frame outside of the logging machinery
+func caller() (runtime.Frame, bool) {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		for _, frame := range framesOf(pc) {
+			if !internalFrame(frame) {
+				return frame, true
+			}
+		}
+	}
+	return runtime.Frame{}, false
+}
+
+// framesOf returns the cached frames of pc, more than one when calls are inlined
+func framesOf(pc uintptr) []runtime.Frame {
+	if v, ok := callerFrames.Load(pc); ok {
+		return v.([]runtime.Frame)
+	}
+	var out []runtime.Frame
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := frames.Next()
+		out = append(out, frame)
+		if !more {
+			break
+		}
+	}
+	callerFrames.Store(pc, out)
+	return out
+}
+
+// shortFile trims the path to the package directory and file name
+func shortFile(file string) string {
+	dir, name := path.Split(file)
+	return path.Join(path.Base(dir), name)
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWithCaller(t *testing.T) {
+	l := &Logger{Logger: logrus.New(), reportCaller: true}
+
+	for i := 0; i < 2; i++ {
+		fields := l.withCaller(logrus.Fields{})
+		if fields[CallerFileKey] != "logger/caller_test.go" {
+			t.Errorf("expected caller file, got %v", fields[CallerFileKey])
+		}
+		if line, _ := fields[CallerLineKey].(int); line == 0 {
+			t.Errorf("expected caller line, got %v", fields[CallerLineKey])
+		}
+		if fn, _ := fields[CallerFuncKey].(string); !strings.HasSuffix(fn, ".TestWithCaller") {
+			t.Errorf("expected caller function, got %v", fields[CallerFuncKey])
+		}
+	}
+
+	l.reportCaller = false
+	if fields := l.withCaller(logrus.Fields{}); len(fields) != 0 {
+		t.Errorf("expected no caller fields when disabled, got %v", fields)
+	}
+}
@@ -0,0 +1,215 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/sirupsen/logrus"
+)
+
+// PutLogEvents limits
+const (
+	cloudWatchMaxEvents    = 10000
+	cloudWatchMaxBytes     = 1048576
+	cloudWatchEventBytes   = 26 // overhead counted for every event
+	cloudWatchMaxEventSize = 256*1024 - cloudWatchEventBytes
+	cloudWatchMaxSpan      = 24 * time.Hour
+	cloudWatchTimeout      = 10 * time.Second
+)
+
+// cloudWatchAPI is the part of the CloudWatch Logs client used by the hook
+type cloudWatchAPI interface {
+	PutLogEventsWithContext(aws.Context, *cloudwatchlogs.PutLogEventsInput, ...request.Option) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogStreamWithContext(aws.Context, *cloudwatchlogs.CreateLogStreamInput, ...request.Option) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+// cloudWatchEvent is a queued log event
+type cloudWatchEvent struct {
+	ts      int64 // milliseconds since epoch
+	message string
+}
+
+// NewCloudWatchHook creates a batch hook putting entries to CloudWatch Logs
+//
+// Credentials and region are resolved by the AWS SDK default chain, i.e. the
+// environment, IRSA web identity tokens, ECS task roles and instance profiles,
+// so no sidecar is needed on ECS or Lambda. Each event is the entry as JSON.
+func NewCloudWatchHook(c *config.LoggerCloudWatch, batch *config.LoggerSearch) (*BatchHook, error) {
+	h, err := newCloudWatchHook(c, batch)
+	if err != nil {
+		return nil, err
+	}
+	h.start()
+	return h, nil
+}
+
+// newCloudWatchHook creates a CloudWatch batch hook without starting its worker
+func newCloudWatchHook(c *config.LoggerCloudWatch, batch *config.LoggerSearch) (*BatchHook, error) {
+	cfg := aws.NewConfig()
+	if c.Region != "" {
+		cfg = cfg.WithRegion(c.Region)
+	}
+	if c.Endpoint != "" {
+		cfg = cfg.WithEndpoint(c.Endpoint)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+	return newCloudWatchBatchHook(cloudwatchlogs.New(sess), c, batch), nil
+}
+
+// newCloudWatchBatchHook creates the batch hook sending with api
+func newCloudWatchBatchHook(api cloudWatchAPI, c *config.LoggerCloudWatch, batch *config.LoggerSearch) *BatchHook {
+	if batch == nil {
+		batch = &defaultBatchConfig
+	}
+	s := &cloudWatchSender{api: api, group: c.LogGroup, stream: c.LogStream}
+	h := newBatchHook(batch, s.send)
+	h.doc = cloudWatchDoc
+	return h
+}
+
+// cloudWatchFormatter formats the events
+var cloudWatchFormatter = &DurationFormatter{Formatter: &logrus.JSONFormatter{}}
+
+// cloudWatchDoc converts an entry to an event, truncating oversized messages
+func cloudWatchDoc(entry *logrus.Entry) any {
+	// Format into a fresh buffer, the entry buffer is owned by the logger
+	e := *entry
+	e.Buffer = nil
+	line, err := cloudWatchFormatter.Format(&e)
+	message := strings.TrimSuffix(string(line), "\n")
+	if err != nil {
+		message = entry.Message
+	}
+	if len(message) > cloudWatchMaxEventSize {
+		message = message[:cloudWatchMaxEventSize]
+	}
+	return cloudWatchEvent{ts: entry.Time.UnixMilli(), message: message}
+}
+
+// cloudWatchSender puts batches to a log stream, it is only used by the
+// batch hook worker so the sequence token needs no locking
+type cloudWatchSender struct {
+	api     cloudWatchAPI
+	group   string
+	stream  string
+	token   *string
+	created bool
+}
+
+// send puts the events split into requests within the PutLogEvents limits
+func (s *cloudWatchSender) send(docs []any) error {
+	events := make([]cloudWatchEvent, 0, len(docs))
+	for _, d := range docs {
+		if e, ok := d.(cloudWatchEvent); ok {
+			events = append(events, e)
+		}
+	}
+	for _, batch := range cloudWatchBatches(events) {
+		if err := s.put(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put sends a batch, creating the stream when missing and retrying once with
+// the expected sequence token when the token is stale
+func (s *cloudWatchSender) put(batch []cloudWatchEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cloudWatchTimeout)
+	defer cancel()
+
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+		LogEvents:     make([]*cloudwatchlogs.InputLogEvent, len(batch)),
+	}
+	for i, e := range batch {
+		input.LogEvents[i] = &cloudwatchlogs.InputLogEvent{
+			Timestamp: aws.Int64(e.ts),
+			Message:   aws.String(e.message),
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		input.SequenceToken = s.token
+		out, err := s.api.PutLogEventsWithContext(ctx, input)
+		if err == nil {
+			s.token = out.NextSequenceToken
+			return nil
+		}
+		if attempt > 0 {
+			return fmt.Errorf("failed to put cloudwatch log events: %w", err)
+		}
+
+		var stale *cloudwatchlogs.InvalidSequenceTokenException
+		var accepted *cloudwatchlogs.DataAlreadyAcceptedException
+		var missing *cloudwatchlogs.ResourceNotFoundException
+		switch {
+		case errors.As(err, &stale):
+			s.token = stale.ExpectedSequenceToken
+		case errors.As(err, &accepted):
+			s.token = accepted.ExpectedSequenceToken
+			return nil
+		case errors.As(err, &missing) && !s.created:
+			if err := s.createStream(ctx); err != nil {
+				return err
+			}
+			s.token = nil
+		default:
+			return fmt.Errorf("failed to put cloudwatch log events: %w", err)
+		}
+	}
+}
+
+// createStream creates the log stream, the log group must exist
+func (s *cloudWatchSender) createStream(ctx context.Context) error {
+	_, err := s.api.CreateLogStreamWithContext(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+	})
+	var exists *cloudwatchlogs.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("failed to create cloudwatch log stream: %w", err)
+	}
+	s.created = true
+	return nil
+}
+
+// cloudWatchBatches sorts the events by time and splits them into batches of
+// at most 10,000 events and 1 MB spanning less than 24 hours
+func cloudWatchBatches(events []cloudWatchEvent) [][]cloudWatchEvent {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ts < events[j].ts })
+
+	var batches [][]cloudWatchEvent
+	start, size := 0, 0
+	for i, e := range events {
+		n := len(e.message) + cloudWatchEventBytes
+		if i > start && (i-start == cloudWatchMaxEvents ||
+			size+n > cloudWatchMaxBytes ||
+			e.ts-events[start].ts >= cloudWatchMaxSpan.Milliseconds()) {
+			batches = append(batches, events[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(events) {
+		batches = append(batches, events[start:])
+	}
+	return batches
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeCloudWatch fails the first put with err and records the accepted events
+type fakeCloudWatch struct {
+	err     error
+	tokens  []*string
+	events  int
+	created int
+}
+
+func (f *fakeCloudWatch) PutLogEventsWithContext(_ aws.Context, in *cloudwatchlogs.PutLogEventsInput, _ ...request.Option) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	f.tokens = append(f.tokens, in.SequenceToken)
+	if err := f.err; err != nil {
+		f.err = nil
+		return nil, err
+	}
+	f.events += len(in.LogEvents)
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: aws.String("next")}, nil
+}
+
+func (f *fakeCloudWatch) CreateLogStreamWithContext(aws.Context, *cloudwatchlogs.CreateLogStreamInput, ...request.Option) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	f.created++
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func TestCloudWatchBatches(t *testing.T) {
+	base := time.Now().UnixMilli()
+	events := []cloudWatchEvent{
+		{ts: base + 2, message: "c"},
+		{ts: base, message: "a"},
+		{ts: base + 1, message: "b"},
+	}
+	batches := cloudWatchBatches(events)
+	if len(batches) != 1 || batches[0][0].message != "a" || batches[0][2].message != "c" {
+		t.Fatalf("expected one batch sorted by time, got %v", batches)
+	}
+
+	events = make([]cloudWatchEvent, cloudWatchMaxEvents+1)
+	if batches := cloudWatchBatches(events); len(batches) != 2 || len(batches[0]) != cloudWatchMaxEvents {
+		t.Fatalf("expected split at %d events, got %d batches", cloudWatchMaxEvents, len(batches))
+	}
+
+	big := strings.Repeat("x", cloudWatchMaxEventSize)
+	events = []cloudWatchEvent{{message: big}, {message: big}, {message: big}, {message: big}, {message: big}}
+	for _, b := range cloudWatchBatches(events) {
+		size := 0
+		for _, e := range b {
+			size += len(e.message) + cloudWatchEventBytes
+		}
+		if size > cloudWatchMaxBytes {
+			t.Fatalf("batch of %d bytes exceeds the limit", size)
+		}
+	}
+
+	events = []cloudWatchEvent{{ts: base}, {ts: base + cloudWatchMaxSpan.Milliseconds()}}
+	if batches := cloudWatchBatches(events); len(batches) != 2 {
+		t.Fatalf("expected split at 24 hours, got %d batches", len(batches))
+	}
+}
+
+func TestCloudWatchSender(t *testing.T) {
+	api := &fakeCloudWatch{err: &cloudwatchlogs.ResourceNotFoundException{Message_: aws.String("stream missing")}}
+	s := &cloudWatchSender{api: api, group: "app", stream: "host-1"}
+	if err := s.send([]any{cloudWatchEvent{ts: 1, message: "a"}}); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+	if api.created != 1 || api.events != 1 {
+		t.Fatalf("expected stream created and event put, got %+v", api)
+	}
+
+	api.err = &cloudwatchlogs.InvalidSequenceTokenException{ExpectedSequenceToken: aws.String("expected")}
+	if err := s.send([]any{cloudWatchEvent{ts: 2, message: "b"}}); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+	if got := aws.StringValue(api.tokens[len(api.tokens)-1]); got != "expected" {
+		t.Fatalf("expected retry with the expected token, got %q", got)
+	}
+	if aws.StringValue(s.token) != "next" {
+		t.Fatalf("expected next token kept, got %q", aws.StringValue(s.token))
+	}
+}
+
+func TestCloudWatchDoc(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithField("order", 7)
+	entry.Message = strings.Repeat("x", cloudWatchMaxEventSize+10)
+	entry.Time = time.UnixMilli(1700000000000)
+	e := cloudWatchDoc(entry).(cloudWatchEvent)
+	if e.ts != 1700000000000 || len(e.message) != cloudWatchMaxEventSize {
+		t.Fatalf("unexpected event ts %d, size %d", e.ts, len(e.message))
+	}
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ANSI colors of the console levels
+const (
+	colorRed    = 31
+	colorYellow = 33
+	colorBlue   = 36
+	colorGray   = 37
+)
+
+// consoleMessageWidth is the width messages are padded to so fields line up
+const consoleMessageWidth = 44
+
+// ConsoleFormatter formats entries for reading in a terminal during development
+//
+// Lines start with a short timestamp and a colored level, fields follow the
+// message sorted by key and aligned, nested values are pretty printed on the
+// lines below. Colors are disabled when the output is not a terminal.
+type ConsoleFormatter struct {
+	TimestampFormat string // default 15:04:05.000
+	DisableColors   bool
+	ForceColors     bool
+
+	once  sync.Once
+	color bool
+}
+
+// Format renders the entry as a console line
+func (f *ConsoleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	f.once.Do(func() {
+		f.color = f.ForceColors || (!f.DisableColors && entry.Logger != nil && isTerminal(entry.Logger.Out))
+	})
+
+	layout := f.TimestampFormat
+	if layout == "" {
+		layout = "15:04:05.000"
+	}
+
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+
+	level := strings.ToUpper(entry.Level.String())
+	if len(level) > 4 {
+		level = level[:4]
+	}
+	b.WriteString(entry.Time.Format(layout))
+	b.WriteByte(' ')
+	if f.color {
+		fmt.Fprintf(b, "\x1b[%dm%-4s\x1b[0m", levelColor(entry.Level), level)
+	} else {
+		fmt.Fprintf(b, "%-4s", level)
+	}
+	b.WriteByte(' ')
+	b.WriteString(entry.Message)
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var nested []string
+	padded := false
+	for _, k := range keys {
+		v := entry.Data[k]
+		if isNested(v) {
+			nested = append(nested, k)
+			continue
+		}
+		if !padded {
+			if pad := consoleMessageWidth - len(entry.Message); pad > 0 {
+				b.WriteString(strings.Repeat(" ", pad))
+			}
+			padded = true
+		}
+		b.WriteByte(' ')
+		f.writeKey(b, entry.Level, k)
+		b.WriteByte('=')
+		fmt.Fprint(b, consoleValue(v))
+	}
+	b.WriteByte('\n')
+
+	for _, k := range nested {
+		pretty, err := json.MarshalIndent(entry.Data[k], "    ", "  ")
+		if err != nil {
+			pretty = []byte(fmt.Sprintf("%+v", entry.Data[k]))
+		}
+		b.WriteString("    ")
+		f.writeKey(b, entry.Level, k)
+		b.WriteString(": ")
+		b.Write(pretty)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// writeKey writes a field key, colored like the level
+func (f *ConsoleFormatter) writeKey(b *bytes.Buffer, level logrus.Level, key string) {
+	if f.color {
+		fmt.Fprintf(b, "\x1b[%dm%s\x1b[0m", levelColor(level), key)
+		return
+	}
+	b.WriteString(key)
+}
+
+// consoleValue returns the inline representation of a field value
+func consoleValue(v any) any {
+	switch v := v.(type) {
+	case error:
+		return fmt.Sprintf("%q", v.Error())
+	case string:
+		if strings.ContainsAny(v, " \t\n\"=") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isNested reports whether the value is printed on its own lines
+func isNested(v any) bool {
+	if v == nil {
+		return false
+	}
+	if _, ok := v.(error); ok {
+		return false
+	}
+	if _, ok := v.(fmt.Stringer); ok {
+		return false
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// levelColor returns the ANSI color of the level
+func levelColor(level logrus.Level) int {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return colorGray
+	case logrus.WarnLevel:
+		return colorYellow
+	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
+		return colorRed
+	default:
+		return colorBlue
+	}
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w any) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
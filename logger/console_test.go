@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestConsoleFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&ConsoleFormatter{})
+
+	entry := l.WithFields(logrus.Fields{
+		"user":    "alice",
+		"err":     errors.New("not found"),
+		"request": map[string]any{"path": "/users", "query": "a=1"},
+	})
+	entry.Time = time.Date(2024, 5, 1, 8, 30, 15, 123e6, time.UTC)
+	entry.Warn("lookup failed")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := "08:30:15.123 WARN lookup failed" + strings.Repeat(" ", consoleMessageWidth-len("lookup failed")) + ` err="not found" user=alice`
+	if lines[0] != want {
+		t.Errorf("unexpected first line\n got %q\nwant %q", lines[0], want)
+	}
+	if !strings.HasPrefix(lines[1], "    request: {") || !strings.Contains(buf.String(), `"path": "/users"`) {
+		t.Errorf("expected nested field pretty printed, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Error("colors must be disabled when the output is not a terminal")
+	}
+}
+
+func TestConsoleFormatter_ForceColors(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&ConsoleFormatter{ForceColors: true})
+
+	l.Error("boom")
+	if !strings.Contains(buf.String(), "\x1b[31mERRO\x1b[0m") {
+		t.Errorf("expected red level, got %q", buf.String())
+	}
+}
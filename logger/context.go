@@ -3,10 +3,70 @@ package logger
 import (
 	"context"
 	"ncobase/common/helper"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
 )
 
 var traceKey = helper.TraceIDKey
 
+// Request identity field names
+const (
+	UserIDKey   = "user_id"
+	TenantIDKey = "tenant_id"
+)
+
+// fieldsKey is the context key of fields added with WithField
+type fieldsKey struct{}
+
+// ContextExtractor extracts a field value from the context, reporting whether it is present
+type ContextExtractor func(ctx context.Context) (any, bool)
+
+// namedExtractor is a registered context extractor
+type namedExtractor struct {
+	name string
+	fn   ContextExtractor
+}
+
+var (
+	// extractors is replaced on write, so log calls read it without locking
+	extractors   atomic.Pointer[[]namedExtractor]
+	extractorsMu sync.Mutex
+)
+
+// RegisterContextExtractor registers an extractor that populates the field
+// name on every entry whose context carries a value, registering the same
+// name again replaces the previous extractor. It is safe to call from init.
+//
+// Extractors run on every log call, so they must be cheap: a context lookup,
+// never I/O or locking.
+func RegisterContextExtractor(name string, fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+
+	var next []namedExtractor
+	if current := extractors.Load(); current != nil {
+		for _, e := range *current {
+			if e.name != name {
+				next = append(next, e)
+			}
+		}
+	}
+	if fn != nil {
+		next = append(next, namedExtractor{name: name, fn: fn})
+	}
+	extractors.Store(&next)
+}
+
+// contextExtractors returns the registered extractors
+func contextExtractors() []namedExtractor {
+	if current := extractors.Load(); current != nil {
+		return *current
+	}
+	return nil
+}
+
 // getTraceID gets a trace ID from the context.
 func getTraceID(ctx context.Context) string {
 	return helper.GetTraceID(ctx)
@@ -17,7 +77,52 @@ func setTraceID(ctx context.Context, traceID string) context.Context {
 	return helper.SetTraceID(ctx, traceID)
 }
 
-// EnsureTraceID ensures that a trace ID exists in the context.
+// EnsureTraceID ensures that a trace ID exists in the context, new ones are
+// W3C trace ids so they can be propagated in traceparent and B3 headers
 func EnsureTraceID(ctx context.Context) (context.Context, string) {
-	return helper.EnsureTraceID(ctx)
+	if traceID := getTraceID(ctx); traceID != "" {
+		return ctx, traceID
+	}
+	traceID := NewTraceParent().TraceID
+	return setTraceID(ctx, traceID), traceID
+}
+
+// WithUserID stores the user id in the context, it is logged as user_id
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return helper.SetUserID(ctx, userID)
+}
+
+// WithTenantID stores the tenant id in the context, it is logged as tenant_id
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return helper.SetTenantID(ctx, tenantID)
+}
+
+// WithField returns a context carrying the field, every entry logged with
+// the context includes it, later values replace earlier ones for the same key
+func WithField(ctx context.Context, key string, value any) context.Context {
+	return ContextWithFields(ctx, logrus.Fields{key: value})
+}
+
+// ContextWithFields returns a context carrying the fields, so middleware can
+// attach request metadata once and every entry logged downstream with the
+// context includes it, later values replace earlier ones for the same key
+func ContextWithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	parent := contextFieldValues(ctx)
+	merged := make(logrus.Fields, len(parent)+len(fields))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// contextFieldValues returns the fields added with WithField, it must not be modified
+func contextFieldValues(ctx context.Context) logrus.Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
 }
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type tenantKey struct{}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	RegisterContextExtractor("tenant_id", func(ctx context.Context) (any, bool) {
+		v, ok := ctx.Value(tenantKey{}).(string)
+		return v, ok
+	})
+	defer RegisterContextExtractor("tenant_id", nil)
+
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	l.Info(context.WithValue(context.Background(), tenantKey{}, "acme"), "with tenant")
+	l.Info(context.Background(), "without tenant")
+
+	dec := json.NewDecoder(&buf)
+	var first, second map[string]any
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+
+	if first["tenant_id"] != "acme" {
+		t.Errorf("expected tenant_id acme, got %v", first["tenant_id"])
+	}
+	if _, ok := second["tenant_id"]; ok {
+		t.Errorf("tenant_id must be omitted when absent, got %v", second["tenant_id"])
+	}
+}
+
+func TestWithField(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := WithUserID(context.Background(), "u1")
+	ctx = WithTenantID(ctx, "t1")
+	ctx = WithField(ctx, "request", "r1")
+	child := WithField(ctx, "request", "r2")
+
+	l.Info(ctx, "parent")
+	l.Info(child, "child")
+
+	dec := json.NewDecoder(&buf)
+	var parent, overridden map[string]any
+	if err := dec.Decode(&parent); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if err := dec.Decode(&overridden); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+
+	if parent[UserIDKey] != "u1" || parent[TenantIDKey] != "t1" {
+		t.Errorf("expected identity fields, got %v", parent)
+	}
+	if parent["request"] != "r1" {
+		t.Errorf("parent context must keep its own value, got %v", parent["request"])
+	}
+	if overridden["request"] != "r2" || overridden[UserIDKey] != "u1" {
+		t.Errorf("expected child to override request and inherit identity, got %v", overridden)
+	}
+}
+
+func TestContextWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := ContextWithFields(context.Background(), logrus.Fields{"request": "r1", "route": "/orders"})
+	if same := ContextWithFields(ctx, nil); same != ctx {
+		t.Error("expected the context returned as is without fields")
+	}
+	child := ContextWithFields(ctx, logrus.Fields{"route": "/orders/:id", "order": 7})
+
+	l.Errorf(child, "order %d failed", 7)
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	if entry["request"] != "r1" || entry["route"] != "/orders/:id" || entry["order"] != float64(7) {
+		t.Errorf("expected cascaded fields, got %v", entry)
+	}
+	if contextFieldValues(ctx)["route"] != "/orders" {
+		t.Error("parent context fields must not change")
+	}
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RepeatCountKey is the field of the entry summing up suppressed duplicates
+const RepeatCountKey = "repeat_count"
+
+// dedupEntry is the first entry of a key in the current window
+type dedupEntry struct {
+	level  logrus.Level
+	msg    string
+	fields logrus.Fields // fields of the last duplicate
+	start  time.Time
+	count  int // duplicates suppressed since start
+}
+
+// Deduper collapses bursts of identical entries
+//
+// The first entry of a level, message and key fields is logged, duplicates
+// within the window are suppressed, then a single entry with repeat_count
+// reports how many were, so a tight retry loop logs two lines per window
+// instead of millions. Fatal and Panic entries are never suppressed.
+type Deduper struct {
+	window     time.Duration
+	keys       []string
+	emit       func(e *dedupEntry)
+	mu         sync.Mutex
+	seen       map[string]*dedupEntry
+	suppressed atomic.Uint64
+	now        func() time.Time
+}
+
+// newDeduper creates a deduper emitting the repeat counts with emit
+func newDeduper(window time.Duration, keys []string, emit func(e *dedupEntry)) *Deduper {
+	return &Deduper{
+		window: window,
+		keys:   keys,
+		emit:   emit,
+		seen:   make(map[string]*dedupEntry),
+		now:    time.Now,
+	}
+}
+
+// Allow reports whether the entry is logged, emitting the repeat count of a
+// previous window of the same key first
+func (d *Deduper) Allow(level logrus.Level, msg string, fields logrus.Fields) bool {
+	if level <= logrus.FatalLevel {
+		return true
+	}
+	key := d.key(level, msg, fields)
+	now := d.now()
+
+	d.mu.Lock()
+	e := d.seen[key]
+	if e != nil && now.Sub(e.start) < d.window {
+		e.count++
+		e.fields = fields
+		d.mu.Unlock()
+		d.suppressed.Add(1)
+		return false
+	}
+	d.seen[key] = &dedupEntry{level: level, msg: msg, fields: fields, start: now}
+	d.mu.Unlock()
+
+	if e != nil && e.count > 0 {
+		d.emit(e)
+	}
+	return true
+}
+
+// key returns the dedup key of an entry
+func (d *Deduper) key(level logrus.Level, msg string, fields logrus.Fields) string {
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(0)
+	b.WriteString(msg)
+	for _, k := range d.keys {
+		b.WriteByte(0)
+		if v, ok := fields[k]; ok {
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String()
+}
+
+// flush emits the repeat counts of the windows ended at now, or of all
+// windows when all is set, and forgets them
+func (d *Deduper) flush(now time.Time, all bool) {
+	var ended []*dedupEntry
+	d.mu.Lock()
+	for key, e := range d.seen {
+		if all || now.Sub(e.start) >= d.window {
+			delete(d.seen, key)
+			if e.count > 0 {
+				ended = append(ended, e)
+			}
+		}
+	}
+	d.mu.Unlock()
+
+	for _, e := range ended {
+		d.emit(e)
+	}
+}
+
+// run flushes ended windows until done is closed, then flushes all
+func (d *Deduper) run(done <-chan struct{}) {
+	ticker := time.NewTicker(d.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(d.now(), false)
+		case <-done:
+			d.flush(d.now(), true)
+			return
+		}
+	}
+}
+
+// Suppressed returns the number of duplicate entries suppressed
+func (d *Deduper) Suppressed() uint64 {
+	return d.suppressed.Load()
+}
+
+// Deduper returns the deduper of the logger, nil when dedup is disabled
+func (l *Logger) Deduper() *Deduper {
+	return l.root().dedup
+}
+
+// deduped reports whether the entry is a suppressed duplicate
+func (l *Logger) deduped(level logrus.Level, msg string, fields logrus.Fields) bool {
+	d := l.root().dedup
+	return d != nil && !d.Allow(level, msg, fields)
+}
+
+// logRepeats logs the repeat count of a deduplicated entry
+func (l *Logger) logRepeats(e *dedupEntry) {
+	l.WithFields(e.fields).WithField(RepeatCountKey, e.count).Log(e.level, e.msg)
+}
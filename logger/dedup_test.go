@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDeduper(t *testing.T) {
+	var emitted []*dedupEntry
+	d := newDeduper(time.Second, []string{"error"}, func(e *dedupEntry) { emitted = append(emitted, e) })
+	now := time.Unix(1700000000, 0)
+	d.now = func() time.Time { return now }
+
+	refused := logrus.Fields{"error": "connection refused"}
+	if !d.Allow(logrus.ErrorLevel, "retry failed", refused) {
+		t.Fatal("expected the first entry logged")
+	}
+	for i := 0; i < 5; i++ {
+		if d.Allow(logrus.ErrorLevel, "retry failed", refused) {
+			t.Fatal("expected duplicates suppressed")
+		}
+	}
+	if !d.Allow(logrus.ErrorLevel, "retry failed", logrus.Fields{"error": "timeout"}) {
+		t.Fatal("expected a different key field logged")
+	}
+	if !d.Allow(logrus.WarnLevel, "retry failed", refused) {
+		t.Fatal("expected a different level logged")
+	}
+	if !d.Allow(logrus.FatalLevel, "down", nil) || !d.Allow(logrus.FatalLevel, "down", nil) {
+		t.Fatal("expected fatal entries never suppressed")
+	}
+	if d.Suppressed() != 5 {
+		t.Fatalf("suppressed = %d, want 5", d.Suppressed())
+	}
+
+	// The next window logs the entry after the repeat count of the previous one
+	now = now.Add(time.Second)
+	if !d.Allow(logrus.ErrorLevel, "retry failed", refused) {
+		t.Fatal("expected the entry logged in a new window")
+	}
+	if len(emitted) != 1 || emitted[0].count != 5 || emitted[0].msg != "retry failed" {
+		t.Fatalf("unexpected repeat counts %v", emitted)
+	}
+
+	d.Allow(logrus.ErrorLevel, "retry failed", refused)
+	d.flush(now, false)
+	if len(emitted) != 1 {
+		t.Fatalf("expected the open window kept, got %d repeat counts", len(emitted))
+	}
+	d.flush(now, true)
+	if len(emitted) != 2 || emitted[1].count != 1 {
+		t.Fatalf("expected the open window flushed, got %v", emitted)
+	}
+	if len(d.seen) != 0 {
+		t.Fatalf("expected windows forgotten, got %d", len(d.seen))
+	}
+}
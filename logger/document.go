@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DocumentMapping names the keys of the documents indexed by the search hooks
+type DocumentMapping struct {
+	Message   string
+	Level     string
+	Timestamp string
+	Fields    string // key of the nested entry fields
+}
+
+// DefaultDocumentMapping is the document shape used when no mapping is configured
+var DefaultDocumentMapping = DocumentMapping{
+	Message:   "message",
+	Level:     "level",
+	Timestamp: "@timestamp",
+	Fields:    "fields",
+}
+
+// documentMapping resolves the mapping of the logger document config
+func documentMapping(c *config.LoggerDocument) DocumentMapping {
+	if c == nil {
+		return DefaultDocumentMapping
+	}
+	return DocumentMapping{
+		Message:   c.Message,
+		Level:     c.Level,
+		Timestamp: c.Timestamp,
+		Fields:    c.Fields,
+	}.withDefaults()
+}
+
+// withDefaults fills empty keys from DefaultDocumentMapping
+func (m DocumentMapping) withDefaults() DocumentMapping {
+	if m.Message == "" {
+		m.Message = DefaultDocumentMapping.Message
+	}
+	if m.Level == "" {
+		m.Level = DefaultDocumentMapping.Level
+	}
+	if m.Timestamp == "" {
+		m.Timestamp = DefaultDocumentMapping.Timestamp
+	}
+	if m.Fields == "" {
+		m.Fields = DefaultDocumentMapping.Fields
+	}
+	return m
+}
+
+// Document returns the complete entry as a search document, with the
+// message, level and UTC timestamp at the top and the entry fields nested
+func (m DocumentMapping) Document(entry *logrus.Entry) map[string]any {
+	m = m.withDefaults()
+	return map[string]any{
+		m.Message:   entry.Message,
+		m.Level:     entry.Level.String(),
+		m.Timestamp: entry.Time.UTC().Format(time.RFC3339Nano),
+		m.Fields:    util.CopyMap(entry.Data),
+	}
+}
+
+// searchDocument returns the document builder of the search hooks, ECS
+// documents when the schema is "ecs", mapped documents otherwise
+func searchDocument(c *config.Logger) func(entry *logrus.Entry) map[string]any {
+	if c.Document != nil && c.Document.Schema == "ecs" {
+		return (&ECSFormatter{ServiceName: c.ServiceName}).Document
+	}
+	return documentMapping(c.Document).Document
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDocumentMapping(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithField("user", "alice")
+	entry.Message = "signed in"
+	entry.Level = logrus.WarnLevel
+	entry.Time = time.Date(2024, 5, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+
+	doc := DefaultDocumentMapping.Document(entry)
+	if doc["message"] != "signed in" || doc["level"] != "warning" || doc["@timestamp"] != "2024-05-01T00:00:00Z" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if fields := doc["fields"].(map[string]any); fields["user"] != "alice" {
+		t.Errorf("expected nested fields, got %v", doc["fields"])
+	}
+
+	renamed := documentMapping(&config.LoggerDocument{Message: "msg", Fields: "data"}).Document(entry)
+	if renamed["msg"] != "signed in" || renamed["data"] == nil || renamed["level"] != "warning" {
+		t.Errorf("expected renamed keys with defaults for the rest, got %v", renamed)
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"ncobase/common/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DurationMillisSuffix is appended to the numeric duration field name
+const DurationMillisSuffix = "_ms"
+
+// WithDuration returns an entry carrying the duration as numeric milliseconds
+// under "<name>_ms" and as a human-readable string under name
+func (l *Logger) WithDuration(ctx context.Context, name string, d time.Duration) *logrus.Entry {
+	return l.entryFromContext(ctx).WithFields(logrus.Fields{
+		name + DurationMillisSuffix: durationMillis(d),
+		name:                        d.String(),
+	})
+}
+
+// DurationFormatter renders time.Duration fields as numeric milliseconds
+// so they stay aggregatable once indexed, other fields are left untouched
+type DurationFormatter struct {
+	logrus.Formatter
+}
+
+// Format formats the entry without mutating the shared entry data
+func (f *DurationFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var data logrus.Fields
+	for k, v := range entry.Data {
+		d, ok := v.(time.Duration)
+		if !ok {
+			continue
+		}
+		if data == nil {
+			data = util.CopyMap(entry.Data)
+		}
+		data[k] = durationMillis(d)
+	}
+	if data == nil {
+		return f.Formatter.Format(entry)
+	}
+
+	e := *entry
+	e.Data = data
+	return f.Formatter.Format(&e)
+}
+
+// durationMillis converts a duration to fractional milliseconds
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// WithDuration returns an entry carrying the given duration
+func WithDuration(ctx context.Context, name string, d time.Duration) *logrus.Entry {
+	return StdLogger().WithDuration(ctx, name, d)
+}
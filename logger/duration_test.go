@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWithDuration_JSONNumeric(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{Logger: logrus.New()}
+	l.SetOutput(&buf)
+	l.SetFormatter(&DurationFormatter{Formatter: &logrus.JSONFormatter{}})
+
+	entry := l.WithDuration(context.Background(), "latency", 1500*time.Millisecond).
+		WithField("elapsed", 250*time.Millisecond)
+	entry.Info("request done")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+
+	if v, ok := out["latency_ms"].(float64); !ok || v != 1500 {
+		t.Errorf("expected numeric latency_ms 1500, got %#v", out["latency_ms"])
+	}
+	if v, ok := out["latency"].(string); !ok || v != "1.5s" {
+		t.Errorf("expected human readable latency 1.5s, got %#v", out["latency"])
+	}
+	if v, ok := out["elapsed"].(float64); !ok || v != 250 {
+		t.Errorf("expected duration field rendered as 250 ms, got %#v", out["elapsed"])
+	}
+
+	// The shared entry data must keep the original duration value
+	if _, ok := entry.Data["elapsed"].(time.Duration); !ok {
+		t.Errorf("entry data was mutated, got %#v", entry.Data["elapsed"])
+	}
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ECSVersion is the Elastic Common Schema version of ECS documents
+const ECSVersion = "1.6.0"
+
+// ecsFieldNames maps entry fields to their ECS names
+var ecsFieldNames = map[string]string{
+	traceKey:        "trace.id",
+	SpanIDKey:       "span.id",
+	VersionKey:      "service.version",
+	HostKey:         "host.hostname",
+	PIDKey:          "process.pid",
+	ModuleKey:       "log.logger",
+	CallerFileKey:   "log.origin.file.name",
+	CallerLineKey:   "log.origin.file.line",
+	CallerFuncKey:   "log.origin.function",
+	StackKey:        "error.stack_trace",
+	logrus.ErrorKey: "error.message",
+	ErrorTypeKey:    "error.type",
+}
+
+// ECSFormatter formats entries as Elastic Common Schema JSON, so they fit
+// the standard Kibana dashboards and the Logs app without ingest pipelines
+//
+// Known fields are renamed to their ECS names, e.g. trace_id to trace.id,
+// other fields are kept as they are.
+type ECSFormatter struct {
+	ServiceName string // service.name, omitted when empty
+}
+
+// Document returns the entry as an ECS document
+func (f *ECSFormatter) Document(entry *logrus.Entry) map[string]any {
+	doc := make(map[string]any, len(entry.Data)+5)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		if name, ok := ecsFieldNames[k]; ok {
+			k = name
+		}
+		doc[k] = v
+	}
+	doc["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["log.level"] = entry.Level.String()
+	doc["message"] = entry.Message
+	doc["ecs.version"] = ECSVersion
+	if f.ServiceName != "" {
+		doc["service.name"] = f.ServiceName
+	}
+	return doc
+}
+
+// Format renders the entry as a line of ECS JSON
+func (f *ECSFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(f.Document(entry)); err != nil {
+		return nil, fmt.Errorf("failed to marshal ECS entry: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestECSFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&ECSFormatter{ServiceName: "orders"})
+
+	l.WithFields(logrus.Fields{
+		traceKey:        "abc",
+		VersionKey:      "1.2.0",
+		logrus.ErrorKey: errors.New("timeout"),
+		"order_id":      7,
+	}).Error("payment failed")
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"ecs.version":     ECSVersion,
+		"log.level":       "error",
+		"message":         "payment failed",
+		"service.name":    "orders",
+		"service.version": "1.2.0",
+		"trace.id":        "abc",
+		"error.message":   "timeout",
+		"order_id":        float64(7),
+	}
+	for k, v := range want {
+		if doc[k] != v {
+			t.Errorf("%s: got %v, want %v", k, doc[k], v)
+		}
+	}
+	if _, ok := doc["@timestamp"]; !ok {
+		t.Error("expected @timestamp")
+	}
+	if _, ok := doc[traceKey]; ok {
+		t.Error("trace_id must be renamed")
+	}
+}
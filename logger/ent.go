@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSlowQueryThreshold is the duration above which queries are logged as slow
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// SQL query field names
+const (
+	SQLQueryKey    = "sql"
+	SQLDurationKey = "duration"
+)
+
+// EntDriver wraps an ent driver to log failed queries at error and queries
+// slower than the threshold at warn, with the fields of the query context
+//
+// Query arguments are not logged since they may hold personal data.
+type EntDriver struct {
+	dialect.Driver
+	log  *Logger
+	slow time.Duration
+}
+
+// EntDriver wraps drv to log its failed and slow queries, e.g.
+// ent.NewClient(ent.Driver(l.EntDriver(drv)))
+func (l *Logger) EntDriver(drv dialect.Driver) *EntDriver {
+	slow := l.root().slowQuery
+	if slow <= 0 {
+		slow = DefaultSlowQueryThreshold
+	}
+	return &EntDriver{Driver: drv, log: l, slow: slow}
+}
+
+// Exec executes the statement and logs it when failed or slow
+func (d *EntDriver) Exec(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := d.Driver.Exec(ctx, query, args, v)
+	d.log.logQuery(ctx, query, time.Since(start), d.slow, err)
+	return err
+}
+
+// Query executes the query and logs it when failed or slow
+func (d *EntDriver) Query(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := d.Driver.Query(ctx, query, args, v)
+	d.log.logQuery(ctx, query, time.Since(start), d.slow, err)
+	return err
+}
+
+// Tx starts a transaction whose statements are logged like the driver's
+func (d *EntDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	tx, err := d.Driver.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &entTx{Tx: tx, log: d.log, slow: d.slow}, nil
+}
+
+// entTx logs the failed and slow statements of a transaction
+type entTx struct {
+	dialect.Tx
+	log  *Logger
+	slow time.Duration
+}
+
+// Exec executes the statement and logs it when failed or slow
+func (t *entTx) Exec(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := t.Tx.Exec(ctx, query, args, v)
+	t.log.logQuery(ctx, query, time.Since(start), t.slow, err)
+	return err
+}
+
+// Query executes the query and logs it when failed or slow
+func (t *entTx) Query(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := t.Tx.Query(ctx, query, args, v)
+	t.log.logQuery(ctx, query, time.Since(start), t.slow, err)
+	return err
+}
+
+// logQuery logs a failed query at error and a slow one at warn
+func (l *Logger) logQuery(ctx context.Context, query string, d, slow time.Duration, err error) {
+	level, msg := logrus.ErrorLevel, "sql query failed"
+	if err == nil {
+		if d < slow {
+			return
+		}
+		level, msg = logrus.WarnLevel, "slow sql query"
+	}
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	fields := logrus.Fields{
+		SQLQueryKey:                           query,
+		SQLDurationKey:                        d.String(),
+		SQLDurationKey + DurationMillisSuffix: durationMillis(d),
+	}
+	if err != nil {
+		fields[logrus.ErrorKey] = err.Error()
+	}
+	l.EntryWithFields(ctx, fields).Log(level, msg)
+}
+
+// NewEntDriver wraps drv to log its failed and slow queries with the
+// package-level logger
+func NewEntDriver(drv dialect.Driver) *EntDriver {
+	return StdLogger().EntDriver(drv)
+}
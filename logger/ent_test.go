@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeDriver is an ent driver taking delay per statement and failing with err
+type fakeDriver struct {
+	delay time.Duration
+	err   error
+}
+
+func (d *fakeDriver) Exec(context.Context, string, any, any) error {
+	time.Sleep(d.delay)
+	return d.err
+}
+
+func (d *fakeDriver) Query(context.Context, string, any, any) error {
+	time.Sleep(d.delay)
+	return d.err
+}
+
+func (d *fakeDriver) Tx(context.Context) (dialect.Tx, error) { return dialect.NopTx(d), nil }
+func (d *fakeDriver) Close() error                           { return nil }
+func (d *fakeDriver) Dialect() string                        { return dialect.Postgres }
+
+func TestEntDriver(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.slowQuery = 20 * time.Millisecond
+	ctx := setTraceID(context.Background(), "trace-3")
+
+	drv := &fakeDriver{}
+	d := l.EntDriver(drv)
+	if err := d.Query(ctx, "SELECT 1", []any{}, nil); err != nil {
+		t.Fatalf("unexpected query error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected fast query not logged, got %s", buf.String())
+	}
+
+	drv.delay = 30 * time.Millisecond
+	tx, err := d.Tx(ctx)
+	if err != nil {
+		t.Fatalf("unexpected tx error: %v", err)
+	}
+	_ = tx.Exec(ctx, "UPDATE orders SET status = $1", []any{"paid"}, nil)
+	entry := lastEntry(t, &buf)
+	if entry["level"] != "warning" || entry[SQLQueryKey] != "UPDATE orders SET status = $1" || entry[traceKey] != "trace-3" {
+		t.Errorf("unexpected slow query entry: %v", entry)
+	}
+	if strings.Contains(buf.String(), "paid") {
+		t.Errorf("expected query arguments not logged, got %s", buf.String())
+	}
+
+	buf.Reset()
+	drv.delay, drv.err = 0, errors.New("relation \"orders\" does not exist")
+	if err := d.Exec(ctx, "DELETE FROM orders", []any{}, nil); err != drv.err {
+		t.Fatalf("expected driver error returned, got %v", err)
+	}
+	entry = lastEntry(t, &buf)
+	if entry["level"] != "error" || entry[logrus.ErrorKey] != drv.err.Error() {
+		t.Errorf("unexpected failed query entry: %v", entry)
+	}
+}
+
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	return entry
+}
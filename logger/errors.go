@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Error field names, the message is under logrus.ErrorKey
+const (
+	ErrorTypeKey  = "error_type"  // Go type of the root cause
+	ErrorCauseKey = "error_cause" // message of the root cause, when wrapped
+)
+
+// FieldsError is an error carrying structured fields, e.g. the order id of
+// a failed payment, they are logged as entry fields by WithError
+type FieldsError interface {
+	error
+	Fields() map[string]any
+}
+
+// callersError is an error recording the stack where it was created,
+// e.g. github.com/go-errors/errors
+type callersError interface {
+	error
+	Callers() []uintptr
+}
+
+// ErrorFields returns the fields of err and its wrapped chain: the message,
+// the type and message of the root cause, the Fields of FieldsError in the
+// chain, outer errors winning, and the stack of the first error recording one
+func ErrorFields(err error) logrus.Fields {
+	if err == nil {
+		return logrus.Fields{}
+	}
+	fields := logrus.Fields{}
+	var root error
+	var rootDepth int
+	var stack string
+	walkErrors(err, func(e error) {
+		if fe, ok := e.(FieldsError); ok {
+			for k, v := range fe.Fields() {
+				if _, set := fields[k]; !set {
+					fields[k] = v
+				}
+			}
+		}
+		if ce, ok := e.(callersError); ok && stack == "" {
+			stack = formatCallers(ce.Callers())
+		}
+	}, func(leaf error, depth int) {
+		if root == nil {
+			root, rootDepth = leaf, depth
+		}
+	})
+
+	fields[logrus.ErrorKey] = err.Error()
+	fields[ErrorTypeKey] = fmt.Sprintf("%T", root)
+	if rootDepth > 0 {
+		fields[ErrorCauseKey] = root.Error()
+	}
+	if stack != "" {
+		fields[StackKey] = stack
+	}
+	return fields
+}
+
+// walkErrors visits err and the errors it wraps depth first, including the
+// branches of errors.Join, and reports every error wrapping nothing as a leaf
+// with its depth
+func walkErrors(err error, visit func(e error), leaf func(e error, depth int)) {
+	var walk func(e error, depth int)
+	walk = func(e error, depth int) {
+		// Bound the depth against cyclic chains
+		if e == nil || depth > 100 {
+			return
+		}
+		visit(e)
+		switch u := e.(type) {
+		case interface{ Unwrap() []error }:
+			children := u.Unwrap()
+			if len(children) == 0 {
+				leaf(e, depth)
+			}
+			for _, c := range children {
+				walk(c, depth+1)
+			}
+		default:
+			if next := errors.Unwrap(e); next != nil {
+				walk(next, depth+1)
+			} else {
+				leaf(e, depth)
+			}
+		}
+	}
+	walk(err, 0)
+}
+
+// formatCallers formats program counters like captureStack
+func formatCallers(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	frames := runtime.CallersFrames(pcs)
+	var b strings.Builder
+	for count := 0; count < defaultStackDepth; count++ {
+		frame, more := frames.Next()
+		if count > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteByte(' ')
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// WithError returns an entry with the context fields and the structured
+// fields of err, instead of flattening the chain with %v
+func (l *Logger) WithError(ctx context.Context, err error) *logrus.Entry {
+	return l.EntryWithFields(ctx, ErrorFields(err))
+}
+
+// WithError returns an entry of the package-level logger with the fields of err
+func WithError(ctx context.Context, err error) *logrus.Entry {
+	return StdLogger().WithError(ctx, err)
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// orderError is a FieldsError
+type orderError struct {
+	id  int
+	err error
+}
+
+func (e *orderError) Error() string          { return fmt.Sprintf("order %d: %v", e.id, e.err) }
+func (e *orderError) Unwrap() error          { return e.err }
+func (e *orderError) Fields() map[string]any { return map[string]any{"order_id": e.id} }
+
+// stackError records the stack where it was created
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 8)
+	return &stackError{msg: msg, pcs: pcs[:runtime.Callers(1, pcs)]}
+}
+
+func (e *stackError) Error() string      { return e.msg }
+func (e *stackError) Callers() []uintptr { return e.pcs }
+
+func TestErrorFields(t *testing.T) {
+	root := &fs.PathError{Op: "open", Path: "/etc/orders", Err: fs.ErrNotExist}
+	err := fmt.Errorf("charge failed: %w", &orderError{id: 7, err: root})
+
+	fields := ErrorFields(err)
+	if fields[logrus.ErrorKey] != err.Error() {
+		t.Errorf("error = %v, want %q", fields[logrus.ErrorKey], err.Error())
+	}
+	if fields[ErrorTypeKey] != "*errors.errorString" {
+		t.Errorf("error_type = %v, want the root cause type", fields[ErrorTypeKey])
+	}
+	if fields[ErrorCauseKey] != fs.ErrNotExist.Error() {
+		t.Errorf("error_cause = %v, want %q", fields[ErrorCauseKey], fs.ErrNotExist.Error())
+	}
+	if fields["order_id"] != 7 {
+		t.Errorf("order_id = %v, want 7", fields["order_id"])
+	}
+	if _, ok := fields[StackKey]; ok {
+		t.Error("expected no stack without a stack recording error")
+	}
+
+	plain := ErrorFields(errors.New("boom"))
+	if _, ok := plain[ErrorCauseKey]; ok || plain[ErrorTypeKey] != "*errors.errorString" {
+		t.Errorf("unexpected fields of an unwrapped error %v", plain)
+	}
+	if len(ErrorFields(nil)) != 0 {
+		t.Error("expected no fields for a nil error")
+	}
+}
+
+func TestErrorFields_JoinAndStack(t *testing.T) {
+	err := errors.Join(
+		&orderError{id: 1, err: newStackError("card declined")},
+		&orderError{id: 2, err: errors.New("timeout")},
+	)
+	fields := ErrorFields(err)
+	if fields["order_id"] != 1 {
+		t.Errorf("order_id = %v, want the first branch", fields["order_id"])
+	}
+	if fields[ErrorCauseKey] != "card declined" || fields[ErrorTypeKey] != "*logger.stackError" {
+		t.Errorf("unexpected root cause %v %v", fields[ErrorTypeKey], fields[ErrorCauseKey])
+	}
+	stack, _ := fields[StackKey].(string)
+	if !strings.Contains(stack, "newStackError") {
+		t.Errorf("expected the recorded stack, got %q", stack)
+	}
+}
+
+func TestLogger_WithError(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := setTraceID(context.Background(), "trace-5")
+	l.WithError(ctx, fmt.Errorf("save: %w", &orderError{id: 3, err: errors.New("disk full")})).Error("order not saved")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	if entry[traceKey] != "trace-5" || entry["order_id"] != float64(3) || entry[ErrorCauseKey] != "disk full" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"ncobase/common/config"
+)
+
+const fatalMarkerEnv = "LOGGER_FATAL_MARKER"
+
+func TestFatal_RunsExitHandlers(t *testing.T) {
+	if marker := os.Getenv(fatalMarkerEnv); marker != "" {
+		// Subprocess: Init registers its cleanup, plus a custom handler
+		if _, err := Init(&config.Logger{Output: "stderr"}); err != nil {
+			os.Exit(2)
+		}
+		RegisterExitHandler(func() {
+			_ = os.WriteFile(marker, []byte("ran"), 0644)
+		})
+		Fatal(context.Background(), "fatal in subprocess")
+		return
+	}
+
+	marker := filepath.Join(t.TempDir(), "marker")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal_RunsExitHandlers$")
+	cmd.Env = append(os.Environ(), fatalMarkerEnv+"="+marker)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected subprocess to exit with code 1, got %v", err)
+	}
+	if b, err := os.ReadFile(marker); err != nil || string(b) != "ran" {
+		t.Errorf("expected exit handler to run, marker: %q, err: %v", b, err)
+	}
+}
+
+const fatalCleanupEnv = "LOGGER_FATAL_CLEANUP"
+
+func TestFatal_RunsOwnCleanup(t *testing.T) {
+	if dir := os.Getenv(fatalCleanupEnv); dir != "" {
+		// Subprocess: each cleanup leaves a file named after its logger
+		var loggers []*Logger
+		for _, name := range []string{"own", "other"} {
+			l, _, err := New(&config.Logger{Output: "stderr"})
+			if err != nil {
+				os.Exit(2)
+			}
+			cleanup := func() { _ = os.WriteFile(filepath.Join(dir, name), nil, 0644) }
+			l.exitCleanup.Store(&cleanup)
+			loggers = append(loggers, l)
+		}
+		loggers[0].Fatal(context.Background(), "fatal in subprocess")
+		return
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatal_RunsOwnCleanup$")
+	cmd.Env = append(os.Environ(), fatalCleanupEnv+"="+dir)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected subprocess to exit with code 1, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "own")); err != nil {
+		t.Errorf("expected the cleanup of the fatal logger to run: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other")); err == nil {
+		t.Error("expected the cleanup of another logger not to run")
+	}
+}
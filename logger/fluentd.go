@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	fluentdMinBackoff = 500 * time.Millisecond
+	fluentdMaxBackoff = 30 * time.Second
+)
+
+// ErrFluentdUnavailable is returned by Fire while waiting to reconnect to Fluentd
+var ErrFluentdUnavailable = errors.New("fluentd unavailable, entry dropped")
+
+// FluentdHook sends entries to Fluentd or Fluent Bit over the forward protocol
+//
+// Each entry is a msgpack encoded [tag, time, record] message. The connection
+// is opened lazily, after a failure it is retried with exponential backoff and
+// entries fired in between are dropped so logging never waits on the sidecar.
+type FluentdHook struct {
+	mu       sync.Mutex
+	address  string
+	tag      string
+	timeout  time.Duration
+	conn     net.Conn
+	backoff  time.Duration
+	retryAt  time.Time
+	now      func() time.Time
+	dialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// NewFluentdHook creates a Fluentd forward hook
+func NewFluentdHook(c *config.LoggerFluentd) *FluentdHook {
+	return &FluentdHook{
+		address:  net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		tag:      c.Tag,
+		timeout:  c.Timeout,
+		now:      time.Now,
+		dialFunc: net.DialTimeout,
+	}
+}
+
+// Levels returns all log levels
+func (h *FluentdHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire sends the entry
+func (h *FluentdHook) Fire(entry *logrus.Entry) error {
+	record := util.CopyMap(entry.Data)
+	record[logrus.FieldKeyMsg] = entry.Message
+	record[logrus.FieldKeyLevel] = entry.Level.String()
+
+	var enc msgpackEncoder
+	enc.encodeArrayHeader(3)
+	enc.encodeString(h.tag)
+	enc.encodeEventTime(entry.Time)
+	enc.encodeMap(record)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		if err := h.connect(); err != nil {
+			return err
+		}
+	}
+
+	if h.timeout > 0 {
+		_ = h.conn.SetWriteDeadline(h.now().Add(h.timeout))
+	}
+	if _, err := h.conn.Write(enc.bytes()); err != nil {
+		h.fail()
+		return fmt.Errorf("failed to write to fluentd: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (h *FluentdHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// connect dials Fluentd unless a retry is scheduled in the future
+func (h *FluentdHook) connect() error {
+	if h.now().Before(h.retryAt) {
+		return ErrFluentdUnavailable
+	}
+	conn, err := h.dialFunc("tcp", h.address, h.timeout)
+	if err != nil {
+		h.fail()
+		return fmt.Errorf("failed to connect to fluentd: %w", err)
+	}
+	h.conn = conn
+	h.backoff = 0
+	return nil
+}
+
+// fail drops the connection and schedules the next attempt
+func (h *FluentdHook) fail() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+	h.backoff = min(max(h.backoff*2, fluentdMinBackoff), fluentdMaxBackoff)
+	h.retryAt = h.now().Add(h.backoff)
+}
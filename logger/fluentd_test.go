@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMsgpackEncoder(t *testing.T) {
+	var enc msgpackEncoder
+	enc.encode(map[string]any{"a": 1, "b": "x", "c": -1, "d": true, "e": nil})
+
+	want := []byte{0x85, 0xa1, 'a', 0x01, 0xa1, 'b', 0xa1, 'x', 0xa1, 'c', 0xff, 0xa1, 'd', 0xc3, 0xa1, 'e', 0xc0}
+	if !bytes.Equal(enc.bytes(), want) {
+		t.Errorf("expected % x, got % x", want, enc.bytes())
+	}
+}
+
+func TestFluentdHook_Forward(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 512)
+		n, _ := io.ReadAtLeast(conn, buf, 1)
+		received <- buf[:n]
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	h := NewFluentdHook(&config.LoggerFluentd{Host: "127.0.0.1", Port: addr.Port, Tag: "app", Timeout: time.Second})
+	defer h.Close()
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message, entry.Level, entry.Time = "hi", logrus.InfoLevel, time.Unix(1700000000, 5)
+	if err := h.Fire(entry); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		// [tag, EventTime(sec, nsec), {level, msg}]
+		prefix := []byte{0x93, 0xa3, 'a', 'p', 'p', 0xd7, 0x00, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x05, 0x82}
+		if !bytes.HasPrefix(msg, prefix) || !bytes.Contains(msg, []byte("\xa2hi")) {
+			t.Errorf("unexpected forward message: % x", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestFluentdHook_Backoff(t *testing.T) {
+	now := time.Unix(0, 0)
+	dials := 0
+	h := NewFluentdHook(&config.LoggerFluentd{Host: "fluent-bit", Port: 24224, Tag: "app"})
+	h.now = func() time.Time { return now }
+	h.dialFunc = func(string, string, time.Duration) (net.Conn, error) {
+		dials++
+		return nil, errors.New("connection refused")
+	}
+
+	entry := logrus.NewEntry(logrus.New())
+	if err := h.Fire(entry); err == nil || errors.Is(err, ErrFluentdUnavailable) {
+		t.Fatalf("expected dial error, got %v", err)
+	}
+	if err := h.Fire(entry); !errors.Is(err, ErrFluentdUnavailable) {
+		t.Fatalf("expected ErrFluentdUnavailable during backoff, got %v", err)
+	}
+	if dials != 1 {
+		t.Fatalf("expected no dial during backoff, got %d dials", dials)
+	}
+
+	now = now.Add(fluentdMinBackoff)
+	_ = h.Fire(entry)
+	if dials != 2 {
+		t.Errorf("expected a retry after the backoff, got %d dials", dials)
+	}
+	if h.backoff != 2*fluentdMinBackoff {
+		t.Errorf("expected the backoff to double, got %v", h.backoff)
+	}
+}
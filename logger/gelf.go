@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	gelfVersion = "1.1"
+	// DefaultGELFChunkSize keeps UDP datagrams below a typical MTU
+	DefaultGELFChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+// ErrGELFTooLarge is returned when a message needs more than 128 UDP chunks
+var ErrGELFTooLarge = errors.New("gelf message too large")
+
+// gelfInvalidFieldChars matches characters not allowed in GELF field names
+var gelfInvalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFFormatter formats entries as GELF 1.1 JSON messages
+//
+// Entry fields become additional fields prefixed with "_", invalid characters
+// are replaced with "_" and the reserved "_id" field is renamed to "__id".
+// Messages spanning several lines keep their first line as short_message.
+type GELFFormatter struct {
+	Host string // host field, default the hostname
+}
+
+// Format implements logrus.Formatter
+func (f *GELFFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	host := f.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	short, _, multiline := strings.Cut(entry.Message, "\n")
+	msg := map[string]any{
+		"version":       gelfVersion,
+		"host":          host,
+		"short_message": short,
+		"timestamp":     float64(entry.Time.UnixNano()) / float64(time.Second),
+		"level":         syslogSeverity(entry.Level),
+	}
+	if multiline {
+		msg["full_message"] = entry.Message
+	}
+
+	for k, v := range entry.Data {
+		name := "_" + gelfInvalidFieldChars.ReplaceAllString(k, "_")
+		if name == "_id" {
+			name = "__id"
+		}
+		switch val := v.(type) {
+		case error:
+			v = val.Error()
+		case time.Duration:
+			v = durationMillis(val)
+		}
+		msg[name] = v
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gelf message: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// GraylogHook sends GELF messages to Graylog over UDP or TCP
+//
+// UDP messages larger than the chunk size are split into GELF chunks, TCP
+// messages are terminated by a null byte. A failed TCP write redials once.
+type GraylogHook struct {
+	mu        sync.Mutex
+	network   string
+	address   string
+	chunkSize int
+	formatter *GELFFormatter
+	conn      net.Conn
+}
+
+// NewGraylogHook creates a Graylog hook and connects to the input
+func NewGraylogHook(c *config.LoggerGraylog) (*GraylogHook, error) {
+	network := strings.ToLower(c.Network)
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported graylog network %q", c.Network)
+	}
+	h := &GraylogHook{
+		network:   network,
+		address:   c.Address,
+		chunkSize: c.ChunkSize,
+		formatter: &GELFFormatter{},
+	}
+	if h.chunkSize <= gelfChunkHeaderSize {
+		h.chunkSize = DefaultGELFChunkSize
+	}
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Levels returns all log levels
+func (h *GraylogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire sends the entry as a GELF message
+func (h *GraylogHook) Fire(entry *logrus.Entry) error {
+	data, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.network == "udp" {
+		return h.writeUDP(data)
+	}
+
+	data = append(data, 0)
+	if h.conn != nil {
+		if _, err := h.conn.Write(data); err == nil {
+			return nil
+		}
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+	if err := h.connect(); err != nil {
+		return err
+	}
+	_, err = h.conn.Write(data)
+	return err
+}
+
+// Close closes the connection
+func (h *GraylogHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// connect dials the Graylog input
+func (h *GraylogHook) connect() error {
+	conn, err := net.Dial(h.network, h.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to graylog: %w", err)
+	}
+	h.conn = conn
+	return nil
+}
+
+// writeUDP writes the message in one datagram or in chunks
+func (h *GraylogHook) writeUDP(data []byte) error {
+	chunks, err := gelfChunks(data, h.chunkSize)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if _, err := h.conn.Write(chunk); err != nil {
+			return fmt.Errorf("failed to write to graylog: %w", err)
+		}
+	}
+	return nil
+}
+
+// gelfChunks splits data into GELF chunks of at most size bytes, data that
+// fits is returned as is
+func gelfChunks(data []byte, size int) ([][]byte, error) {
+	if len(data) <= size {
+		return [][]byte{data}, nil
+	}
+
+	payload := size - gelfChunkHeaderSize
+	count := (len(data) + payload - 1) / payload
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("%w: %d bytes need %d chunks", ErrGELFTooLarge, len(data), count)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate gelf message id: %w", err)
+	}
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*payload, len(data))
+		chunk := make([]byte, 0, gelfChunkHeaderSize+end-i*payload)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data[i*payload:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"id":       "abc",
+		"user id":  7,
+		"duration": 1500 * time.Millisecond,
+		"error":    errors.New("boom"),
+	})
+	entry.Message, entry.Level, entry.Time = "failed\nstack", logrus.ErrorLevel, time.Unix(1700000000, 500000000)
+
+	data, err := (&GELFFormatter{Host: "api-1"}).Format(entry)
+	if err != nil {
+		t.Fatalf("unexpected format error: %v", err)
+	}
+
+	var msg map[string]any
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("invalid GELF JSON: %v", err)
+	}
+	want := map[string]any{
+		"version":       "1.1",
+		"host":          "api-1",
+		"short_message": "failed",
+		"full_message":  "failed\nstack",
+		"timestamp":     1700000000.5,
+		"level":         float64(3),
+		"__id":          "abc",
+		"_user_id":      float64(7),
+		"_duration":     float64(1500),
+		"_error":        "boom",
+	}
+	for k, v := range want {
+		if msg[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, msg[k])
+		}
+	}
+}
+
+func TestGELFChunks(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 100)
+
+	chunks, err := gelfChunks(data, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 28 bytes of payload per chunk
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks, got %d", len(chunks))
+	}
+
+	var joined []byte
+	for i, c := range chunks {
+		if c[0] != 0x1e || c[1] != 0x0f || c[10] != byte(i) || c[11] != 4 {
+			t.Errorf("chunk %d: unexpected header % x", i, c[:12])
+		}
+		if !bytes.Equal(c[2:10], chunks[0][2:10]) {
+			t.Errorf("chunk %d: message id differs", i)
+		}
+		joined = append(joined, c[12:]...)
+	}
+	if !bytes.Equal(joined, data) {
+		t.Error("chunks do not reassemble to the message")
+	}
+
+	if _, err := gelfChunks(bytes.Repeat([]byte("a"), 129*28+1), 40); !errors.Is(err, ErrGELFTooLarge) {
+		t.Errorf("expected ErrGELFTooLarge, got %v", err)
+	}
+}
+
+func TestGraylogHook_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer pc.Close()
+
+	h, err := NewGraylogHook(&config.LoggerGraylog{Network: "udp", Address: pc.LocalAddr().String(), ChunkSize: 1420})
+	if err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+	defer h.Close()
+
+	entry := logrus.NewEntry(logrus.New()).WithField("module", "billing")
+	entry.Message, entry.Level = "paid", logrus.InfoLevel
+	if err := h.Fire(entry); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read datagram: %v", err)
+	}
+	if got := string(buf[:n]); !strings.Contains(got, `"_module":"billing"`) || strings.HasSuffix(got, "\n") {
+		t.Errorf("unexpected datagram: %q", got)
+	}
+}
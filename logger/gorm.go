@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger adapts the logger to gorm's logger.Interface, logging failed
+// queries at error and queries slower than the threshold at warn like
+// EntDriver, e.g. gorm.Open(dialector, &gorm.Config{Logger: l.GormLogger()})
+//
+// Record not found errors are not logged, they are the expected outcome of a
+// lookup. Query arguments are not logged since they may hold personal data.
+type GormLogger struct {
+	log   *Logger
+	slow  time.Duration
+	level gormlogger.LogLevel
+}
+
+var (
+	_ gormlogger.Interface = (*GormLogger)(nil)
+	_ gorm.ParamsFilter    = (*GormLogger)(nil)
+)
+
+// GormLogger returns a gorm logger at the warn level
+func (l *Logger) GormLogger() *GormLogger {
+	slow := l.root().slowQuery
+	if slow <= 0 {
+		slow = DefaultSlowQueryThreshold
+	}
+	return &GormLogger{log: l, slow: slow, level: gormlogger.Warn}
+}
+
+// LogMode returns a copy of the gorm logger at level
+func (g *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *g
+	c.level = level
+	return &c
+}
+
+// Info logs a gorm message at info
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Info {
+		g.log.Infof(ctx, msg, args...)
+	}
+}
+
+// Warn logs a gorm message at warn
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Warn {
+		g.log.Warnf(ctx, msg, args...)
+	}
+}
+
+// Error logs a gorm message at error
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Error {
+		g.log.Errorf(ctx, msg, args...)
+	}
+}
+
+// ParamsFilter drops the query arguments gorm would render into the SQL
+func (g *GormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+// Trace logs the query started at begin when failed or slow, the SQL is only
+// rendered then
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if errors.Is(err, gormlogger.ErrRecordNotFound) {
+		err = nil
+	}
+	d := time.Since(begin)
+	switch {
+	case err != nil && g.level >= gormlogger.Error:
+	case err == nil && d >= g.slow && g.level >= gormlogger.Warn:
+	default:
+		return
+	}
+	query, _ := fc()
+	g.log.logQuery(ctx, query, d, g.slow, err)
+}
+
+// NewGormLogger returns a gorm logger of the package-level logger
+func NewGormLogger() *GormLogger {
+	return StdLogger().GormLogger()
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestGormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.slowQuery = 20 * time.Millisecond
+	ctx := setTraceID(context.Background(), "trace-4")
+
+	g := l.GormLogger()
+	rendered := 0
+	query := func(sql string) func() (string, int64) {
+		return func() (string, int64) {
+			rendered++
+			return sql, 1
+		}
+	}
+
+	g.Trace(ctx, time.Now(), query("SELECT 1"), nil)
+	g.Trace(ctx, time.Now(), query("SELECT * FROM orders WHERE id = 7"), gormlogger.ErrRecordNotFound)
+	if buf.Len() != 0 || rendered != 0 {
+		t.Fatalf("expected fast and not found queries not logged, got %s", buf.String())
+	}
+
+	g.Trace(ctx, time.Now().Add(-30*time.Millisecond), query("UPDATE orders SET status = 'paid'"), nil)
+	entry := lastEntry(t, &buf)
+	if entry["level"] != "warning" || entry[SQLQueryKey] != "UPDATE orders SET status = 'paid'" || entry[traceKey] != "trace-4" {
+		t.Errorf("unexpected slow query entry: %v", entry)
+	}
+
+	buf.Reset()
+	failed := errors.New("relation \"orders\" does not exist")
+	g.Trace(ctx, time.Now(), query("DELETE FROM orders"), failed)
+	entry = lastEntry(t, &buf)
+	if entry["level"] != "error" || entry[logrus.ErrorKey] != failed.Error() {
+		t.Errorf("unexpected failed query entry: %v", entry)
+	}
+
+	if sql, args := g.ParamsFilter(ctx, "SELECT * FROM users WHERE email = ?", "a@example.com"); sql != "SELECT * FROM users WHERE email = ?" || args != nil {
+		t.Errorf("expected query arguments dropped, got %q %v", sql, args)
+	}
+
+	buf.Reset()
+	silent := g.LogMode(gormlogger.Silent)
+	silent.Trace(ctx, time.Now(), query("DELETE FROM orders"), failed)
+	silent.Error(ctx, "failed to migrate %s", "orders")
+	if buf.Len() != 0 {
+		t.Errorf("expected a silent gorm logger to log nothing, got %s", buf.String())
+	}
+	g.Error(ctx, "failed to migrate %s", "orders")
+	if entry = lastEntry(t, &buf); entry["msg"] != "failed to migrate orders" {
+		t.Errorf("unexpected gorm message entry: %v", entry)
+	}
+}
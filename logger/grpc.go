@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"ncobase/common/consts"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC call field names
+const (
+	GRPCMethodKey   = "grpc_method"
+	GRPCCodeKey     = "grpc_code"
+	GRPCDurationKey = "duration"
+)
+
+// UnaryServerInterceptor logs every unary call with its method, code and duration
+//
+// The trace id is taken from the x-md-trace, traceparent or B3 incoming
+// metadata or generated, set on the handler context and sent back in the
+// response header. Calls failing with a client error code are logged at warn,
+// other failures at error. Entries pass through the logger hooks, so redaction applies.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, traceID := incomingTrace(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(consts.TraceKey, traceID))
+
+		resp, err := handler(ctx, req)
+		l.logRPC(ctx, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every stream with its method, code and duration
+func (l *Logger) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, traceID := incomingTrace(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(consts.TraceKey, traceID))
+
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		l.logRPC(ctx, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// logRPC logs a finished call at the level of its status code
+func (l *Logger) logRPC(ctx context.Context, method string, err error, d time.Duration) {
+	code := status.Code(err)
+	level := rpcLevel(code)
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	fields := logrus.Fields{
+		GRPCMethodKey:                          method,
+		GRPCCodeKey:                            code.String(),
+		GRPCDurationKey:                        d.String(),
+		GRPCDurationKey + DurationMillisSuffix: durationMillis(d),
+	}
+	if err != nil {
+		fields[logrus.ErrorKey] = status.Convert(err).Message()
+	}
+	l.EntryWithFields(ctx, fields).Log(level, "grpc call")
+}
+
+// rpcLevel returns the log level of a status code
+func rpcLevel(code codes.Code) logrus.Level {
+	switch code {
+	case codes.OK:
+		return logrus.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.OutOfRange, codes.ResourceExhausted:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// incomingTrace sets the trace id of the incoming metadata on ctx, taken
+// from x-md-trace, then traceparent or B3, generating one when absent
+func incomingTrace(ctx context.Context) (context.Context, string) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(consts.TraceKey); len(values) > 0 && values[0] != "" {
+			return setTraceID(ctx, values[0]), values[0]
+		}
+		if p, ok := ExtractGRPC(md); ok {
+			return setTraceID(ctx, p.TraceID), p.TraceID
+		}
+	}
+	return EnsureTraceID(ctx)
+}
+
+// tracedStream overrides the context of a server stream
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the trace id
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryServerInterceptor logs unary calls with the package-level logger
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return StdLogger().UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor logs streams with the package-level logger
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return StdLogger().StreamServerInterceptor()
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"ncobase/common/consts"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(consts.TraceKey, "trace-9"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+	var handlerTrace string
+	_, err := l.UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		handlerTrace = getTraceID(ctx)
+		return nil, status.Error(codes.NotFound, "order 7 not found")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected handler error returned, got %v", err)
+	}
+	if handlerTrace != "trace-9" {
+		t.Errorf("expected trace id from metadata, got %q", handlerTrace)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "warning" || entry[GRPCCodeKey] != "NotFound" || entry[GRPCMethodKey] != info.FullMethod {
+		t.Errorf("unexpected entry %v", entry)
+	}
+	if entry[traceKey] != "trace-9" || entry[logrus.ErrorKey] != "order 7 not found" {
+		t.Errorf("expected trace and error fields, got %v", entry)
+	}
+}
+
+func TestRPCLevel(t *testing.T) {
+	if rpcLevel(codes.OK) != logrus.InfoLevel || rpcLevel(codes.Unauthenticated) != logrus.WarnLevel || rpcLevel(codes.Internal) != logrus.ErrorLevel {
+		t.Error("unexpected rpc levels")
+	}
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelFilter restricts a hook to the levels at or above a minimum
+type levelFilter struct {
+	logrus.Hook
+	levels []logrus.Level
+}
+
+// Levels returns the levels of the hook at or above the minimum
+func (f *levelFilter) Levels() []logrus.Level {
+	return f.levels
+}
+
+// WithMinLevel restricts the hook to entries at or above min, e.g. only warn
+// and above to Elasticsearch while debug still reaches the local output
+func WithMinLevel(hook logrus.Hook, min logrus.Level) logrus.Hook {
+	var levels []logrus.Level
+	for _, level := range hook.Levels() {
+		if level <= min {
+			levels = append(levels, level)
+		}
+	}
+	return &levelFilter{Hook: hook, levels: levels}
+}
+
+// filterHook applies the configured minimum level of the named hook
+func (l *Logger) filterHook(name string, hook logrus.Hook) logrus.Hook {
+	if min, ok := l.hookLevels[name]; ok {
+		return WithMinLevel(hook, min)
+	}
+	return hook
+}
+
+// parseHookLevels parses the minimum level names by hook name
+func parseHookLevels(names map[string]string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level, len(names))
+	for hook, name := range names {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid level of log hook %s: %w", hook, err)
+		}
+		levels[hook] = level
+	}
+	return levels, nil
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelRecorder records the levels of fired entries
+type levelRecorder struct {
+	levels []logrus.Level
+}
+
+func (r *levelRecorder) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (r *levelRecorder) Fire(entry *logrus.Entry) error {
+	r.levels = append(r.levels, entry.Level)
+	return nil
+}
+
+func TestWithMinLevel(t *testing.T) {
+	rec := &levelRecorder{}
+	l := logrus.New()
+	l.SetLevel(logrus.DebugLevel)
+	l.SetOutput(io.Discard)
+	l.AddHook(WithMinLevel(rec, logrus.WarnLevel))
+
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	if len(rec.levels) != 2 || rec.levels[0] != logrus.WarnLevel || rec.levels[1] != logrus.ErrorLevel {
+		t.Errorf("expected only warn and error, got %v", rec.levels)
+	}
+}
+
+func TestParseHookLevels(t *testing.T) {
+	levels, err := parseHookLevels(map[string]string{"elasticsearch": "warn", "meilisearch": "info"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if levels["elasticsearch"] != logrus.WarnLevel || levels["meilisearch"] != logrus.InfoLevel {
+		t.Errorf("unexpected levels %v", levels)
+	}
+	if _, err := parseHookLevels(map[string]string{"loki": "loud"}); err == nil {
+		t.Error("expected error for invalid level")
+	}
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"ncobase/common/consts"
+	"ncobase/common/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTP request field names
+const (
+	HTTPMethodKey   = "method"
+	HTTPPathKey     = "path"
+	HTTPStatusKey   = "status"
+	HTTPLatencyKey  = "latency"
+	HTTPBytesKey    = "bytes"
+	HTTPClientIPKey = "client_ip"
+)
+
+// HTTPMiddleware logs every request handled by next except the skip paths,
+// e.g. health checks
+//
+// The trace id is taken from the x-md-trace, traceparent or B3 request
+// headers or generated, set on the request context and echoed in the response
+// header, so entries logged by handlers share it. Requests are logged at info,
+// 4xx at warn and 5xx at error.
+func (l *Logger) HTTPMiddleware(skipPaths ...string) func(http.Handler) http.Handler {
+	skip := pathSet(skipPaths)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := skip[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			ctx, traceID := requestTrace(r.Context(), r.Header)
+			w.Header().Set(consts.TraceKey, traceID)
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(rec, r)
+
+			l.logRequest(ctx, r.Method, r.URL.Path, rec.status, rec.bytes, clientIP(r), time.Since(start))
+		})
+	}
+}
+
+// GinMiddleware is the Gin variant of HTTPMiddleware
+func (l *Logger) GinMiddleware(skipPaths ...string) gin.HandlerFunc {
+	skip := pathSet(skipPaths)
+	return func(c *gin.Context) {
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		ctx, traceID := requestTrace(c.Request.Context(), c.Request.Header)
+		c.Set(helper.TraceIDKey, traceID)
+		c.Header(consts.TraceKey, traceID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		l.logRequest(c.Request.Context(), c.Request.Method, c.Request.URL.Path, c.Writer.Status(), int64(size), c.ClientIP(), time.Since(start))
+	}
+}
+
+// logRequest logs a handled request at the level of its status
+func (l *Logger) logRequest(ctx context.Context, method, path string, status int, bytes int64, ip string, latency time.Duration) {
+	level := logrus.InfoLevel
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = logrus.ErrorLevel
+	case status >= http.StatusBadRequest:
+		level = logrus.WarnLevel
+	}
+	if !l.IsLevelEnabled(level) {
+		return
+	}
+	l.EntryWithFields(ctx, logrus.Fields{
+		HTTPMethodKey:                         method,
+		HTTPPathKey:                           path,
+		HTTPStatusKey:                         status,
+		HTTPBytesKey:                          bytes,
+		HTTPClientIPKey:                       ip,
+		HTTPLatencyKey:                        latency.String(),
+		HTTPLatencyKey + DurationMillisSuffix: durationMillis(latency),
+	}).Log(level, "http request")
+}
+
+// requestTrace sets the trace id of the request on ctx, taken from the
+// x-md-trace header, then the traceparent or B3 headers, else generated
+func requestTrace(ctx context.Context, h http.Header) (context.Context, string) {
+	if traceID := h.Get(consts.TraceKey); traceID != "" {
+		return setTraceID(ctx, traceID), traceID
+	}
+	if p, ok := ExtractHTTP(h); ok {
+		return setTraceID(ctx, p.TraceID), p.TraceID
+	}
+	return EnsureTraceID(ctx)
+}
+
+// pathSet returns the paths as a set
+func pathSet(paths []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		set[p] = struct{}{}
+	}
+	return set
+}
+
+// clientIP returns the first forwarded address, or the remote address
+func clientIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		ip, _, _ := strings.Cut(xff, ",")
+		return strings.TrimSpace(ip)
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder records the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the written bytes
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// HTTPMiddleware logs requests with the package-level logger
+func HTTPMiddleware(skipPaths ...string) func(http.Handler) http.Handler {
+	return StdLogger().HTTPMiddleware(skipPaths...)
+}
+
+// GinMiddleware logs requests with the package-level logger
+func GinMiddleware(skipPaths ...string) gin.HandlerFunc {
+	return StdLogger().GinMiddleware(skipPaths...)
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ncobase/common/consts"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	var handlerTrace string
+	h := l.HTTPMiddleware("/healthz")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerTrace = getTraceID(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(consts.TraceKey, "trace-1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if handlerTrace != "trace-1" || rw.Header().Get(consts.TraceKey) != "trace-1" {
+		t.Errorf("expected trace id propagated, handler %q, header %q", handlerTrace, rw.Header().Get(consts.TraceKey))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "warning" || entry[HTTPStatusKey] != float64(404) || entry[HTTPBytesKey] != float64(7) {
+		t.Errorf("unexpected status fields %v", entry)
+	}
+	if entry[HTTPMethodKey] != "GET" || entry[HTTPPathKey] != "/users/1" || entry[HTTPClientIPKey] != "203.0.113.7" {
+		t.Errorf("unexpected request fields %v", entry)
+	}
+	if entry[traceKey] != "trace-1" {
+		t.Errorf("expected trace_id, got %v", entry[traceKey])
+	}
+
+	buf.Reset()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if buf.Len() != 0 {
+		t.Errorf("expected skipped path not logged, got %q", buf.String())
+	}
+}
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	r := gin.New()
+	r.Use(l.GinMiddleware("/healthz"))
+	r.GET("/orders", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	trace := rw.Header().Get(consts.TraceKey)
+	if trace == "" {
+		t.Fatal("expected generated trace id in response header")
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid output %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "info" || entry[HTTPStatusKey] != float64(200) || entry[traceKey] != trace {
+		t.Errorf("unexpected entry %v", entry)
+	}
+}
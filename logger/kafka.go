@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/util"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// kafkaWriter is the part of kafka.Writer used by the hook
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaHook publishes entries as JSON messages to a Kafka topic
+//
+// The producer is asynchronous, Fire only queues the message and delivery
+// results are counted in the background. Messages with the same key, the
+// value of the configured key field such as trace_id, go to the same partition.
+type KafkaHook struct {
+	writer    kafkaWriter
+	keyField  string
+	delivered atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewKafkaHook creates a Kafka hook with an async producer
+func NewKafkaHook(c *config.LoggerKafka) *KafkaHook {
+	h := &KafkaHook{keyField: c.KeyField}
+	h.writer = &kafka.Writer{
+		Addr:         kafka.TCP(c.Brokers...),
+		Topic:        c.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: c.BatchTimeout,
+		Async:        true,
+		Completion:   h.complete,
+	}
+	return h
+}
+
+// Levels returns all log levels
+func (h *KafkaHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire queues the entry for publishing
+func (h *KafkaHook) Fire(entry *logrus.Entry) error {
+	data := util.CopyMap(entry.Data)
+	data[logrus.FieldKeyMsg] = entry.Message
+	data[logrus.FieldKeyLevel] = entry.Level.String()
+	data[logrus.FieldKeyTime] = entry.Time.Format(time.RFC3339Nano)
+
+	value, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log data: %w", err)
+	}
+
+	msg := kafka.Message{Value: value, Time: entry.Time}
+	if key, ok := entry.Data[h.keyField]; ok && h.keyField != "" {
+		msg.Key = []byte(fmt.Sprint(key))
+	}
+	return h.writer.WriteMessages(context.Background(), msg)
+}
+
+// complete counts delivery results reported by the async producer
+func (h *KafkaHook) complete(msgs []kafka.Message, err error) {
+	if err != nil {
+		h.failed.Add(int64(len(msgs)))
+		return
+	}
+	h.delivered.Add(int64(len(msgs)))
+}
+
+// Delivered returns the number of messages acknowledged by Kafka
+func (h *KafkaHook) Delivered() int64 {
+	return h.delivered.Load()
+}
+
+// Failed returns the number of messages that could not be delivered
+func (h *KafkaHook) Failed() int64 {
+	return h.failed.Load()
+}
+
+// Close flushes pending messages and closes the producer
+func (h *KafkaHook) Close() error {
+	return h.writer.Close()
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeKafkaWriter records messages and reports them to the completion callback
+type fakeKafkaWriter struct {
+	msgs     []kafka.Message
+	err      error
+	complete func([]kafka.Message, error)
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	w.complete(msgs, w.err)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error { return nil }
+
+func TestKafkaHook_Fire(t *testing.T) {
+	h := &KafkaHook{keyField: "trace_id"}
+	w := &fakeKafkaWriter{complete: h.complete}
+	h.writer = w
+
+	entry := logrus.NewEntry(logrus.New()).WithField("trace_id", "t-1")
+	entry.Message, entry.Level = "paid", logrus.InfoLevel
+	if err := h.Fire(entry); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	w.err = errors.New("broker down")
+	_ = h.Fire(logrus.NewEntry(logrus.New()))
+
+	if len(w.msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(w.msgs))
+	}
+	if string(w.msgs[0].Key) != "t-1" || w.msgs[1].Key != nil {
+		t.Errorf("unexpected keys %q and %q", w.msgs[0].Key, w.msgs[1].Key)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(w.msgs[0].Value, &doc); err != nil {
+		t.Fatalf("invalid message value: %v", err)
+	}
+	if doc["msg"] != "paid" || doc["level"] != "info" || doc["trace_id"] != "t-1" {
+		t.Errorf("unexpected message value: %v", doc)
+	}
+
+	if h.Delivered() != 1 || h.Failed() != 1 {
+		t.Errorf("expected 1 delivered and 1 failed, got %d and %d", h.Delivered(), h.Failed())
+	}
+}
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelBody is the JSON body of the level handler
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an admin handler reading and changing the log level
+//
+// GET returns {"level":"info"}, PUT or POST with {"level":"debug"} or
+// ?level=debug changes it. The handler has no authentication of its own,
+// mount it on an internal or protected route.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			name := r.URL.Query().Get("level")
+			if name == "" {
+				var body levelBody
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				name = body.Level
+			}
+			level, err := logrus.ParseLevel(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if level != l.GetLevel() {
+				l.Logger.Warnf("Log level changed from %s to %s", l.GetLevel(), level)
+				l.SetLevel(level)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelBody{Level: l.GetLevel().String()})
+	})
+}
+
+// ReloadLevelOnSIGHUP sets the level returned by load whenever the process
+// receives SIGHUP, e.g. after re-reading the config file, until stop is called
+func (l *Logger) ReloadLevelOnSIGHUP(load func() (logrus.Level, error)) (stop func()) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			select {
+			case <-sig:
+				level, err := load()
+				if err != nil {
+					l.Logger.Errorf("Failed to reload log level: %v", err)
+					continue
+				}
+				l.Logger.Warnf("Log level reloaded on SIGHUP: %s", level)
+				l.SetLevel(level)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sig)
+		cancel()
+	}
+}
+
+// SetLevel sets the level of the package-level logger
+func SetLevel(level logrus.Level) { StdLogger().SetLevel(level) }
+
+// GetLevel returns the level of the package-level logger
+func GetLevel() logrus.Level { return StdLogger().GetLevel() }
+
+// LevelHandler returns the level admin handler of the package-level logger
+func LevelHandler() http.Handler { return StdLogger().LevelHandler() }
+
+// ReloadLevelOnSIGHUP reloads the level of the package-level logger on SIGHUP
+func ReloadLevelOnSIGHUP(load func() (logrus.Level, error)) func() {
+	return StdLogger().ReloadLevelOnSIGHUP(load)
+}
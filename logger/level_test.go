@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLevelHandler(t *testing.T) {
+	l := newLogger()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.InfoLevel)
+	h := l.LevelHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"level":"info"}` {
+		t.Errorf("unexpected GET body: %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK || l.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected debug level after PUT, got %d %s", rec.Code, l.GetLevel())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/log/level?level=warn", nil))
+	if l.GetLevel() != logrus.WarnLevel {
+		t.Errorf("expected warn level from query, got %s", l.GetLevel())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/log/level?level=loud", nil))
+	if rec.Code != http.StatusBadRequest || l.GetLevel() != logrus.WarnLevel {
+		t.Errorf("expected invalid level to be rejected, got %d %s", rec.Code, l.GetLevel())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/log/level", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for DELETE, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package logger
+
+import (
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestReloadLevelOnSIGHUP(t *testing.T) {
+	l := newLogger()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.InfoLevel)
+
+	stop := l.ReloadLevelOnSIGHUP(func() (logrus.Level, error) {
+		return logrus.DebugLevel, nil
+	})
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Skipf("cannot send SIGHUP: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for l.GetLevel() != logrus.DebugLevel && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if l.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected debug level after SIGHUP, got %s", l.GetLevel())
+	}
+}
@@ -3,14 +3,17 @@ package logger
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"ncobase/common/data/elastic"
 	"ncobase/common/data/meili"
+	"ncobase/common/helper"
+	"ncobase/common/uuid"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"ncobase/common/config"
@@ -18,22 +21,52 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultShutdownTimeout is the max time cleanup waits for hooks to flush
+const DefaultShutdownTimeout = 5 * time.Second
+
 // Key constants
 const (
 	VersionKey      = "version"
+	HostKey         = "host"
+	PIDKey          = "pid"
 	SpanTitleKey    = "title"
 	SpanFunctionKey = "function"
+	MeiliIDKey      = "id"   // primary key of log documents in Meilisearch
+	MeiliTimeKey    = "time" // unix seconds of log documents in Meilisearch, filterable for retention
 )
 
-// Logger represents logger instance
+// Logger represents logger instance, or a module child logger created by Named
 type Logger struct {
 	*logrus.Logger
-	version     string
-	logFile     *os.File
-	logPath     string
-	meiliClient *meili.Client
-	esClient    *elastic.Client
-	indexName   string // Meilisearch / Elasticsearch index name
+	version      string
+	logFile      *rotatingFile
+	fileBuf      *BufferedWriter // buffers the writes to logFile, nil when unbuffered
+	logPath      string
+	rotate       RotateOptions
+	meiliClient  *meili.Client
+	esClient     *elastic.Client
+	indexName    string        // Meilisearch / Elasticsearch index name
+	static       logrus.Fields // fields resolved once at Init, e.g. host and pid
+	custom       io.Writer     // writer used by the "custom" output
+	searchOff    atomic.Bool   // set while search hooks are paused
+	backend      atomic.Pointer[backendHolder]
+	module       string                  // module name of a child logger
+	parent       *Logger                 // root logger of a child logger
+	ownLevel     bool                    // child level set explicitly, guarded by the parent childMu
+	childMu      sync.Mutex              // guards children and modules
+	children     map[string]*Logger      // child loggers by module
+	modules      map[string]logrus.Level // configured module levels
+	redactor     *Redactor               // masks sensitive values, nil when disabled
+	stack        StackOptions            // stack trace capture of Error and above
+	reportCaller bool                    // add the source location of the logging call
+	sampler      *Sampler                // drops entries by level, nil when sampling is disabled
+	schedule     rotateSchedule          // time-based rotation of the log file
+	hookLevels   map[string]logrus.Level // minimum level of the hooks by name
+	templates    bool                    // formatted entries carry their template in the context, for the Sentry hook
+	dedup        *Deduper                // collapses duplicate entries, nil when disabled
+	slowQuery    time.Duration           // ORM queries logged as slow above it
+	audit        *AuditLog               // tamper-evident audit trail, nil when disabled
+	exitCleanup  atomic.Pointer[func()]  // cleanup of the last Init, run by Fatal before exiting
 }
 
 var (
@@ -46,142 +79,595 @@ var (
 // StdLogger returns the single logger instance
 func StdLogger() *Logger {
 	once.Do(func() {
-		stdLogger = &Logger{
-			Logger: logrus.New(),
-		}
-		stdLogger.SetFormatter(&logrus.JSONFormatter{})
+		stdLogger = newLogger()
 	})
 	return stdLogger
 }
 
+// newLogger creates a logger with its own logrus instance, hooks and output
+func newLogger() *Logger {
+	l := &Logger{
+		Logger: logrus.New(),
+	}
+	l.SetFormatter(&DurationFormatter{Formatter: &logrus.JSONFormatter{}})
+	l.ExitFunc = l.exit
+	return l
+}
+
+// exit is the ExitFunc of a logger, run by Fatal after the handlers of
+// RegisterExitHandler, it runs the cleanup of the last Init of this logger
+// only, since os.Exit skips defers, and exits the process
+func (l *Logger) exit(code int) {
+	if cleanup := l.exitCleanup.Load(); cleanup != nil {
+		(*cleanup)()
+	}
+	os.Exit(code)
+}
+
+// New creates an independent logger that does not share state with the
+// package-level logger, useful for libraries and parallel tests
+func New(c *config.Logger) (*Logger, func(), error) {
+	l := newLogger()
+	cleanup, err := l.Init(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, cleanup, nil
+}
+
 // SetVersion sets the version for logging
 func (l *Logger) SetVersion(v string) {
 	l.version = v
 }
 
 // Init initializes the logger with the given configuration
-func (l *Logger) Init(c *config.Logger) (func(), error) {
+func (l *Logger) Init(c *config.Logger) (_ func(), err error) {
 	l.SetLevel(logrus.Level(c.Level))
+	if err := l.setModuleLevels(c.Modules); err != nil {
+		return nil, err
+	}
+	l.static = staticFields(c)
+	if c.Stack != nil {
+		l.stack = StackOptions{Enabled: c.Stack.Enabled, Skip: c.Stack.Skip, Depth: c.Stack.Depth}
+	}
+	l.reportCaller = c.ReportCaller
+	l.slowQuery = c.SlowQueryThreshold
+	sampler, err := newSamplerFromConfig(c.Sampling)
+	if err != nil {
+		return nil, err
+	}
+	l.sampler = sampler
+	if l.schedule, err = parseRotateSchedule(c.RotateSchedule, c.RotateTimezone); err != nil {
+		return nil, err
+	}
+	done := make(chan struct{}) // closed by cleanup to stop background work
+	var closers []func()        // flush and stop async hooks on cleanup
+
+	// Return cleanup function, also run on Fatal since os.Exit skips defers
+	timeout := c.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	var closeOnce sync.Once
+	cleanup := func() {
+		closeOnce.Do(func() {
+			close(done)
+			if !closeAll(closers, timeout) {
+				_, _ = fmt.Fprintf(os.Stderr, "logger: hooks did not flush within %s, pending entries may be lost\n", timeout)
+			}
+			if l.fileBuf != nil {
+				_ = l.fileBuf.Flush()
+			}
+			if l.logFile != nil {
+				_ = l.logFile.Close()
+			}
+		})
+	}
+
+	// A failed Init stops what it started and removes the hooks it added
+	hooks := make(logrus.LevelHooks, len(l.Hooks))
+	for level, list := range l.Hooks {
+		hooks[level] = append([]logrus.Hook(nil), list...)
+	}
+	out, dedup := l.Out, l.dedup
+	defer func() {
+		if err != nil {
+			cleanup()
+			l.logFile, l.fileBuf, l.dedup = nil, nil, dedup
+			l.Logger.ReplaceHooks(hooks)
+			l.SetOutput(out)
+		}
+	}()
+
+	if c.Dedup != nil && c.Dedup.Window > 0 {
+		l.dedup = newDeduper(c.Dedup.Window, c.Dedup.Keys, l.logRepeats)
+		go l.dedup.run(done)
+	}
+
+	l.SetFormatter(newFormatter(c, c.Format))
+	if l.hookLevels, err = parseHookLevels(c.HookLevels); err != nil {
+		return nil, err
+	}
 
-	switch c.Format {
-	case "json":
-		l.SetFormatter(&logrus.JSONFormatter{})
-	default:
-		l.SetFormatter(&logrus.TextFormatter{})
+	if c.Metrics {
+		l.AddHook(MetricsHook{})
 	}
 
-	switch c.Output {
+	// Redact before any output or hook sees the entry
+	if c.Redact != nil && (len(c.Redact.Fields) > 0 || len(c.Redact.Patterns) > 0) {
+		r, err := NewRedactor(c.Redact)
+		if err != nil {
+			return nil, err
+		}
+		l.redactor = r
+		l.AddHook(NewSafeHook("redact", r))
+	}
+	// Span events leave the process, record them from the redacted entry
+	if c.SpanEvents {
+		l.AddHook(SpanEventHook{})
+	}
+
+	output := c.Output
+	if len(c.Outputs) > 0 {
+		output = "tee"
+	}
+
+	switch output {
 	case "stdout":
 		l.SetOutput(os.Stdout)
 	case "stderr":
 		l.SetOutput(os.Stderr)
+	case "custom":
+		if l.custom == nil {
+			return nil, errors.New("custom log output requires SetCustomWriter before Init")
+		}
+		l.SetOutput(l.custom)
+	case "syslog":
+		if c.Syslog == nil {
+			return nil, errors.New("syslog output requires syslog config")
+		}
+		hook, err := NewSyslogHook(c.Syslog, l.Formatter)
+		if err != nil {
+			return nil, err
+		}
+		l.SetOutput(io.Discard)
+		l.addHook("syslog", hook)
+		closers = append(closers, func() { _ = hook.Close() })
 	case "file":
 		l.logPath = c.OutputFile
+		l.rotate = l.rotateOptions(c)
 		if l.logPath != "" {
 			if err := l.setupLogFile(); err != nil {
-				return nil, err
+				if !c.FallbackToStderr {
+					return nil, err
+				}
+				l.SetOutput(os.Stderr)
+				l.Logger.Warnf("Log file %s unavailable, falling back to stderr: %v", l.logPath, err)
+			} else {
+				if c.FileBuffer != nil && c.FileBuffer.SizeKB > 0 {
+					if err := l.bufferLogFile(c.FileBuffer, done); err != nil {
+						return nil, err
+					}
+				}
+				go l.periodicLogRotation(done)
 			}
-			go l.periodicLogRotation()
 		}
+	case "tee":
+		teeClosers, err := l.setupTee(c, done)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, teeClosers...)
 	}
 
+	document := searchDocument(c)
+
 	// Initialize MeiliSearch client
-	if c.Meilisearch.Host != "" {
+	if c.Meilisearch != nil && c.Meilisearch.Host != "" {
 		l.meiliClient = meili.NewMeilisearch(c.Meilisearch.Host, c.Meilisearch.APIKey)
 		l.indexName = c.IndexName
-		l.AddHook(&MeiliSearchHook{
-			client: l.meiliClient,
-			index:  l.indexName,
-		})
+		if c.Search != nil {
+			client, index := l.meiliClient, l.indexName
+			hook := newBatchHook(c.Search, func(docs []any) error {
+				return client.IndexDocuments(index, withDocumentIDs(docs), MeiliIDKey)
+			})
+			hook.paused = &l.searchOff
+			hook.doc = func(entry *logrus.Entry) any { return meiliDocument(document, entry) }
+			if err := hook.withSpool(c.Spool, "meilisearch"); err != nil {
+				return nil, err
+			}
+			l.addBatchHook("meilisearch", hook)
+			closers = append(closers, hook.Close)
+		} else {
+			l.addHook("meilisearch", &MeiliSearchHook{
+				client: l.meiliClient,
+				index:  l.indexName,
+				paused: &l.searchOff,
+				doc:    document,
+			})
+		}
 	}
 
 	// Initialize Elasticsearch client
-	if len(c.Elasticsearch.Addresses) > 0 {
+	if c.Elasticsearch != nil && len(c.Elasticsearch.Addresses) > 0 {
 		var err error
 		l.esClient, err = elastic.NewClient(c.Elasticsearch.Addresses, c.Elasticsearch.Username, c.Elasticsearch.Password)
 		if err != nil {
 			return nil, fmt.Errorf("error initializing Elasticsearch client: %w", err)
 		}
 		l.indexName = c.IndexName
-		l.AddHook(&ElasticSearchHook{
-			client: l.esClient,
-			index:  l.indexName,
-		})
+		if c.Search != nil {
+			client, index := l.esClient, l.indexName
+			var opts []elastic.IndexOption
+			if c.Pipeline != "" {
+				opts = append(opts, elastic.WithPipeline(c.Pipeline))
+			}
+			hook := newBatchHook(c.Search, func(docs []any) error {
+				return client.BulkIndex(context.Background(), index, docs, opts...)
+			})
+			hook.paused = &l.searchOff
+			hook.doc = func(entry *logrus.Entry) any { return document(entry) }
+			if err := hook.withSpool(c.Spool, "elasticsearch"); err != nil {
+				return nil, err
+			}
+			l.addBatchHook("elasticsearch", hook)
+			closers = append(closers, hook.Close)
+		} else {
+			l.addHook("elasticsearch", &ElasticSearchHook{
+				client:   l.esClient,
+				index:    l.indexName,
+				pipeline: c.Pipeline,
+				paused:   &l.searchOff,
+				doc:      document,
+			})
+		}
+	}
+
+	// Initialize Loki hook
+	if c.Loki != nil && c.Loki.URL != "" {
+		hook := newLokiHook(c.Loki, c.Search)
+		l.addBatchHook("loki", hook)
+		closers = append(closers, hook.Close)
+	}
+
+	// Initialize CloudWatch Logs hook
+	if c.CloudWatch != nil && c.CloudWatch.LogGroup != "" {
+		hook, err := newCloudWatchHook(c.CloudWatch, c.Search)
+		if err != nil {
+			return nil, err
+		}
+		l.addBatchHook("cloudwatch", hook)
+		closers = append(closers, hook.Close)
+	}
+
+	// Initialize Kafka hook
+	if c.Kafka != nil && c.Kafka.Topic != "" && len(c.Kafka.Brokers) > 0 {
+		hook := NewKafkaHook(c.Kafka)
+		l.addHook("kafka", hook)
+		closers = append(closers, func() { _ = hook.Close() })
+	}
+
+	// Initialize OTLP log exporter
+	if c.OTLP != nil && c.OTLP.Endpoint != "" {
+		hook, err := NewOTLPHook(c.OTLP)
+		if err != nil {
+			return nil, err
+		}
+		l.addHook("otlp", hook)
+		closers = append(closers, func() { _ = hook.Close() })
+	}
+
+	// Initialize Fluentd hook
+	if c.Fluentd != nil && c.Fluentd.Host != "" {
+		hook := NewFluentdHook(c.Fluentd)
+		l.addHook("fluentd", hook)
+		closers = append(closers, func() { _ = hook.Close() })
+	}
+
+	// Initialize Graylog hook
+	if c.Graylog != nil && c.Graylog.Address != "" {
+		hook, err := NewGraylogHook(c.Graylog)
+		if err != nil {
+			return nil, err
+		}
+		l.addHook("graylog", hook)
+		closers = append(closers, func() { _ = hook.Close() })
+	}
+
+	// Initialize Sentry hook
+	if c.Sentry != nil && c.Sentry.DSN != "" {
+		hook, err := NewSentryHook(c.Sentry)
+		if err != nil {
+			return nil, err
+		}
+		l.addHook("sentry", hook)
+		l.templates = true
+		closers = append(closers, func() { _ = hook.Close() })
 	}
 
-	// Return cleanup function
-	return func() {
-		if l.logFile != nil {
-			_ = l.logFile.Close()
+	// Open the audit log, kept apart from the outputs and hooks above
+	if c.Audit != nil && c.Audit.Path != "" {
+		audit, err := OpenAuditLog(c.Audit.Path)
+		if err != nil {
+			return nil, err
 		}
-	}, nil
+		l.audit = audit
+		closers = append(closers, func() { _ = audit.Close() })
+	}
+
+	l.exitCleanup.Store(&cleanup)
+
+	return cleanup, nil
 }
 
-// setupLogFile sets up the log file
-func (l *Logger) setupLogFile() error {
-	if err := os.MkdirAll(filepath.Dir(l.logPath), 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
+// closeAll runs the closers concurrently, so a slow hook doesn't delay the
+// others, and reports whether they all returned within timeout
+func closeAll(closers []func(), timeout time.Duration) bool {
+	var wg sync.WaitGroup
+	for _, c := range closers {
+		wg.Add(1)
+		go func(c func()) {
+			defer wg.Done()
+			c()
+		}(c)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(closed)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-closed:
+		return true
+	case <-timer.C:
+		return false
 	}
-	return l.rotateLog()
 }
 
-// rotateLog rotates the log
-func (l *Logger) rotateLog() error {
-	if l.logFile != nil {
-		if err := l.logFile.Close(); err != nil {
-			return fmt.Errorf("failed to close current log file: %w", err)
+// addBatchHook starts the batch hook and adds it, send failures are
+// reported like other hook errors
+func (l *Logger) addBatchHook(name string, hook *BatchHook) {
+	safe := NewSafeHook(name, l.filterHook(name, hook))
+	hook.onError = safe.report
+	hook.start()
+	l.AddHook(safe)
+}
+
+// addHook adds the named hook, wrapped in a SafeHook and restricted to its
+// configured minimum level
+func (l *Logger) addHook(name string, hook logrus.Hook) {
+	l.AddHook(NewSafeHook(name, l.filterHook(name, hook)))
+}
+
+// staticFields resolves the fields that never change during the process lifetime
+func staticFields(c *config.Logger) logrus.Fields {
+	fields := logrus.Fields{}
+	if c.IncludeHost {
+		if host, err := os.Hostname(); err == nil {
+			fields[HostKey] = host
 		}
 	}
+	if c.IncludePID {
+		fields[PIDKey] = os.Getpid()
+	}
+	return fields
+}
+
+// rotateOptions resolves the log file rotation options, removed files are logged
+func (l *Logger) rotateOptions(c *config.Logger) RotateOptions {
+	return RotateOptions{
+		MaxSizeMB:  c.MaxSizeMB,
+		MaxBackups: c.MaxBackups,
+		MaxAgeDays: c.MaxAgeDays,
+		Compress:   c.Compress,
+		DryRun:     c.RetentionDryRun,
+		Layout:     l.schedule.layout(),
+		Location:   l.schedule.loc,
+		Removed: func(path string, dryRun bool) {
+			if dryRun {
+				l.Logger.Infof("Log retention would remove %s (dry run)", path)
+				return
+			}
+			l.Logger.Infof("Log retention removed %s", path)
+		},
+	}
+}
 
-	logFilePath := fmt.Sprintf("%s.%s.log", strings.TrimSuffix(l.logPath, ".log"), time.Now().Format("2006-01-02"))
-	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open new log file: %w", err)
+// setupLogFile opens the log file and uses it as output
+func (l *Logger) setupLogFile() error {
+	if err := l.openLogFile(); err != nil {
+		return err
 	}
+	l.SetOutput(l.logFile)
+	return nil
+}
 
+// openLogFile opens the log file, closing the previous one
+func (l *Logger) openLogFile() error {
+	if err := os.MkdirAll(filepath.Dir(l.logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if l.logFile != nil {
+		_ = l.logFile.Close()
+	}
+	f, err := openRotatingFile(l.logPath, l.rotate)
+	if err != nil {
+		return err
+	}
 	l.logFile = f
-	l.SetOutput(l.logFile)
 	return nil
 }
 
-// periodicLogRotation rotates the log every 24 hours
-func (l *Logger) periodicLogRotation() {
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
+// rotateLog rotates the log
+func (l *Logger) rotateLog() error {
+	if l.logFile == nil {
+		return l.setupLogFile()
+	}
+	if l.fileBuf != nil {
+		if err := l.fileBuf.Flush(); err != nil {
+			return err
+		}
+	}
+	return l.logFile.Rotate()
+}
+
+// periodicLogRotation rotates the log at every boundary of the schedule
+func (l *Logger) periodicLogRotation(done <-chan struct{}) {
+	timer := time.NewTimer(time.Until(l.schedule.next(time.Now())))
+	defer timer.Stop()
 
-	for range ticker.C {
-		if err := l.rotateLog(); err != nil {
-			l.Logger.Errorf("Error rotating log: %v", err)
+	for {
+		select {
+		case <-timer.C:
+			if err := l.rotateLog(); err != nil {
+				l.Logger.Errorf("Error rotating log: %v", err)
+			}
+			timer.Reset(time.Until(l.schedule.next(time.Now())))
+		case <-done:
+			return
 		}
 	}
 }
 
 // entryFromContext creates a new log entry with fields from context
 func (l *Logger) entryFromContext(ctx context.Context) *logrus.Entry {
-	fields := logrus.Fields{}
+	return l.WithContext(ctx).WithFields(l.contextFields(ctx))
+}
+
+// contextFields resolves the static, version, trace, span, identity,
+// extracted and WithField fields for ctx
+func (l *Logger) contextFields(ctx context.Context) logrus.Fields {
+	root := l.root()
+	fields := make(logrus.Fields, len(root.static)+3)
+	for k, v := range root.static {
+		fields[k] = v
+	}
+	if l.module != "" {
+		fields[ModuleKey] = l.module
+	}
 
 	traceID := getTraceID(ctx)
 	if traceID != "" {
 		fields[traceKey] = traceID
 	}
+	withSpan(ctx, fields)
 
-	if l.version != "" {
-		fields[VersionKey] = l.version
+	if root.version != "" {
+		fields[VersionKey] = root.version
 	}
 
-	return l.WithFields(fields)
+	if id := helper.GetUserID(ctx); id != "" {
+		fields[UserIDKey] = id
+	}
+	if id := helper.GetTenantID(ctx); id != "" {
+		fields[TenantIDKey] = id
+	}
+
+	for _, e := range contextExtractors() {
+		if v, ok := e.fn(ctx); ok {
+			fields[e.name] = v
+		}
+	}
+
+	for k, v := range contextFieldValues(ctx) {
+		fields[k] = v
+	}
+
+	return fields
 }
 
 // Log methods
 
+// levelFields resolves the context fields plus the caller and stack of the level
+func (l *Logger) levelFields(ctx context.Context, level logrus.Level) logrus.Fields {
+	return l.withStack(l.withCaller(l.contextFields(ctx)), level)
+}
+
 // Log logs a message with the given level
 func (l *Logger) log(ctx context.Context, level logrus.Level, args ...any) {
-	l.entryFromContext(ctx).Log(level, args...)
+	if b := l.currentBackend(); b != nil {
+		if l.backendEnabled(b, level) {
+			l.logBackend(ctx, b, level, sprint(args))
+		}
+		return
+	}
+	if !l.IsLevelEnabled(level) || !l.sampled(level) {
+		return
+	}
+	fields := l.levelFields(ctx, level)
+	if l.root().dedup != nil {
+		msg := fmt.Sprint(args...)
+		if l.deduped(level, msg, fields) {
+			return
+		}
+		l.WithContext(ctx).WithFields(fields).Log(level, msg)
+		return
+	}
+	l.WithContext(ctx).WithFields(fields).Log(level, args...)
 }
 
 // Logf logs a formatted message
 func (l *Logger) logf(ctx context.Context, level logrus.Level, format string, args ...any) {
-	l.entryFromContext(ctx).Logf(level, format, args...)
+	if b := l.currentBackend(); b != nil {
+		if l.backendEnabled(b, level) {
+			l.logBackend(ctx, b, level, fmt.Sprintf(format, args...))
+		}
+		return
+	}
+	if !l.IsLevelEnabled(level) || !l.sampled(level) {
+		return
+	}
+	if l.root().templates {
+		ctx = withTemplate(ctx, format)
+	}
+	fields := l.levelFields(ctx, level)
+	if l.root().dedup != nil {
+		msg := fmt.Sprintf(format, args...)
+		if l.deduped(level, msg, fields) {
+			return
+		}
+		l.WithContext(ctx).WithFields(fields).Log(level, msg)
+		return
+	}
+	l.WithContext(ctx).WithFields(fields).Logf(level, format, args...)
+}
+
+// backendEnabled reports whether a record at level passes the logger level,
+// the backend level and sampling
+func (l *Logger) backendEnabled(b Backend, level logrus.Level) bool {
+	return l.IsLevelEnabled(level) && b.Enabled(level) && l.sampled(level)
+}
+
+// logBackend writes a record to the backend, then panics for PanicLevel like logrus does
+func (l *Logger) logBackend(ctx context.Context, b Backend, level logrus.Level, msg string) {
+	buf := fieldPool.Get().(*[]Field)
+	fields := l.backendFields(ctx, level, (*buf)[:0])
+	if r := l.root().redactor; r != nil {
+		r.redactTyped(fields)
+		msg = r.redactString(msg)
+	}
+	b.Log(level, fields, msg)
+
+	clear(fields)
+	*buf = fields[:0]
+	fieldPool.Put(buf)
+	if level == logrus.PanicLevel {
+		panic(msg)
+	}
+}
+
+// sprint formats args like fmt.Sprint, a single string is returned as is
+func sprint(args []any) string {
+	if len(args) == 1 {
+		if s, ok := args[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(args...)
 }
 
 // Trace logs a trace message
@@ -212,6 +698,7 @@ func (l *Logger) Error(ctx context.Context, args ...any) {
 // Fatal logs a fatal message
 func (l *Logger) Fatal(ctx context.Context, args ...any) {
 	l.log(ctx, logrus.FatalLevel, args...)
+	l.Exit(1)
 }
 
 // Panic logs a panic message
@@ -247,6 +734,7 @@ func (l *Logger) Errorf(ctx context.Context, format string, args ...any) {
 // Fatalf logs a fatal message with format
 func (l *Logger) Fatalf(ctx context.Context, format string, args ...any) {
 	l.logf(ctx, logrus.FatalLevel, format, args...)
+	l.Exit(1)
 }
 
 // Panicf logs a panic message with format
@@ -260,6 +748,8 @@ func (l *Logger) Panicf(ctx context.Context, format string, args ...any) {
 type MeiliSearchHook struct {
 	client *meili.Client
 	index  string
+	paused *atomic.Bool
+	doc    func(entry *logrus.Entry) map[string]any // default DefaultDocumentMapping
 }
 
 // Levels returns all log levels
@@ -269,17 +759,51 @@ func (h *MeiliSearchHook) Levels() []logrus.Level {
 
 // Fire sends log entry to MeiliSearch
 func (h *MeiliSearchHook) Fire(entry *logrus.Entry) error {
-	jsonData, err := json.Marshal(entry.Data)
+	if h.paused != nil && h.paused.Load() {
+		return nil
+	}
+	jsonData, err := json.Marshal(meiliDocument(h.doc, entry))
 	if err != nil {
 		return fmt.Errorf("failed to marshal log data: %w", err)
 	}
 	return h.client.IndexDocuments(h.index, jsonData)
 }
 
+// hookDocument builds the document of a sync search hook
+func hookDocument(doc func(entry *logrus.Entry) map[string]any, entry *logrus.Entry) map[string]any {
+	if doc == nil {
+		return DefaultDocumentMapping.Document(entry)
+	}
+	return doc(entry)
+}
+
+// meiliDocument builds the document of a Meilisearch hook, it adds the entry
+// time as unix seconds since Meilisearch compares numbers only, so retention
+// can delete with a "time < N" filter
+func meiliDocument(doc func(entry *logrus.Entry) map[string]any, entry *logrus.Entry) map[string]any {
+	m := hookDocument(doc, entry)
+	m[MeiliTimeKey] = entry.Time.Unix()
+	return m
+}
+
+// withDocumentIDs sets a unique MeiliIDKey on each document, Meilisearch
+// needs a primary key and entry data has no unique field of its own
+func withDocumentIDs(docs []any) []any {
+	for _, d := range docs {
+		if m, ok := d.(map[string]any); ok {
+			m[MeiliIDKey] = uuid.NewString()
+		}
+	}
+	return docs
+}
+
 // ElasticSearchHook represents an Elasticsearch log hook
 type ElasticSearchHook struct {
-	client *elastic.Client
-	index  string
+	client   *elastic.Client
+	index    string
+	pipeline string
+	paused   *atomic.Bool
+	doc      func(entry *logrus.Entry) map[string]any // default DefaultDocumentMapping
 }
 
 // Levels returns all log levels
@@ -289,18 +813,50 @@ func (h *ElasticSearchHook) Levels() []logrus.Level {
 
 // Fire sends log entry to Elasticsearch
 func (h *ElasticSearchHook) Fire(entry *logrus.Entry) error {
-	return h.client.IndexDocument(context.Background(), h.index, entry.Time.Format(time.RFC3339), entry.Data)
+	if h.paused != nil && h.paused.Load() {
+		return nil
+	}
+	var opts []elastic.IndexOption
+	if h.pipeline != "" {
+		opts = append(opts, elastic.WithPipeline(h.pipeline))
+	}
+	return h.client.IndexDocument(context.Background(), h.index, entry.Time.Format(time.RFC3339), hookDocument(h.doc, entry), opts...)
+}
+
+// PauseSearchHooks stops forwarding entries to Meilisearch and Elasticsearch,
+// e.g. while reindexing, local output is not affected and entries logged
+// while paused are not sent later
+func (l *Logger) PauseSearchHooks() {
+	l.searchOff.Store(true)
+}
+
+// ResumeSearchHooks resumes forwarding entries to Meilisearch and Elasticsearch
+func (l *Logger) ResumeSearchHooks() {
+	l.searchOff.Store(false)
+}
+
+// SearchHooksPaused reports whether search hooks are paused
+func (l *Logger) SearchHooksPaused() bool {
+	return l.searchOff.Load()
 }
 
 // SetOutput sets the output destination for the logger
 func (l *Logger) SetOutput(out io.Writer) {
 	l.Logger.SetOutput(out)
+	l.syncChildren()
+}
+
+// SetCustomWriter sets the writer used when the output is "custom", it
+// survives Init and bypasses file rotation
+func (l *Logger) SetCustomWriter(w io.Writer) {
+	l.custom = w
 }
 
 // AddHook adds a hook to the logger
 func (l *Logger) AddHook(hook logrus.Hook) {
 	if !l.hookExists(hook) {
 		l.Logger.AddHook(hook)
+		l.syncChildren()
 	}
 }
 
@@ -316,16 +872,28 @@ func (l *Logger) hookExists(hook logrus.Hook) bool {
 	return false
 }
 
+// EntryWithFields returns an entry with the context fields and the given fields
+func (l *Logger) EntryWithFields(ctx context.Context, fields logrus.Fields) *logrus.Entry {
+	return l.entryFromContext(ctx).WithFields(fields)
+}
+
 // SetVersion sets the version for logging
 func SetVersion(v string) { StdLogger().SetVersion(v) }
 
-// New creates new logger
-func New(c *config.Logger) (func(), error) { return StdLogger().Init(c) }
+// RegisterExitHandler registers a function run before Fatal exits the process
+//
+// Fatal calls os.Exit, which skips deferred calls, so critical cleanup such
+// as closing connections or flushing buffers must be registered here. The
+// handlers are global to the process and run by Fatal of any logger, the
+// cleanup of Init is run by Fatal of its own logger only.
+func RegisterExitHandler(handler func()) { logrus.RegisterExitHandler(handler) }
+
+// Init initializes the package-level logger
+func Init(c *config.Logger) (func(), error) { return StdLogger().Init(c) }
 
 // WithFields returns an entry with the given fields
 func WithFields(ctx context.Context, fields logrus.Fields) *logrus.Entry {
-	entry := StdLogger().entryFromContext(ctx)
-	return entry.WithFields(fields)
+	return StdLogger().EntryWithFields(ctx, fields)
 }
 
 // Trace logs trace message
@@ -384,8 +952,17 @@ func Panicf(ctx context.Context, format string, args ...any) {
 	StdLogger().Panicf(ctx, format, args...)
 }
 
+// PauseSearchHooks stops forwarding entries to Meilisearch and Elasticsearch
+func PauseSearchHooks() { StdLogger().PauseSearchHooks() }
+
+// ResumeSearchHooks resumes forwarding entries to Meilisearch and Elasticsearch
+func ResumeSearchHooks() { StdLogger().ResumeSearchHooks() }
+
 // SetOutput sets the output destination for the logger
 func SetOutput(out io.Writer) { StdLogger().SetOutput(out) }
 
+// SetCustomWriter sets the writer used when the output is "custom"
+func SetCustomWriter(w io.Writer) { StdLogger().SetCustomWriter(w) }
+
 // AddHook adds a hook to the logger
 func AddHook(hook logrus.Hook) { StdLogger().AddHook(hook) }
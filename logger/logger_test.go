@@ -0,0 +1,357 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+	dc "ncobase/common/data/config"
+	"ncobase/common/data/meili"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNew_IndependentLoggers(t *testing.T) {
+	a, cleanupA, err := New(&config.Logger{Level: int(logrus.InfoLevel), Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger a: %v", err)
+	}
+	defer cleanupA()
+
+	b, cleanupB, err := New(&config.Logger{Level: int(logrus.WarnLevel), Format: "text"})
+	if err != nil {
+		t.Fatalf("failed to create logger b: %v", err)
+	}
+	defer cleanupB()
+
+	var bufA, bufB bytes.Buffer
+	a.SetOutput(&bufA)
+	b.SetOutput(&bufB)
+
+	ctx := context.Background()
+	a.Infof(ctx, "hello from %s", "a")
+	b.Infof(ctx, "filtered by level")
+	b.EntryWithFields(ctx, logrus.Fields{"module": "b"}).Warn("hello from b")
+
+	if !strings.Contains(bufA.String(), "hello from a") || strings.Contains(bufA.String(), "hello from b") {
+		t.Errorf("unexpected output for logger a: %q", bufA.String())
+	}
+	if strings.Contains(bufB.String(), "filtered by level") {
+		t.Errorf("logger b should respect its own level, got %q", bufB.String())
+	}
+	if !strings.Contains(bufB.String(), "module=b") {
+		t.Errorf("expected fields in logger b output, got %q", bufB.String())
+	}
+	if a == StdLogger() || b == StdLogger() {
+		t.Error("New must not return the package-level logger")
+	}
+}
+
+func TestInit_CustomWriter(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger()
+	l.SetCustomWriter(&buf)
+
+	cleanup, err := l.Init(&config.Logger{Level: int(logrus.InfoLevel), Format: "json", Output: "custom"})
+	if err != nil {
+		t.Fatalf("unexpected init error: %v", err)
+	}
+	defer cleanup()
+
+	l.Info(context.Background(), "to the ring buffer")
+	if !strings.Contains(buf.String(), "to the ring buffer") {
+		t.Errorf("expected line in custom writer, got %q", buf.String())
+	}
+
+	if _, err := newLogger().Init(&config.Logger{Output: "custom"}); err == nil {
+		t.Error("expected error when custom writer is not set")
+	}
+}
+
+func TestInit_MeiliRetentionFilter(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		docs []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/indexes/app_log/documents":
+			// A single document or a batch
+			var body any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			batch, ok := body.([]any)
+			if !ok {
+				batch = []any{body}
+			}
+			for _, d := range batch {
+				if doc, ok := d.(map[string]any); ok {
+					docs = append(docs, doc)
+				}
+			}
+		case "/indexes/app_log/documents/delete":
+			// Like Meilisearch, a range filter only matches numbers
+			var req struct {
+				Filter string `json:"filter"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			var limit float64
+			if _, err := fmt.Sscanf(req.Filter, MeiliTimeKey+" < %g", &limit); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			kept := docs[:0]
+			for _, d := range docs {
+				if v, ok := d[MeiliTimeKey].(float64); !ok || v >= limit {
+					kept = append(kept, d)
+				}
+			}
+			docs = kept
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"taskUid":1,"indexUid":"app_log","status":"enqueued","type":"documentDeletion"}`)
+	}))
+	defer srv.Close()
+
+	l, cleanup, err := New(&config.Logger{
+		Level:       int(logrus.InfoLevel),
+		IndexName:   "app_log",
+		Meilisearch: &dc.Meilisearch{Host: srv.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	l.SetOutput(io.Discard)
+
+	now := time.Now()
+	l.EntryWithFields(context.Background(), logrus.Fields{}).WithTime(now.Add(-48 * time.Hour)).Info("old")
+	l.EntryWithFields(context.Background(), logrus.Fields{}).WithTime(now).Info("new")
+	cleanup()
+
+	filter := fmt.Sprintf("%s < %d", MeiliTimeKey, now.Add(-24*time.Hour).Unix())
+	if _, err := meili.NewMeilisearch(srv.URL, "").DeleteDocumentsByFilter("app_log", filter); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(docs) != 1 || docs[0][MeiliTimeKey] != float64(now.Unix()) {
+		t.Errorf("expected only the new entry to remain, got %v", docs)
+	}
+}
+
+func TestInit_FallbackToStderr(t *testing.T) {
+	// A regular file used as a directory makes the log path unwritable, even as root
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("failed to create blocker file: %v", err)
+	}
+	c := &config.Logger{Output: "file", OutputFile: filepath.Join(blocker, "logs", "app.log")}
+
+	if _, err := newLogger().Init(c); err == nil {
+		t.Fatal("expected init error with an unwritable log path")
+	}
+
+	c.FallbackToStderr = true
+	l := newLogger()
+	cleanup, err := l.Init(c)
+	if err != nil {
+		t.Fatalf("expected fallback instead of error, got %v", err)
+	}
+	defer cleanup()
+
+	if l.Out != os.Stderr {
+		t.Errorf("expected output to fall back to stderr, got %T", l.Out)
+	}
+	if l.logFile != nil {
+		t.Error("expected no log file to be open")
+	}
+}
+
+func TestPauseSearchHooks(t *testing.T) {
+	var indexed atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/_doc") {
+			indexed.Add(1)
+		}
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"result":"created"}`)
+	}))
+	defer srv.Close()
+
+	l, cleanup, err := New(&config.Logger{
+		Level:         int(logrus.InfoLevel),
+		Format:        "json",
+		IndexName:     "app_log",
+		Elasticsearch: &dc.Elasticsearch{Addresses: []string{srv.URL}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer cleanup()
+
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	ctx := context.Background()
+
+	l.PauseSearchHooks()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info(ctx, "during reindex")
+		}()
+	}
+	wg.Wait()
+
+	if n := indexed.Load(); n != 0 {
+		t.Fatalf("expected no documents while paused, got %d", n)
+	}
+	if got := strings.Count(buf.String(), "during reindex"); got != 10 {
+		t.Errorf("expected local logging to continue while paused, got %d lines", got)
+	}
+
+	l.ResumeSearchHooks()
+	l.Info(ctx, "after reindex")
+	if n := indexed.Load(); n != 1 {
+		t.Errorf("expected one document after resume, got %d", n)
+	}
+}
+
+func TestInit_MeiliBatching(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		docs     []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/indexes/app_log/documents" && r.URL.Query().Get("primaryKey") == MeiliIDKey {
+			requests++
+			var batch []map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&batch)
+			docs = append(docs, batch...)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"taskUid":1,"indexUid":"app_log","status":"enqueued","type":"documentAdditionOrUpdate"}`)
+	}))
+	defer srv.Close()
+
+	l, cleanup, err := New(&config.Logger{
+		Level:       int(logrus.InfoLevel),
+		IndexName:   "app_log",
+		Meilisearch: &dc.Meilisearch{Host: srv.URL},
+		Search:      &config.LoggerSearch{BatchSize: 100, BufferSize: 100, FlushInterval: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	l.SetOutput(io.Discard)
+
+	for i := 0; i < 5; i++ {
+		l.EntryWithFields(context.Background(), logrus.Fields{"n": i}).Info("batched")
+	}
+	cleanup()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 || len(docs) != 5 {
+		t.Fatalf("expected 5 documents in 1 request, got %d in %d", len(docs), requests)
+	}
+	ids := map[any]bool{}
+	for _, d := range docs {
+		ids[d[MeiliIDKey]] = true
+		if d["message"] != "batched" || d["level"] != "info" {
+			t.Errorf("expected message and level in document, got %v", d)
+		}
+		if _, ok := d[MeiliTimeKey].(float64); !ok {
+			t.Errorf("expected numeric %s in document, got %v", MeiliTimeKey, d[MeiliTimeKey])
+		}
+	}
+	if len(ids) != 5 {
+		t.Errorf("expected unique document ids, got %v", ids)
+	}
+}
+
+func TestInit_FailureCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+
+	l := newLogger()
+	var out bytes.Buffer
+	l.SetOutput(&out)
+	_, err := l.Init(&config.Logger{
+		Level:      int(logrus.InfoLevel),
+		Output:     "file",
+		OutputFile: filepath.Join(dir, "app.log"),
+		Dedup:      &config.LoggerDedup{Window: time.Minute},
+		Loki:       &config.LoggerLoki{URL: "http://127.0.0.1:1"},
+		Search:     &config.LoggerSearch{BatchSize: 10, BufferSize: 10, FlushInterval: time.Hour},
+		Audit:      &config.LoggerAudit{Path: filepath.Join(blocked, "audit.log")},
+	})
+	if err == nil {
+		t.Fatal("expected error for an audit log under a file")
+	}
+
+	if len(l.Hooks) != 0 {
+		t.Errorf("expected the hooks added by Init removed, got %v", l.Hooks)
+	}
+	if l.Out != &out || l.logFile != nil {
+		t.Error("expected the previous output restored and the log file closed")
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("expected background work stopped, %d goroutines left of %d", n, before)
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	var closed atomic.Int32
+	fast := func() { closed.Add(1) }
+	if !closeAll([]func(){fast, fast}, time.Second) {
+		t.Error("expected closers to finish within the deadline")
+	}
+	if closed.Load() != 2 {
+		t.Errorf("expected both closers run, got %d", closed.Load())
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := func() { <-release }
+	start := time.Now()
+	if closeAll([]func(){slow, fast}, 50*time.Millisecond) {
+		t.Error("expected deadline to expire")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("closeAll must return at the deadline")
+	}
+	if closed.Load() != 3 {
+		t.Errorf("fast closer must not wait for the slow one, got %d", closed.Load())
+	}
+}
@@ -0,0 +1,156 @@
+// Package logtest captures the entries of a logger in tests
+//
+// NewTestLogger returns a logger writing nowhere and a hook recording its
+// entries, so tests verify logging without scraping stdout:
+//
+//	l, logs := logtest.NewTestLogger(t)
+//	svc := NewService(l)
+//	svc.Charge(ctx, order)
+//	logs.AssertLogged(logrus.ErrorLevel, "payment declined")
+package logtest
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a captured log entry
+type Entry struct {
+	Level   logrus.Level
+	Message string
+	Fields  logrus.Fields
+	Time    time.Time
+}
+
+// Hook records every entry of the logger it is added to
+type Hook struct {
+	t       testing.TB
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewHook creates a capture hook reporting failed assertions to t
+func NewHook(t testing.TB) *Hook {
+	return &Hook{t: t}
+}
+
+// NewTestLogger creates an independent logger at trace level capturing its
+// entries, it is cleaned up when the test ends
+func NewTestLogger(t testing.TB) (*logger.Logger, *Hook) {
+	t.Helper()
+	l, cleanup, err := logger.New(&config.Logger{Level: int(logrus.TraceLevel)})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	t.Cleanup(cleanup)
+	l.SetOutput(io.Discard)
+
+	h := NewHook(t)
+	l.AddHook(h)
+	return l, h
+}
+
+// Levels returns all levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire records the entry
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	fields := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+	h.mu.Lock()
+	h.entries = append(h.entries, Entry{
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  fields,
+		Time:    entry.Time,
+	})
+	h.mu.Unlock()
+	return nil
+}
+
+// Entries returns the captured entries in order
+func (h *Hook) Entries() []Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]Entry(nil), h.entries...)
+}
+
+// LastEntry returns the last captured entry, nil when none
+func (h *Hook) LastEntry() *Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.entries) == 0 {
+		return nil
+	}
+	e := h.entries[len(h.entries)-1]
+	return &e
+}
+
+// Reset forgets the captured entries
+func (h *Hook) Reset() {
+	h.mu.Lock()
+	h.entries = nil
+	h.mu.Unlock()
+}
+
+// find returns the first entry of the level whose message contains substr
+func (h *Hook) find(level logrus.Level, substr string) *Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for i := range h.entries {
+		if h.entries[i].Level == level && strings.Contains(h.entries[i].Message, substr) {
+			e := h.entries[i]
+			return &e
+		}
+	}
+	return nil
+}
+
+// AssertLogged fails the test unless an entry of the level contains substr
+// in its message, it returns the entry for assertions on its fields
+func (h *Hook) AssertLogged(level logrus.Level, substr string) *Entry {
+	h.t.Helper()
+	e := h.find(level, substr)
+	if e == nil {
+		h.t.Errorf("expected a %s entry containing %q, got:\n%s", level, substr, h.dump())
+	}
+	return e
+}
+
+// AssertNotLogged fails the test if an entry of the level contains substr
+// in its message
+func (h *Hook) AssertNotLogged(level logrus.Level, substr string) {
+	h.t.Helper()
+	if e := h.find(level, substr); e != nil {
+		h.t.Errorf("unexpected %s entry %q", level, e.Message)
+	}
+}
+
+// dump lists the captured entries for failure messages
+func (h *Hook) dump() string {
+	entries := h.Entries()
+	if len(entries) == 0 {
+		return "  no entries"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString("  ")
+		b.WriteString(e.Level.String())
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,62 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingTB records the failures of assertions expected to fail
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestNewTestLogger(t *testing.T) {
+	l, logs := NewTestLogger(t)
+	ctx := context.Background()
+
+	l.Debugf(ctx, "loaded %d orders", 3)
+	l.Error(ctx, "payment declined")
+
+	if n := len(logs.Entries()); n != 2 {
+		t.Fatalf("captured %d entries, want 2", n)
+	}
+	logs.AssertLogged(logrus.DebugLevel, "3 orders")
+	logs.AssertLogged(logrus.ErrorLevel, "declined")
+	logs.AssertNotLogged(logrus.WarnLevel, "declined")
+	if e := logs.LastEntry(); e == nil || e.Message != "payment declined" {
+		t.Fatalf("unexpected last entry %+v", e)
+	}
+
+	logs.Reset()
+	if logs.LastEntry() != nil || len(logs.Entries()) != 0 {
+		t.Fatal("expected no entries after Reset")
+	}
+}
+
+func TestHook_FailedAssertions(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	h := NewHook(tb)
+	l := logrus.New()
+	l.AddHook(h)
+	l.SetOutput(io.Discard)
+	l.Warn("disk almost full")
+
+	if e := h.AssertLogged(logrus.ErrorLevel, "disk"); e != nil {
+		t.Fatalf("expected no entry, got %+v", e)
+	}
+	h.AssertNotLogged(logrus.WarnLevel, "disk")
+	if len(tb.failures) != 2 {
+		t.Fatalf("expected 2 failures, got %v", tb.failures)
+	}
+}
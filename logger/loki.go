@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ncobase/common/config"
+	"ncobase/common/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	lokiPushPath = "/loki/api/v1/push"
+	lokiTimeout  = 10 * time.Second
+)
+
+// defaultBatchConfig is used by async hooks when no search batching is configured
+var defaultBatchConfig = config.LoggerSearch{
+	BatchSize:     100,
+	FlushInterval: 5 * time.Second,
+	BufferSize:    1000,
+}
+
+// lokiEntry is a queued log line with the labels of its stream
+type lokiEntry struct {
+	level   string
+	version string
+	ts      time.Time
+	line    string
+}
+
+// lokiStream is a stream of the Loki push API
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// NewLokiHook creates a batch hook pushing entries to Loki
+//
+// Each line is the entry data as JSON with the message under "msg", streams
+// are labeled with the configured labels plus level and version.
+func NewLokiHook(c *config.LoggerLoki, batch *config.LoggerSearch) *BatchHook {
+	h := newLokiHook(c, batch)
+	h.start()
+	return h
+}
+
+// newLokiHook creates a Loki batch hook without starting its worker
+func newLokiHook(c *config.LoggerLoki, batch *config.LoggerSearch) *BatchHook {
+	if batch == nil {
+		batch = &defaultBatchConfig
+	}
+	client := &http.Client{Timeout: lokiTimeout}
+	h := newBatchHook(batch, func(docs []any) error {
+		return pushLoki(client, c, docs)
+	})
+	h.doc = lokiDoc
+	return h
+}
+
+// lokiDoc converts an entry to a Loki line
+func lokiDoc(entry *logrus.Entry) any {
+	data := util.CopyMap(entry.Data)
+	data[logrus.FieldKeyMsg] = entry.Message
+
+	line, err := json.Marshal(data)
+	if err != nil {
+		line = []byte(entry.Message)
+	}
+
+	e := lokiEntry{level: entry.Level.String(), ts: entry.Time, line: string(line)}
+	if v, ok := entry.Data[VersionKey].(string); ok {
+		e.version = v
+	}
+	return e
+}
+
+// pushLoki sends the batch grouped into streams
+func pushLoki(client *http.Client, c *config.LoggerLoki, docs []any) error {
+	streams := map[string]*lokiStream{}
+	var order []string
+	for _, d := range docs {
+		e, ok := d.(lokiEntry)
+		if !ok {
+			continue
+		}
+		key := e.level + "\x00" + e.version
+		s, ok := streams[key]
+		if !ok {
+			labels := util.CopyMap(c.Labels)
+			labels[logrus.FieldKeyLevel] = e.level
+			if e.version != "" {
+				labels[VersionKey] = e.version
+			}
+			s = &lokiStream{Stream: labels}
+			streams[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.ts.UnixNano(), 10), e.line})
+	}
+
+	payload := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		payload.Streams = append(payload.Streams, streams[key])
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode loki push: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lokiTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lokiURL(c.URL), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create loki request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.TenantID)
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to loki: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("loki push failed: %s", res.Status)
+	}
+	return nil
+}
+
+// lokiURL returns the push endpoint for a base URL
+func lokiURL(base string) string {
+	if strings.HasSuffix(base, lokiPushPath) {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + lokiPushPath
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLokiHook_Push(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		streams []lokiStream
+		tenant  string
+		path    string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path = r.URL.Path
+		tenant = r.Header.Get("X-Scope-OrgID")
+		var payload struct {
+			Streams []lokiStream `json:"streams"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		streams = append(streams, payload.Streams...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	h := NewLokiHook(&config.LoggerLoki{
+		URL:      srv.URL,
+		TenantID: "team-a",
+		Labels:   map[string]string{"app": "billing", "env": "prod"},
+	}, &config.LoggerSearch{BatchSize: 10, BufferSize: 10, FlushInterval: time.Hour})
+
+	l := logrus.New()
+	for _, e := range []*logrus.Entry{
+		logrus.NewEntry(l).WithField(VersionKey, "1.0.0"),
+		logrus.NewEntry(l).WithField(VersionKey, "1.0.0"),
+		logrus.NewEntry(l).WithField(VersionKey, "1.0.0"),
+	} {
+		e.Message = "charged"
+		e.Level = logrus.InfoLevel
+		e.Time = time.Unix(1700000000, 0)
+		if err := h.Fire(e); err != nil {
+			t.Fatalf("unexpected fire error: %v", err)
+		}
+	}
+	failed := logrus.NewEntry(l)
+	failed.Message, failed.Level, failed.Time = "declined", logrus.ErrorLevel, time.Now()
+	_ = h.Fire(failed)
+	h.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != lokiPushPath || tenant != "team-a" {
+		t.Errorf("unexpected request path %q tenant %q", path, tenant)
+	}
+	if len(streams) != 2 {
+		t.Fatalf("expected info and error streams, got %d", len(streams))
+	}
+
+	info := streams[0]
+	if info.Stream["app"] != "billing" || info.Stream["env"] != "prod" || info.Stream["level"] != "info" || info.Stream[VersionKey] != "1.0.0" {
+		t.Errorf("unexpected labels: %v", info.Stream)
+	}
+	if len(info.Values) != 3 || info.Values[0][0] != "1700000000000000000" {
+		t.Fatalf("unexpected values: %v", info.Values)
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(info.Values[0][1]), &line); err != nil || line["msg"] != "charged" {
+		t.Errorf("expected JSON line with msg, got %q", info.Values[0][1])
+	}
+	if _, ok := streams[1].Stream[VersionKey]; ok {
+		t.Errorf("expected no version label without version field, got %v", streams[1].Stream)
+	}
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	logEntries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_entries_total",
+			Help: "Number of log entries by level.",
+		},
+		[]string{"level"},
+	)
+	shipFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "log_ship_failures_total",
+			Help: "Number of failures of log hooks shipping entries to a sink.",
+		},
+		[]string{"sink"},
+	)
+)
+
+// MetricsCollectors returns the log metrics collectors, they are not
+// registered automatically, register them once at startup with
+//
+//	prometheus.MustRegister(logger.MetricsCollectors()...)
+func MetricsCollectors() []prometheus.Collector {
+	return []prometheus.Collector{logEntries, shipFailures}
+}
+
+// MetricsHook counts log entries by level
+type MetricsHook struct{}
+
+// Levels returns all levels
+func (MetricsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire increments the counter of the entry level
+func (MetricsHook) Fire(entry *logrus.Entry) error {
+	logEntries.WithLabelValues(entry.Level.String()).Inc()
+	return nil
+}
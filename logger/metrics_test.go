@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMetricsHook(t *testing.T) {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(MetricsHook{})
+
+	before := testutil.ToFloat64(logEntries.WithLabelValues("error"))
+	l.Error("one")
+	l.Error("two")
+	l.Info("three")
+	if got := testutil.ToFloat64(logEntries.WithLabelValues("error")) - before; got != 2 {
+		t.Errorf("expected 2 error entries counted, got %v", got)
+	}
+}
+
+func TestSafeHook_ShipFailures(t *testing.T) {
+	h := NewSafeHook("metrics_test_sink", &failingHook{})
+	h.out = io.Discard
+
+	before := testutil.ToFloat64(shipFailures.WithLabelValues("metrics_test_sink"))
+	_ = h.Fire(logrus.NewEntry(logrus.New()))
+	if got := testutil.ToFloat64(shipFailures.WithLabelValues("metrics_test_sink")) - before; got != 1 {
+		t.Errorf("expected 1 ship failure counted, got %v", got)
+	}
+}
@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// msgpackEncoder is a minimal MessagePack encoder for log records, values it
+// does not know are encoded as their fmt string
+type msgpackEncoder struct {
+	buf []byte
+}
+
+// bytes returns the encoded data
+func (e *msgpackEncoder) bytes() []byte {
+	return e.buf
+}
+
+// encode appends v
+func (e *msgpackEncoder) encode(v any) {
+	switch val := v.(type) {
+	case nil:
+		e.buf = append(e.buf, 0xc0)
+	case bool:
+		if val {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case string:
+		e.encodeString(val)
+	case []byte:
+		e.encodeString(string(val))
+	case int:
+		e.encodeInt(int64(val))
+	case int8:
+		e.encodeInt(int64(val))
+	case int16:
+		e.encodeInt(int64(val))
+	case int32:
+		e.encodeInt(int64(val))
+	case int64:
+		e.encodeInt(val)
+	case uint:
+		e.encodeUint(uint64(val))
+	case uint8:
+		e.encodeUint(uint64(val))
+	case uint16:
+		e.encodeUint(uint64(val))
+	case uint32:
+		e.encodeUint(uint64(val))
+	case uint64:
+		e.encodeUint(val)
+	case float32:
+		e.encodeFloat(float64(val))
+	case float64:
+		e.encodeFloat(val)
+	case time.Duration:
+		e.encodeFloat(durationMillis(val))
+	case time.Time:
+		e.encodeString(val.Format(time.RFC3339Nano))
+	case error:
+		e.encodeString(val.Error())
+	case map[string]any:
+		e.encodeMap(val)
+	case []any:
+		e.encodeArrayHeader(len(val))
+		for _, item := range val {
+			e.encode(item)
+		}
+	case []string:
+		e.encodeArrayHeader(len(val))
+		for _, item := range val {
+			e.encodeString(item)
+		}
+	default:
+		e.encodeString(fmt.Sprint(val))
+	}
+}
+
+// encodeMap appends a map with keys in sorted order
+func (e *msgpackEncoder) encodeMap(m map[string]any) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	n := len(m)
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xde)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdf)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	for _, k := range keys {
+		e.encodeString(k)
+		e.encode(m[k])
+	}
+}
+
+// encodeArrayHeader appends the header of an array of n items
+func (e *msgpackEncoder) encodeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xdc)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdd)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+// encodeString appends a str
+func (e *msgpackEncoder) encodeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+// encodeInt appends a signed integer
+func (e *msgpackEncoder) encodeInt(i int64) {
+	if i >= 0 {
+		e.encodeUint(uint64(i))
+		return
+	}
+	if i >= -32 {
+		e.buf = append(e.buf, byte(i))
+		return
+	}
+	e.buf = append(e.buf, 0xd3)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+}
+
+// encodeUint appends an unsigned integer
+func (e *msgpackEncoder) encodeUint(u uint64) {
+	switch {
+	case u < 128:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, u)
+	}
+}
+
+// encodeFloat appends a float64
+func (e *msgpackEncoder) encodeFloat(f float64) {
+	e.buf = append(e.buf, 0xcb)
+	e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+// encodeEventTime appends a Fluentd EventTime, ext type 0 with seconds and nanoseconds
+func (e *msgpackEncoder) encodeEventTime(t time.Time) {
+	e.buf = append(e.buf, 0xd7, 0x00)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Unix()))
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+}
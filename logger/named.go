@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModuleKey is the field carrying the module name of child loggers
+const ModuleKey = "module"
+
+// Named returns the child logger of a module, creating it on first use
+//
+// The child writes to the same output, formatter and hooks as its parent and
+// adds a module field to every entry. Its level comes from config.Logger.Modules
+// or SetLevel on the child, otherwise it follows the parent level, so a single
+// module can log at debug while the rest of the service stays at warn. Named
+// on a child returns the "parent.name" module of the root logger.
+func (l *Logger) Named(module string) *Logger {
+	if l.parent != nil {
+		return l.parent.Named(l.module + "." + module)
+	}
+
+	l.childMu.Lock()
+	defer l.childMu.Unlock()
+
+	if child, ok := l.children[module]; ok {
+		return child
+	}
+
+	child := &Logger{
+		Logger: logrus.New(),
+		module: module,
+		parent: l,
+	}
+	if level, ok := l.modules[module]; ok {
+		child.Logger.SetLevel(level)
+		child.ownLevel = true
+	}
+	l.syncChild(child)
+
+	if l.children == nil {
+		l.children = make(map[string]*Logger)
+	}
+	l.children[module] = child
+	return child
+}
+
+// Module returns the module name of a child logger, empty for the root
+func (l *Logger) Module() string {
+	return l.module
+}
+
+// SetLevel sets the level, on the root it also applies to children without their own level
+func (l *Logger) SetLevel(level logrus.Level) {
+	l.Logger.SetLevel(level)
+	if l.parent != nil {
+		l.parent.childMu.Lock()
+		l.ownLevel = true
+		l.parent.childMu.Unlock()
+		return
+	}
+	l.syncChildren()
+}
+
+// SetFormatter sets the formatter of the logger and its children
+func (l *Logger) SetFormatter(formatter logrus.Formatter) {
+	l.Logger.SetFormatter(formatter)
+	l.syncChildren()
+}
+
+// root returns the logger owning the shared state, the logger itself unless it is a child
+func (l *Logger) root() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+	return l
+}
+
+// setModuleLevels sets the configured module levels and applies them to existing children
+func (l *Logger) setModuleLevels(modules map[string]string) error {
+	levels := make(map[string]logrus.Level, len(modules))
+	for module, name := range modules {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid level for module %s: %w", module, err)
+		}
+		levels[module] = level
+	}
+
+	l.childMu.Lock()
+	defer l.childMu.Unlock()
+
+	l.modules = levels
+	for module, child := range l.children {
+		if level, ok := levels[module]; ok {
+			child.Logger.SetLevel(level)
+			child.ownLevel = true
+		}
+	}
+	return nil
+}
+
+// syncChildren copies output, formatter, hooks and level to the children
+func (l *Logger) syncChildren() {
+	if l.parent != nil {
+		return
+	}
+	l.childMu.Lock()
+	defer l.childMu.Unlock()
+	for _, child := range l.children {
+		l.syncChild(child)
+	}
+}
+
+// syncChild copies the parent settings to a child, childMu must be held
+func (l *Logger) syncChild(child *Logger) {
+	child.Logger.SetOutput(l.Out)
+	child.Logger.SetFormatter(l.Formatter)
+	child.Logger.SetReportCaller(l.ReportCaller)
+	child.ExitFunc = l.ExitFunc
+
+	hooks := make(logrus.LevelHooks, len(l.Hooks))
+	for level, list := range l.Hooks {
+		hooks[level] = append([]logrus.Hook(nil), list...)
+	}
+	child.ReplaceHooks(hooks)
+
+	if !child.ownLevel {
+		child.Logger.SetLevel(l.GetLevel())
+	}
+}
+
+// Named returns the child logger of a module of the package-level logger
+func Named(module string) *Logger { return StdLogger().Named(module) }
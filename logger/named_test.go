@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNamed_ModuleLevels(t *testing.T) {
+	l, cleanup, err := New(&config.Logger{
+		Level:   int(logrus.WarnLevel),
+		Format:  "text",
+		Modules: map[string]string{"payments": "debug"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer cleanup()
+
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	ctx := context.Background()
+
+	payments := l.Named("payments")
+	orders := l.Named("orders")
+	if l.Named("payments") != payments {
+		t.Error("expected Named to return the same child for a module")
+	}
+
+	payments.Debug(ctx, "payment debug")
+	orders.Info(ctx, "orders info")
+	l.Info(ctx, "root info")
+
+	out := buf.String()
+	if !strings.Contains(out, "payment debug") || !strings.Contains(out, "module=payments") {
+		t.Errorf("expected debug entry of payments with module field, got %q", out)
+	}
+	if strings.Contains(out, "orders info") || strings.Contains(out, "root info") {
+		t.Errorf("expected info entries to be filtered at warn, got %q", out)
+	}
+
+	// Children without their own level follow the root
+	buf.Reset()
+	l.SetLevel(logrus.InfoLevel)
+	orders.Info(ctx, "orders info")
+	payments.Debug(ctx, "still debug")
+	if !strings.Contains(buf.String(), "orders info") || !strings.Contains(buf.String(), "still debug") {
+		t.Errorf("unexpected output after root level change: %q", buf.String())
+	}
+
+	// An explicit child level no longer follows the root
+	buf.Reset()
+	orders.SetLevel(logrus.ErrorLevel)
+	l.SetLevel(logrus.DebugLevel)
+	orders.Warn(ctx, "orders warn")
+	if strings.Contains(buf.String(), "orders warn") {
+		t.Errorf("expected orders to keep its own level, got %q", buf.String())
+	}
+
+	if got := orders.Named("sync").Module(); got != "orders.sync" {
+		t.Errorf("expected nested module orders.sync, got %q", got)
+	}
+}
+
+func TestInit_InvalidModuleLevel(t *testing.T) {
+	if _, err := newLogger().Init(&config.Logger{Modules: map[string]string{"payments": "loud"}}); err == nil {
+		t.Error("expected error for an invalid module level")
+	}
+}
+
+func TestApplyLevels(t *testing.T) {
+	l := newLogger()
+	payments := l.Named("payments")
+
+	if err := l.ApplyLevels(&config.Logger{Level: int(logrus.ErrorLevel), Modules: map[string]string{"payments": "debug"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.GetLevel() != logrus.ErrorLevel || payments.GetLevel() != logrus.DebugLevel {
+		t.Errorf("levels = %s and %s, want error and debug", l.GetLevel(), payments.GetLevel())
+	}
+	if err := payments.ApplyLevels(&config.Logger{Modules: map[string]string{"payments": "loud"}}); err == nil {
+		t.Error("expected an invalid module level rejected")
+	}
+}
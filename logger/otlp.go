@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otlpShutdownTimeout bounds flushing pending records on Close
+const otlpShutdownTimeout = 5 * time.Second
+
+// OTLPHook exports entries as OpenTelemetry log records
+//
+// Records are batched by the SDK and shipped via OTLP/gRPC or OTLP/HTTP. The
+// trace id of the entry context, or the trace_id field when it is a 32 hex
+// digit id (dashes ignored), is set as the record trace context so backends
+// can correlate logs and traces.
+type OTLPHook struct {
+	provider *sdklog.LoggerProvider
+	logger   otellog.Logger
+}
+
+// NewOTLPHook creates an OTLP log hook from config
+func NewOTLPHook(c *config.LoggerOTLP) (*OTLPHook, error) {
+	ctx := context.Background()
+
+	var (
+		exporter sdklog.Exporter
+		err      error
+	)
+	switch strings.ToLower(c.Protocol) {
+	case "", "grpc":
+		opts := []otlploggrpc.Option{otlploggrpc.WithEndpoint(c.Endpoint), otlploggrpc.WithHeaders(c.Headers)}
+		if c.Insecure {
+			opts = append(opts, otlploggrpc.WithInsecure())
+		}
+		exporter, err = otlploggrpc.New(ctx, opts...)
+	case "http":
+		opts := []otlploghttp.Option{otlploghttp.WithEndpoint(c.Endpoint), otlploghttp.WithHeaders(c.Headers)}
+		if c.Insecure {
+			opts = append(opts, otlploghttp.WithInsecure())
+		}
+		exporter, err = otlploghttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", c.Protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceNameKey.String(c.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	return newOTLPHook(sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	)), nil
+}
+
+// newOTLPHook creates a hook emitting to the provider
+func newOTLPHook(provider *sdklog.LoggerProvider) *OTLPHook {
+	return &OTLPHook{
+		provider: provider,
+		logger:   provider.Logger("ncobase/common/logger"),
+	}
+}
+
+// Levels returns all log levels
+func (h *OTLPHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire emits the entry as a log record
+func (h *OTLPHook) Fire(entry *logrus.Entry) error {
+	var r otellog.Record
+	r.SetTimestamp(entry.Time)
+	r.SetObservedTimestamp(time.Now())
+	r.SetSeverity(otlpSeverity(entry.Level))
+	r.SetSeverityText(entry.Level.String())
+	r.SetBody(otellog.StringValue(entry.Message))
+
+	attrs := make([]otellog.KeyValue, 0, len(entry.Data))
+	for k, v := range entry.Data {
+		attrs = append(attrs, otlpAttribute(k, v))
+	}
+	r.AddAttributes(attrs...)
+
+	h.logger.Emit(otlpContext(entry), r)
+	return nil
+}
+
+// Close flushes pending records and shuts the exporter down
+func (h *OTLPHook) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otlpShutdownTimeout)
+	defer cancel()
+	return h.provider.Shutdown(ctx)
+}
+
+// otlpContext returns the context carrying the trace of the entry
+func otlpContext(entry *logrus.Entry) context.Context {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	s, ok := entry.Data[traceKey].(string)
+	if !ok {
+		return ctx
+	}
+	var id trace.TraceID
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != len(id) {
+		return ctx
+	}
+	copy(id[:], b)
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: id}))
+}
+
+// otlpAttribute converts an entry field to a log attribute
+func otlpAttribute(k string, v any) otellog.KeyValue {
+	switch val := v.(type) {
+	case string:
+		return otellog.String(k, val)
+	case bool:
+		return otellog.Bool(k, val)
+	case int:
+		return otellog.Int(k, val)
+	case int64:
+		return otellog.Int64(k, val)
+	case float64:
+		return otellog.Float64(k, val)
+	case time.Duration:
+		return otellog.Float64(k, durationMillis(val))
+	case error:
+		return otellog.String(k, val.Error())
+	default:
+		return otellog.String(k, fmt.Sprint(val))
+	}
+}
+
+// otlpSeverity maps a logrus level to an OpenTelemetry severity
+func otlpSeverity(level logrus.Level) otellog.Severity {
+	switch level {
+	case logrus.PanicLevel:
+		return otellog.SeverityFatal4
+	case logrus.FatalLevel:
+		return otellog.SeverityFatal
+	case logrus.ErrorLevel:
+		return otellog.SeverityError
+	case logrus.WarnLevel:
+		return otellog.SeverityWarn
+	case logrus.InfoLevel:
+		return otellog.SeverityInfo
+	case logrus.DebugLevel:
+		return otellog.SeverityDebug
+	default:
+		return otellog.SeverityTrace
+	}
+}
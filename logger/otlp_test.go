@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// recordingExporter keeps exported records in memory
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTLPHook_Fire(t *testing.T) {
+	exp := &recordingExporter{}
+	h := newOTLPHook(sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exp))))
+	defer h.Close()
+
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		traceKey: "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		"order":  42,
+	})
+	entry.Level, entry.Message = logrus.WarnLevel, "slow payment"
+	if err := h.Fire(entry); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	if len(exp.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(exp.records))
+	}
+	r := exp.records[0]
+	if r.Body().AsString() != "slow payment" || r.Severity() != otellog.SeverityWarn {
+		t.Errorf("unexpected record: %q %v", r.Body().AsString(), r.Severity())
+	}
+	if got := r.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace id from trace_id field, got %s", got)
+	}
+
+	var order int64
+	r.WalkAttributes(func(kv otellog.KeyValue) bool {
+		if kv.Key == "order" {
+			order = kv.Value.AsInt64()
+		}
+		return true
+	})
+	if order != 42 {
+		t.Errorf("expected order attribute 42, got %d", order)
+	}
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactMask replaces redacted values
+const RedactMask = "[REDACTED]"
+
+// Redactor masks sensitive values before entries are formatted or shipped
+//
+// Values of the configured field names are replaced as a whole, matched
+// case-insensitively at any depth of nested maps. Parts of string values and
+// of the message that match a pattern are replaced in place, e.g. card numbers
+// embedded in free text. Registered as the first hook, it runs before every
+// other hook and the formatter.
+type Redactor struct {
+	fields   map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a redactor from the logger redaction config
+func NewRedactor(c *config.LoggerRedact) (*Redactor, error) {
+	r := &Redactor{fields: make(map[string]struct{}, len(c.Fields))}
+	for _, f := range c.Fields {
+		r.fields[strings.ToLower(f)] = struct{}{}
+	}
+	for _, p := range c.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Levels returns all levels
+func (r *Redactor) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire masks the entry data and message in place
+func (r *Redactor) Fire(entry *logrus.Entry) error {
+	r.redactFields(entry.Data)
+	entry.Message = r.redactString(entry.Message)
+	return nil
+}
+
+// redactFields masks fields in place, the map must be owned by the caller,
+// nested maps may be shared so they are copied when changed
+func (r *Redactor) redactFields(fields map[string]any) {
+	for k, v := range fields {
+		if r.sensitive(k) {
+			fields[k] = RedactMask
+			continue
+		}
+		fields[k] = r.redactValue(v)
+	}
+}
+
+// redactTyped masks the fields of a backend record in place
+func (r *Redactor) redactTyped(fields []Field) {
+	for i := range fields {
+		f := &fields[i]
+		switch {
+		case r.sensitive(f.Key):
+			*f = stringField(f.Key, RedactMask)
+		case f.Kind == StringKind:
+			f.Str = r.redactString(f.Str)
+		case f.Kind == AnyKind:
+			f.Any = r.redactValue(f.Any)
+		}
+	}
+}
+
+// redactValue returns the value with sensitive parts masked
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.redactString(v)
+	case logrus.Fields:
+		return logrus.Fields(r.redactMap(v))
+	case map[string]any:
+		return r.redactMap(v)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.sensitive(k) {
+				out[k] = RedactMask
+			} else {
+				out[k] = r.redactString(s)
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactMap returns a masked copy of m
+func (r *Redactor) redactMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	r.redactFields(out)
+	return out
+}
+
+// redactString replaces every pattern match with the mask
+func (r *Redactor) redactString(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, RedactMask)
+	}
+	return s
+}
+
+// sensitive reports whether values of the field name are masked
+func (r *Redactor) sensitive(name string) bool {
+	_, ok := r.fields[strings.ToLower(name)]
+	return ok
+}
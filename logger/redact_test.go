@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor(&config.LoggerRedact{
+		Fields:   []string{"password", "authorization"},
+		Patterns: []string{`\b\d{4}-\d{4}-\d{4}-\d{4}\b`},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.AddHook(r)
+
+	headers := map[string]any{"Authorization": "Bearer secret", "Accept": "*/*"}
+	l.WithFields(logrus.Fields{
+		"Password": "hunter2",
+		"user":     "alice",
+		"headers":  headers,
+		"note":     "paid with 4111-1111-1111-1111",
+	}).Info("card 4111-1111-1111-1111 charged")
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid output: %v", err)
+	}
+	if got["Password"] != RedactMask {
+		t.Errorf("expected password masked, got %v", got["Password"])
+	}
+	if got["user"] != "alice" {
+		t.Errorf("expected user kept, got %v", got["user"])
+	}
+	nested := got["headers"].(map[string]any)
+	if nested["Authorization"] != RedactMask || nested["Accept"] != "*/*" {
+		t.Errorf("expected nested authorization masked, got %v", nested)
+	}
+	if got["note"] != "paid with "+RedactMask {
+		t.Errorf("expected card number masked in field, got %v", got["note"])
+	}
+	if got["msg"] != "card "+RedactMask+" charged" {
+		t.Errorf("expected card number masked in message, got %v", got["msg"])
+	}
+	if headers["Authorization"] != "Bearer secret" {
+		t.Error("caller map must not be modified")
+	}
+}
+
+func TestNewRedactor_InvalidPattern(t *testing.T) {
+	if _, err := NewRedactor(&config.LoggerRedact{Patterns: []string{"("}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
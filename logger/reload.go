@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ApplyLevels updates the level and the module levels from c, so they follow
+// config reloads without a restart, e.g.
+//
+//	config.OnChange(func(_, c *config.Config) {
+//		_ = logger.StdLogger().ApplyLevels(c.Logger)
+//	})
+//
+// Outputs and hooks are set up by Init and are not changed.
+func (l *Logger) ApplyLevels(c *config.Logger) error {
+	root := l.root()
+	if err := root.setModuleLevels(c.Modules); err != nil {
+		return err
+	}
+	root.SetLevel(logrus.Level(c.Level))
+	return nil
+}
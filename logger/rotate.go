@@ -0,0 +1,277 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dateLayout   = "2006-01-02"
+	backupLayout = "2006-01-02T15-04-05.000"
+	gzipSuffix   = ".gz"
+	megabyte     = 1024 * 1024
+)
+
+// RotateOptions controls size based rotation and retention of log files
+type RotateOptions struct {
+	MaxSizeMB  int            // rotate once the current file exceeds this size, 0 disables
+	MaxBackups int            // rotated files to keep, 0 keeps all
+	MaxAgeDays int            // remove rotated files older than this, 0 keeps all
+	Compress   bool           // gzip rotated files
+	DryRun     bool           // report the files retention would remove without removing them
+	Layout     string         // time layout naming the current file, default date only
+	Location   *time.Location // timezone of the file names, default local
+	// Removed is called for every file removed, or that would be removed in dry run
+	Removed func(path string, dryRun bool)
+}
+
+// rotatingFile is a log file writer rotated on schedule by name and by size
+//
+// The current file is "<base>.<date>.log", or "<base>.<date>T<hour>.log" for
+// hourly rotation, when it grows beyond MaxSizeMB it
+// is renamed to "<base>.<date>.<time>.log" and a fresh file is opened.
+// Rotated files are compressed and pruned in the background.
+type rotatingFile struct {
+	mu   sync.Mutex
+	base string // path without the .log extension
+	opts RotateOptions
+	file *os.File
+	size int64
+	wg   sync.WaitGroup // tracks background compress and prune runs
+	mmu  sync.Mutex     // serializes background compress and prune runs
+}
+
+// openRotatingFile opens the current log file for base path
+func openRotatingFile(path string, opts RotateOptions) (*rotatingFile, error) {
+	r := &rotatingFile{base: strings.TrimSuffix(path, ".log"), opts: opts}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write writes p to the current file, rotating first if it would exceed the max size
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if max := int64(r.opts.MaxSizeMB) * megabyte; max > 0 && r.size > 0 && r.size+int64(len(p)) > max {
+		if err := r.rotate(true); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate reopens the file for the current date
+func (r *rotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rotate(false)
+}
+
+// Close closes the current file and waits for background work
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return err
+}
+
+// current returns the name of the file for the current date
+func (r *rotatingFile) current() string {
+	layout, loc := r.opts.Layout, r.opts.Location
+	if layout == "" {
+		layout = dateLayout
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return fmt.Sprintf("%s.%s.log", r.base, time.Now().In(loc).Format(layout))
+}
+
+// open opens the current file in append mode
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.current(), os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open new log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// rotate closes the current file, optionally keeping it as a backup, and opens a new one
+func (r *rotatingFile) rotate(backup bool) error {
+	if r.file != nil {
+		name := r.file.Name()
+		if err := r.file.Close(); err != nil {
+			return fmt.Errorf("failed to close current log file: %w", err)
+		}
+		r.file = nil
+		if backup {
+			now := time.Now()
+			dst := fmt.Sprintf("%s.%s.log", r.base, now.Format(backupLayout))
+			if err := os.Rename(name, dst); err != nil {
+				return fmt.Errorf("failed to rename log file: %w", err)
+			}
+		}
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go func(current string) {
+		defer r.wg.Done()
+		r.maintain(current)
+	}(r.file.Name())
+	return nil
+}
+
+// backups returns rotated files, newest first, excluding current
+func (r *rotatingFile) backups(current string) ([]os.FileInfo, error) {
+	dir := filepath.Dir(r.base)
+	prefix := filepath.Base(r.base) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == filepath.Base(current) || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if !strings.HasSuffix(name, ".log") && !strings.HasSuffix(name, ".log"+gzipSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+
+	// Sort by the time in the name, compressing changes the modification time
+	sort.Slice(files, func(i, j int) bool {
+		return backupKey(files[i].Name()) > backupKey(files[j].Name())
+	})
+	return files, nil
+}
+
+// backupKey returns a sortable key for a log file name, a daily or hourly
+// file holds the last writes of its period so it sorts after its backups
+func backupKey(name string) string {
+	key := strings.TrimSuffix(strings.TrimSuffix(name, gzipSuffix), ".log")
+	for _, layout := range []string{dateLayout, hourLayout} {
+		if len(key) < len(layout) {
+			continue
+		}
+		if _, err := time.Parse(layout, key[len(key)-len(layout):]); err == nil {
+			return key + "~"
+		}
+	}
+	return key
+}
+
+// maintain prunes and compresses rotated files according to the options
+func (r *rotatingFile) maintain(current string) {
+	if r.opts.MaxBackups == 0 && r.opts.MaxAgeDays == 0 && !r.opts.Compress {
+		return
+	}
+	r.mmu.Lock()
+	defer r.mmu.Unlock()
+
+	files, err := r.backups(current)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to list log backups: %v\n", err)
+		return
+	}
+
+	dir := filepath.Dir(r.base)
+	cutoff := time.Now().AddDate(0, 0, -r.opts.MaxAgeDays)
+	for i, info := range files {
+		path := filepath.Join(dir, info.Name())
+		expired := r.opts.MaxAgeDays > 0 && info.ModTime().Before(cutoff)
+		if (r.opts.MaxBackups > 0 && i >= r.opts.MaxBackups) || expired {
+			r.remove(path)
+			continue
+		}
+		if r.opts.Compress && !strings.HasSuffix(path, gzipSuffix) {
+			if err := compressFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "logger: failed to compress %s: %v\n", path, err)
+			}
+		}
+	}
+}
+
+// remove removes a stale file, or only reports it in dry run
+func (r *rotatingFile) remove(path string) {
+	if !r.opts.DryRun {
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: failed to remove %s: %v\n", path, err)
+			return
+		}
+	}
+	if r.opts.Removed != nil {
+		r.opts.Removed(path, r.opts.DryRun)
+	}
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+gzipSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	r, err := openRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{MaxSizeMB: 1, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+
+	line := bytes.Repeat([]byte("x"), 400*1024)
+	for i := 0; i < 10; i++ {
+		if _, err := r.Write(line); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read dir: %v", err)
+	}
+
+	var current, compressed int
+	for _, e := range entries {
+		info, _ := e.Info()
+		switch {
+		case strings.HasSuffix(e.Name(), ".log.gz"):
+			compressed++
+		case strings.HasSuffix(e.Name(), ".log"):
+			current++
+			if info.Size() > 1024*1024 {
+				t.Errorf("%s exceeds the max size: %d", e.Name(), info.Size())
+			}
+		}
+	}
+	if current != 1 {
+		t.Errorf("expected only the current file uncompressed, got %d", current)
+	}
+	if compressed != 2 {
+		t.Errorf("expected max backups of 2 compressed files, got %d", compressed)
+	}
+}
+
+func TestRotatingFile_DisabledBySize(t *testing.T) {
+	dir := t.TempDir()
+	r, err := openRotatingFile(filepath.Join(dir, "app.log"), RotateOptions{})
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	defer r.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Write([]byte("line\n")); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected a single log file without size limit, got %d", len(entries))
+	}
+}
+
+func TestBackupKey_Order(t *testing.T) {
+	names := []string{
+		"app.2026-10-15T08-00-00.000.log.gz",
+		"app.2026-10-15.log",
+		"app.2026-10-16T09-30-00.000.log",
+	}
+	if !(backupKey(names[2]) > backupKey(names[1]) && backupKey(names[1]) > backupKey(names[0])) {
+		t.Errorf("unexpected order: %q %q %q", backupKey(names[0]), backupKey(names[1]), backupKey(names[2]))
+	}
+}
+
+func TestRotatingFile_RetentionDryRun(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -10)
+	stale := filepath.Join(dir, "app."+old.Format(dateLayout)+".log")
+	if err := os.WriteFile(stale, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("failed to write stale file: %v", err)
+	}
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("failed to age stale file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var removed []string
+	opts := RotateOptions{MaxAgeDays: 7, DryRun: true, Removed: func(path string, dryRun bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !dryRun {
+			t.Errorf("expected dry run report for %s", path)
+		}
+		removed = append(removed, path)
+	}}
+	r, err := openRotatingFile(filepath.Join(dir, "app.log"), opts)
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	if err := r.Rotate(); err != nil {
+		t.Fatalf("unexpected rotate error: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if len(removed) != 1 || removed[0] != stale {
+		t.Errorf("expected stale file reported, got %v", removed)
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Errorf("dry run must keep the file: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultHookErrorInterval is the minimum time between two hook error reports
+const DefaultHookErrorInterval = time.Minute
+
+// SafeHook wraps a hook so its errors are swallowed and counted, also in
+// the log_ship_failures_total metric
+//
+// logrus prints every failed Fire to stderr, and logging that error through
+// the logger again can feed back into the failing hook. SafeHook never returns
+// an error to logrus and reports failures straight to a raw writer, bypassing
+// all hooks, at most once per interval.
+type SafeHook struct {
+	name     string
+	hook     logrus.Hook
+	out      io.Writer
+	interval time.Duration
+	errors   atomic.Int64
+	lastLog  atomic.Int64 // unix nano of the last report
+}
+
+// NewSafeHook wraps the hook, reporting errors to stderr
+func NewSafeHook(name string, hook logrus.Hook) *SafeHook {
+	return &SafeHook{
+		name:     name,
+		hook:     hook,
+		out:      os.Stderr,
+		interval: DefaultHookErrorInterval,
+	}
+}
+
+// Levels returns the levels of the wrapped hook
+func (h *SafeHook) Levels() []logrus.Level {
+	return h.hook.Levels()
+}
+
+// Fire fires the wrapped hook and swallows its error
+func (h *SafeHook) Fire(entry *logrus.Entry) error {
+	if err := h.hook.Fire(entry); err != nil {
+		h.report(err)
+	}
+	return nil
+}
+
+// Errors returns the number of failed Fire calls
+func (h *SafeHook) Errors() int64 {
+	return h.errors.Load()
+}
+
+// Hook returns the wrapped hook
+func (h *SafeHook) Hook() logrus.Hook {
+	return h.hook
+}
+
+// report counts the error and writes it out if the interval has elapsed
+func (h *SafeHook) report(err error) {
+	total := h.errors.Add(1)
+	shipFailures.WithLabelValues(h.name).Inc()
+
+	now := time.Now().UnixNano()
+	last := h.lastLog.Load()
+	if last != 0 && now-last < int64(h.interval) {
+		return
+	}
+	if !h.lastLog.CompareAndSwap(last, now) {
+		return
+	}
+	_, _ = fmt.Fprintf(h.out, "%s log hook error (%d total): %v\n", h.name, total, err)
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// failingHook always fails and panics if it is re-entered, which would
+// happen if its error were fed back through the logger
+type failingHook struct {
+	fired atomic.Int64
+	depth atomic.Int64
+}
+
+func (h *failingHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *failingHook) Fire(*logrus.Entry) error {
+	h.fired.Add(1)
+	if h.depth.Add(1) > 1 {
+		panic("recursive hook invocation")
+	}
+	defer h.depth.Add(-1)
+	return errors.New("backend down")
+}
+
+func TestSafeHook_SwallowsErrors(t *testing.T) {
+	l := logrus.New()
+	var stderr bytes.Buffer
+	l.SetOutput(io.Discard)
+
+	inner := &failingHook{}
+	safe := NewSafeHook("test", inner)
+	safe.out = &stderr
+	l.AddHook(safe)
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		l.Error("something failed")
+	}
+
+	if got := inner.fired.Load(); got != n {
+		t.Errorf("expected %d hook invocations, got %d", n, got)
+	}
+	if got := safe.Errors(); got != n {
+		t.Errorf("expected %d counted errors, got %d", n, got)
+	}
+	if lines := strings.Count(stderr.String(), "\n"); lines != 1 {
+		t.Errorf("expected a single rate limited report, got %d: %q", lines, stderr.String())
+	}
+}
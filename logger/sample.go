@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// numLevels is the number of logrus levels, from Panic to Trace
+const numLevels = int(logrus.TraceLevel) + 1
+
+// SampleRate keeps the first entries of every tick, then one in Thereafter
+type SampleRate struct {
+	First      int
+	Thereafter int // 0 drops every entry after First until the next tick
+}
+
+// levelCounter counts the entries of a level in the current tick
+type levelCounter struct {
+	resetAt atomic.Int64 // unix nano at which the tick ends
+	count   atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// Sampler limits the entries logged per level and tick
+//
+// A flood of debug entries from a hot path is cut down to a steady trickle
+// before it reaches the output and hooks, so it can't overload Elasticsearch.
+// Levels without a rate, and Fatal and Panic, are never sampled.
+type Sampler struct {
+	tick     time.Duration
+	rates    [numLevels]*SampleRate
+	counters [numLevels]levelCounter
+	now      func() time.Time
+}
+
+// NewSampler creates a sampler with rates by level
+func NewSampler(tick time.Duration, rates map[logrus.Level]SampleRate) *Sampler {
+	s := &Sampler{tick: tick, now: time.Now}
+	for level, rate := range rates {
+		if level <= logrus.FatalLevel || int(level) >= numLevels {
+			continue
+		}
+		rate := rate
+		s.rates[level] = &rate
+	}
+	return s
+}
+
+// newSamplerFromConfig creates the sampler of the logger sampling config,
+// nil when no level is sampled
+func newSamplerFromConfig(c *config.LoggerSampling) (*Sampler, error) {
+	if c == nil || len(c.Levels) == 0 {
+		return nil, nil
+	}
+	rates := make(map[logrus.Level]SampleRate, len(c.Levels))
+	for name, r := range c.Levels {
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling level %q: %w", name, err)
+		}
+		rates[level] = SampleRate{First: r.First, Thereafter: r.Thereafter}
+	}
+	return NewSampler(c.Tick, rates), nil
+}
+
+// Allow reports whether an entry of the level is logged, counting it as dropped otherwise
+func (s *Sampler) Allow(level logrus.Level) bool {
+	if int(level) >= numLevels || s.rates[level] == nil {
+		return true
+	}
+	rate, c := s.rates[level], &s.counters[level]
+
+	n := c.next(s.now().UnixNano(), s.tick)
+	if n <= uint64(rate.First) {
+		return true
+	}
+	if rate.Thereafter > 0 && (n-uint64(rate.First))%uint64(rate.Thereafter) == 0 {
+		return true
+	}
+	c.dropped.Add(1)
+	return false
+}
+
+// next counts an entry and returns its position in the current tick
+func (c *levelCounter) next(now int64, tick time.Duration) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+int64(tick)) {
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// Dropped returns the number of entries of the level dropped by sampling
+func (s *Sampler) Dropped(level logrus.Level) uint64 {
+	if int(level) >= numLevels {
+		return 0
+	}
+	return s.counters[level].dropped.Load()
+}
+
+// TotalDropped returns the number of entries of all levels dropped by sampling
+func (s *Sampler) TotalDropped() uint64 {
+	var total uint64
+	for i := range s.counters {
+		total += s.counters[i].dropped.Load()
+	}
+	return total
+}
+
+// Sampler returns the sampler of the logger, nil when sampling is disabled
+func (l *Logger) Sampler() *Sampler {
+	return l.root().sampler
+}
+
+// sampled reports whether the sampler keeps an entry of the level
+func (l *Logger) sampled(level logrus.Level) bool {
+	s := l.root().sampler
+	return s == nil || s.Allow(level)
+}
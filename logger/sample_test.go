@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSampler(time.Second, map[logrus.Level]SampleRate{
+		logrus.DebugLevel: {First: 2, Thereafter: 3},
+		logrus.FatalLevel: {First: 0},
+	})
+	s.now = func() time.Time { return now }
+
+	var kept []int
+	for i := 1; i <= 8; i++ {
+		if s.Allow(logrus.DebugLevel) {
+			kept = append(kept, i)
+		}
+	}
+	// first 2, then every 3rd: entries 1, 2, 5, 8
+	if len(kept) != 4 || kept[2] != 5 || kept[3] != 8 {
+		t.Errorf("unexpected kept entries %v", kept)
+	}
+	if s.Dropped(logrus.DebugLevel) != 4 {
+		t.Errorf("expected 4 dropped, got %d", s.Dropped(logrus.DebugLevel))
+	}
+
+	now = now.Add(time.Second)
+	if !s.Allow(logrus.DebugLevel) {
+		t.Error("expected counter reset on the next tick")
+	}
+
+	for i := 0; i < 10; i++ {
+		if !s.Allow(logrus.InfoLevel) || !s.Allow(logrus.FatalLevel) {
+			t.Fatal("unsampled levels must always be allowed")
+		}
+	}
+	if s.TotalDropped() != 4 {
+		t.Errorf("expected 4 dropped in total, got %d", s.TotalDropped())
+	}
+}
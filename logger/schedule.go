@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hourLayout names the current log file of hourly rotation
+const hourLayout = "2006-01-02T15"
+
+// rotateSchedule decides when the log file is rotated
+type rotateSchedule struct {
+	hourly bool
+	hour   int // time of day of daily rotation
+	minute int
+	loc    *time.Location
+}
+
+// parseRotateSchedule parses "hourly", "daily" or "daily@HH:MM" in the IANA
+// timezone tz, empty values mean daily at midnight local time
+func parseRotateSchedule(spec, tz string) (rotateSchedule, error) {
+	s := rotateSchedule{loc: time.Local}
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return s, fmt.Errorf("invalid log rotation timezone %q: %w", tz, err)
+		}
+		s.loc = loc
+	}
+
+	switch {
+	case spec == "" || spec == "daily":
+	case spec == "hourly":
+		s.hourly = true
+	case strings.HasPrefix(spec, "daily@"):
+		at, err := time.Parse("15:04", strings.TrimPrefix(spec, "daily@"))
+		if err != nil {
+			return s, fmt.Errorf("invalid log rotation time %q: %w", spec, err)
+		}
+		s.hour, s.minute = at.Hour(), at.Minute()
+	default:
+		return s, fmt.Errorf("unknown log rotation schedule %q", spec)
+	}
+	return s, nil
+}
+
+// layout returns the time layout naming the current log file
+func (s rotateSchedule) layout() string {
+	if s.hourly {
+		return hourLayout
+	}
+	return dateLayout
+}
+
+// next returns the first rotation time after now, computed on the wall
+// clock of the timezone so boundaries stay aligned across DST changes
+func (s rotateSchedule) next(now time.Time) time.Time {
+	t := now.In(s.loc)
+	if s.hourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+	}
+	at := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, s.loc)
+	if !at.After(t) {
+		at = time.Date(t.Year(), t.Month(), t.Day()+1, s.hour, s.minute, 0, 0, s.loc)
+	}
+	return at
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotateSchedule_Next(t *testing.T) {
+	tests := []struct {
+		spec, tz string
+		now      string
+		want     string
+	}{
+		{"daily", "Asia/Shanghai", "2024-03-01T15:30:00+08:00", "2024-03-02T00:00:00+08:00"},
+		{"", "UTC", "2024-03-01T23:59:59Z", "2024-03-02T00:00:00Z"},
+		{"daily@06:30", "UTC", "2024-03-01T05:00:00Z", "2024-03-01T06:30:00Z"},
+		{"daily@06:30", "UTC", "2024-03-01T06:30:00Z", "2024-03-02T06:30:00Z"},
+		{"hourly", "Asia/Kolkata", "2024-03-01T10:45:00+05:30", "2024-03-01T11:00:00+05:30"},
+		// spring forward, the day is 23 hours long
+		{"daily", "America/New_York", "2024-03-10T12:00:00-04:00", "2024-03-11T00:00:00-04:00"},
+	}
+	for _, tt := range tests {
+		s, err := parseRotateSchedule(tt.spec, tt.tz)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.spec, err)
+		}
+		now, _ := time.Parse(time.RFC3339, tt.now)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := s.next(now); !got.Equal(want) {
+			t.Errorf("%s in %s at %s: got %s, want %s", tt.spec, tt.tz, tt.now, got, want)
+		}
+	}
+}
+
+func TestParseRotateSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"weekly", "daily@25:00"} {
+		if _, err := parseRotateSchedule(spec, ""); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+	if _, err := parseRotateSchedule("daily", "Mars/Olympus"); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
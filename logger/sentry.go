@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/sirupsen/logrus"
+)
+
+// sentryFlushTimeout bounds the wait for events to be sent on Fatal, Panic and Close
+const sentryFlushTimeout = 2 * time.Second
+
+// templateKey is the context key of the message template of a formatted entry
+type templateKey struct{}
+
+// withTemplate stores the message template in the context of the entry
+func withTemplate(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, templateKey{}, format)
+}
+
+// sentryHub is the part of the Sentry hub used by the hook
+type sentryHub interface {
+	CaptureEvent(event *sentry.Event) *sentry.EventID
+	Flush(timeout time.Duration) bool
+}
+
+// SentryHook forwards Error, Fatal and Panic entries as Sentry events
+//
+// Events are grouped by the message template, so "order 7 failed" and
+// "order 8 failed" logged with Errorf("order %d failed") are one issue. The
+// release is the version set with SetVersion and the trace id is a tag.
+type SentryHook struct {
+	hub sentryHub
+}
+
+// NewSentryHook creates a hook sending to the DSN with its own Sentry client,
+// the global Sentry hub is left untouched
+func NewSentryHook(c *config.LoggerSentry) (*SentryHook, error) {
+	host, _ := os.Hostname()
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         c.DSN,
+		Environment: c.Environment,
+		SampleRate:  c.SampleRate,
+		ServerName:  host,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sentry client: %w", err)
+	}
+	return &SentryHook{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// Levels returns Error, Fatal and Panic
+func (h *SentryHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+// Fire captures the entry as an event, waiting for it to be sent on Fatal
+// and Panic since the process is about to stop
+func (h *SentryHook) Fire(entry *logrus.Entry) error {
+	h.hub.CaptureEvent(sentryEvent(entry))
+	if entry.Level <= logrus.FatalLevel {
+		h.hub.Flush(sentryFlushTimeout)
+	}
+	return nil
+}
+
+// Close waits for pending events to be sent
+func (h *SentryHook) Close() error {
+	if !h.hub.Flush(sentryFlushTimeout) {
+		return fmt.Errorf("sentry events not sent within %s", sentryFlushTimeout)
+	}
+	return nil
+}
+
+// sentryEvent converts an entry to a Sentry event
+func sentryEvent(entry *logrus.Entry) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	if entry.Level <= logrus.FatalLevel {
+		event.Level = sentry.LevelFatal
+	}
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = "logrus"
+
+	template := entry.Message
+	if entry.Context != nil {
+		if t, ok := entry.Context.Value(templateKey{}).(string); ok {
+			template = t
+		}
+	}
+	event.Fingerprint = []string{template}
+
+	for k, v := range entry.Data {
+		switch k {
+		case VersionKey:
+			event.Release = fmt.Sprint(v)
+		case traceKey:
+			event.Tags[traceKey] = fmt.Sprint(v)
+		case ModuleKey:
+			event.Tags[ModuleKey] = fmt.Sprint(v)
+		case logrus.ErrorKey, ErrorTypeKey:
+			// Reported as the exception below
+		default:
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			event.Extra[k] = v
+		}
+	}
+
+	if msg, ok := entry.Data[logrus.ErrorKey]; ok {
+		exception := sentry.Exception{Value: fmt.Sprint(msg), Type: fmt.Sprint(entry.Data[ErrorTypeKey])}
+		if err, ok := msg.(error); ok {
+			exception.Value = err.Error()
+			exception.Type = fmt.Sprintf("%T", err)
+		} else if _, ok := entry.Data[ErrorTypeKey]; !ok {
+			exception.Type = "error"
+		}
+		event.Exception = []sentry.Exception{exception}
+	}
+	return event
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeHub records captured events
+type fakeHub struct {
+	events  []*sentry.Event
+	flushes int
+}
+
+func (h *fakeHub) CaptureEvent(event *sentry.Event) *sentry.EventID {
+	h.events = append(h.events, event)
+	id := sentry.EventID("id")
+	return &id
+}
+
+func (h *fakeHub) Flush(time.Duration) bool {
+	h.flushes++
+	return true
+}
+
+func TestSentryHook(t *testing.T) {
+	hub := &fakeHub{}
+	l := newLogger()
+	l.SetOutput(io.Discard)
+	l.SetVersion("1.4.2")
+	l.templates = true
+	l.AddHook(&SentryHook{hub: hub})
+
+	ctx := setTraceID(context.Background(), "trace-8")
+	l.Errorf(ctx, "order %d failed", 7)
+	l.Errorf(ctx, "order %d failed", 8)
+	l.Warn(ctx, "not forwarded")
+	l.WithError(ctx, errors.New("card declined")).Error("charge failed")
+
+	if len(hub.events) != 3 {
+		t.Fatalf("captured %d events, want 3", len(hub.events))
+	}
+	first, second := hub.events[0], hub.events[1]
+	if first.Message != "order 7 failed" || first.Level != sentry.LevelError {
+		t.Errorf("unexpected event %q at %s", first.Message, first.Level)
+	}
+	if len(first.Fingerprint) != 1 || first.Fingerprint[0] != "order %d failed" || second.Fingerprint[0] != first.Fingerprint[0] {
+		t.Errorf("expected events grouped by template, got %v and %v", first.Fingerprint, second.Fingerprint)
+	}
+	if first.Release != "1.4.2" || first.Tags[traceKey] != "trace-8" {
+		t.Errorf("unexpected release %q or tags %v", first.Release, first.Tags)
+	}
+
+	withErr := hub.events[2]
+	if len(withErr.Exception) != 1 || withErr.Exception[0].Value != "card declined" || withErr.Exception[0].Type != "*errors.errorString" {
+		t.Errorf("unexpected exception %+v", withErr.Exception)
+	}
+	if hub.flushes != 0 {
+		t.Errorf("expected no flush for error entries, got %d", hub.flushes)
+	}
+
+	entry := logrus.NewEntry(l.Logger)
+	entry.Level = logrus.FatalLevel
+	if err := (&SentryHook{hub: hub}).Fire(entry); err != nil || hub.flushes != 1 {
+		t.Errorf("expected fatal entries flushed, got %d flushes, %v", hub.flushes, err)
+	}
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanIDKey is the field carrying the span id of the active OpenTelemetry span
+const SpanIDKey = "span_id"
+
+// spanEventName is the name of the span events recorded for log entries
+const spanEventName = "log"
+
+// withSpan sets trace_id and span_id from the active span of ctx, they take
+// precedence over a trace id set with the helper package so logs correlate
+// with the traces in Jaeger or Tempo
+func withSpan(ctx context.Context, fields logrus.Fields) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	fields[traceKey] = sc.TraceID().String()
+	fields[SpanIDKey] = sc.SpanID().String()
+}
+
+// SpanEventHook records entries as events of the active span of their context
+type SpanEventHook struct{}
+
+// Levels returns all levels
+func (SpanEventHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the entry as a "log" event with its level, message and fields
+func (SpanEventHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	span := trace.SpanFromContext(entry.Context)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(entry.Data)+2)
+	attrs = append(attrs,
+		attribute.String("log.severity", entry.Level.String()),
+		attribute.String("log.message", entry.Message),
+	)
+	for k, v := range entry.Data {
+		if k == traceKey || k == SpanIDKey {
+			continue
+		}
+		attrs = append(attrs, spanAttribute(k, v))
+	}
+	span.AddEvent(spanEventName, trace.WithTimestamp(entry.Time), trace.WithAttributes(attrs...))
+	return nil
+}
+
+// spanAttribute converts an entry field to a span attribute
+func spanAttribute(k string, v any) attribute.KeyValue {
+	switch val := v.(type) {
+	case string:
+		return attribute.String(k, val)
+	case bool:
+		return attribute.Bool(k, val)
+	case int:
+		return attribute.Int(k, val)
+	case int64:
+		return attribute.Int64(k, val)
+	case float64:
+		return attribute.Float64(k, val)
+	case error:
+		return attribute.String(k, val.Error())
+	default:
+		return attribute.String(k, fmt.Sprint(val))
+	}
+}
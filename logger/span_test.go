@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan is a recording span keeping its events
+type recordingSpan struct {
+	noop.Span
+	sc     trace.SpanContext
+	events []trace.EventConfig
+	names  []string
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordingSpan) AddEvent(name string, opts ...trace.EventOption) {
+	s.names = append(s.names, name)
+	s.events = append(s.events, trace.NewEventConfig(opts...))
+}
+
+func newRecordingSpan(t *testing.T) *recordingSpan {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &recordingSpan{sc: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})}
+}
+
+func TestWithSpan(t *testing.T) {
+	fields := logrus.Fields{}
+	withSpan(context.Background(), fields)
+	if len(fields) != 0 {
+		t.Fatalf("fields without span = %v, want none", fields)
+	}
+
+	span := newRecordingSpan(t)
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	fields = logrus.Fields{traceKey: "home-grown"}
+	withSpan(ctx, fields)
+	if fields[traceKey] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace_id = %v, want the span trace id", fields[traceKey])
+	}
+	if fields[SpanIDKey] != "00f067aa0ba902b7" {
+		t.Errorf("span_id = %v, want the span id", fields[SpanIDKey])
+	}
+}
+
+func TestSpanEventHook(t *testing.T) {
+	span := newRecordingSpan(t)
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(SpanEventHook{})
+	l.WithContext(ctx).WithFields(logrus.Fields{"order": 42, traceKey: "x"}).Warn("slow order")
+	l.Info("no context")
+
+	if len(span.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(span.events))
+	}
+	if span.names[0] != spanEventName {
+		t.Errorf("event name = %q, want %q", span.names[0], spanEventName)
+	}
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.events[0].Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	if attrs["log.message"] != "slow order" || attrs["log.severity"] != "warning" || attrs["order"] != "42" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	if _, ok := attrs[attribute.Key(traceKey)]; ok {
+		t.Errorf("attributes contain %s", traceKey)
+	}
+}
+
+func TestSpanEventHook_Redacted(t *testing.T) {
+	l, cleanup, err := New(&config.Logger{
+		Level:      int(logrus.InfoLevel),
+		Format:     "json",
+		SpanEvents: true,
+		Redact:     &config.LoggerRedact{Fields: []string{"password"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	defer cleanup()
+	l.SetOutput(io.Discard)
+
+	span := newRecordingSpan(t)
+	ctx := trace.ContextWithSpan(context.Background(), span)
+	l.EntryWithFields(ctx, logrus.Fields{"password": "hunter2"}).Info("login")
+
+	if len(span.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(span.events))
+	}
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.events[0].Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	if attrs["password"] != RedactMask {
+		t.Errorf("password attribute = %q, want %q", attrs["password"], RedactMask)
+	}
+}
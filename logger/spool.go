@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"ncobase/common/config"
+)
+
+// ErrSpoolFull is returned by Append when the spool reached its max size
+var ErrSpoolFull = errors.New("log spool full, batch dropped")
+
+// drainSuffix marks a spool file being re-sent
+const drainSuffix = ".drain"
+
+// Spool is a disk-backed queue of batches that could not be shipped
+//
+// Each failed batch is appended to the file as one JSON array per line. Drain
+// moves the file aside before re-sending so appends continue meanwhile, the
+// batches still unsent after a failure stay in that file and are re-sent
+// before newer ones. A file left aside by a crash is drained first on the next
+// run.
+type Spool struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64 // 0 means unlimited
+	size    int64 // bytes appended to path
+	pending int64 // bytes left aside for draining
+	dropped atomic.Int64
+}
+
+// OpenSpool opens the spool file at path, creating its directory
+func OpenSpool(path string, maxSizeMB int) (*Spool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	s := &Spool{path: path, maxSize: int64(maxSizeMB) * megabyte}
+	for file, size := range map[string]*int64{path: &s.size, path + drainSuffix: &s.pending} {
+		info, err := os.Stat(file)
+		switch {
+		case err == nil:
+			*size = info.Size()
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to stat spool: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Append writes the batch to the spool, dropping it when the spool is full
+func (s *Spool) Append(docs []any) error {
+	line, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spooled batch: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendLines(line, len(docs))
+}
+
+// appendLines appends encoded lines holding count docs, s.mu must be held
+func (s *Spool) appendLines(lines []byte, count int) error {
+	if s.maxSize > 0 && s.size+s.pending+int64(len(lines)) > s.maxSize {
+		s.dropped.Add(int64(count))
+		return ErrSpoolFull
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open spool: %w", err)
+	}
+	n, err := f.Write(lines)
+	s.size += int64(n)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Drain re-sends the spooled batches in order, stopping at the first failure
+func (s *Spool) Drain(send func(docs []any) error) error {
+	draining := s.path + drainSuffix
+	for {
+		s.mu.Lock()
+		if s.pending == 0 {
+			if s.size == 0 {
+				s.mu.Unlock()
+				return nil
+			}
+			if err := os.Rename(s.path, draining); err != nil {
+				s.mu.Unlock()
+				return fmt.Errorf("failed to move spool aside: %w", err)
+			}
+			s.pending, s.size = s.size, 0
+		}
+		s.mu.Unlock()
+
+		if err := s.drainFile(draining, send); err != nil {
+			return err
+		}
+	}
+}
+
+// drainFile re-sends the batches of the file moved aside and removes it, on a
+// failure the unsent batches are written back to it, ahead of newer appends
+func (s *Spool) drainFile(draining string, send func(docs []any) error) error {
+	data, err := os.ReadFile(draining)
+	if err != nil {
+		return fmt.Errorf("failed to read spool: %w", err)
+	}
+
+	var sendErr error
+	rest := data
+	for len(rest) > 0 {
+		line := rest
+		next := len(rest)
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, next = rest[:i], i+1
+		}
+		// A torn line from a crash is skipped
+		var docs []any
+		if json.Unmarshal(line, &docs) == nil {
+			if sendErr = send(docs); sendErr != nil {
+				break
+			}
+		}
+		rest = rest[next:]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sendErr == nil {
+		if err := os.Remove(draining); err != nil {
+			return fmt.Errorf("failed to remove drained spool: %w", err)
+		}
+		s.pending = 0
+		return nil
+	}
+	if len(rest) < len(data) {
+		tmp := draining + ".tmp"
+		if err := os.WriteFile(tmp, rest, 0644); err != nil {
+			return fmt.Errorf("failed to rewrite spool: %w", err)
+		}
+		if err := os.Rename(tmp, draining); err != nil {
+			return fmt.Errorf("failed to rewrite spool: %w", err)
+		}
+		s.pending = int64(len(rest))
+	}
+	return sendErr
+}
+
+// Size returns the number of bytes waiting in the spool
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size + s.pending
+}
+
+// Dropped returns the number of entries dropped because the spool was full
+func (s *Spool) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// withSpool attaches the spool of the named hook, before the hook is started
+func (h *BatchHook) withSpool(c *config.LoggerSpool, name string) error {
+	if c == nil || c.Dir == "" {
+		return nil
+	}
+	s, err := OpenSpool(filepath.Join(c.Dir, name+".spool"), c.MaxSizeMB)
+	if err != nil {
+		return err
+	}
+	h.spool, h.retry = s, c.RetryInterval
+	return nil
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+)
+
+func TestSpool_DrainInOrder(t *testing.T) {
+	s, err := OpenSpool(filepath.Join(t.TempDir(), "es.spool"), 0)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.Append([]any{map[string]any{"n": i}}); err != nil {
+			t.Fatalf("unexpected append error: %v", err)
+		}
+	}
+
+	down := errors.New("cluster down")
+	var sent []float64
+	failAfter := 1
+	send := func(docs []any) error {
+		if len(sent) == failAfter {
+			return down
+		}
+		sent = append(sent, docs[0].(map[string]any)["n"].(float64))
+		return nil
+	}
+	if err := s.Drain(send); !errors.Is(err, down) {
+		t.Fatalf("expected send error, got %v", err)
+	}
+	if s.Size() == 0 {
+		t.Fatal("expected unsent batches kept in the spool")
+	}
+
+	failAfter = -1
+	if err := s.Drain(send); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if len(sent) != 3 || sent[0] != 0 || sent[1] != 1 || sent[2] != 2 {
+		t.Errorf("expected batches re-sent in order, got %v", sent)
+	}
+	if s.Size() != 0 {
+		t.Errorf("expected empty spool, got %d bytes", s.Size())
+	}
+}
+
+// drainWithAppend appends batch n of size bytes while the second spooled batch
+// fails to send, then reopens the spool and drains it, returning the order of
+// the batches sent and the error of the append
+func drainWithAppend(t *testing.T, n, size int) ([]float64, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "es.spool")
+	s, err := OpenSpool(path, 1)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	batch := func(n, size int) []any {
+		return []any{map[string]any{"n": n, "pad": strings.Repeat("x", size)}}
+	}
+	for i := 0; i < 3; i++ {
+		if err := s.Append(batch(i, 200*1024)); err != nil {
+			t.Fatalf("unexpected append error: %v", err)
+		}
+	}
+
+	down := errors.New("cluster down")
+	var sent []float64
+	var appended bool
+	var appendErr error
+	send := func(docs []any) error {
+		if len(sent) == 1 && !appended {
+			appended = true
+			appendErr = s.Append(batch(n, size))
+			return down
+		}
+		sent = append(sent, docs[0].(map[string]any)["n"].(float64))
+		return nil
+	}
+	if err := s.Drain(send); !errors.Is(err, down) {
+		t.Fatalf("expected send error, got %v", err)
+	}
+
+	// A reopened spool still holds the remainder
+	if s, err = OpenSpool(path, 1); err != nil {
+		t.Fatalf("failed to reopen spool: %v", err)
+	}
+	if err := s.Drain(send); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if s.Size() != 0 {
+		t.Errorf("expected empty spool, got %d bytes", s.Size())
+	}
+	return sent, appendErr
+}
+
+func TestSpool_DrainKeepsRemainderFirst(t *testing.T) {
+	sent, err := drainWithAppend(t, 3, 100*1024)
+	if err != nil {
+		t.Fatalf("unexpected append error: %v", err)
+	}
+	if len(sent) != 4 || sent[0] != 0 || sent[1] != 1 || sent[2] != 2 || sent[3] != 3 {
+		t.Errorf("expected the remainder re-sent before newer batches, got %v", sent)
+	}
+}
+
+func TestSpool_DrainFullKeepsRemainder(t *testing.T) {
+	sent, err := drainWithAppend(t, 3, 700*1024)
+	if !errors.Is(err, ErrSpoolFull) {
+		t.Fatalf("expected spool full, got %v", err)
+	}
+	if len(sent) != 3 || sent[0] != 0 || sent[1] != 1 || sent[2] != 2 {
+		t.Errorf("expected the remainder kept over newer batches, got %v", sent)
+	}
+}
+
+func TestSpool_MaxSize(t *testing.T) {
+	s, err := OpenSpool(filepath.Join(t.TempDir(), "es.spool"), 1)
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	big := make([]byte, 600*1024)
+	for i := range big {
+		big[i] = 'x'
+	}
+	docs := []any{map[string]any{"msg": string(big)}, map[string]any{"msg": "small"}}
+	if err := s.Append(docs); err != nil {
+		t.Fatalf("unexpected append error: %v", err)
+	}
+	if err := s.Append(docs); !errors.Is(err, ErrSpoolFull) {
+		t.Fatalf("expected spool full, got %v", err)
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("expected 2 dropped entries, got %d", s.Dropped())
+	}
+}
+
+func TestBatchHook_Spool(t *testing.T) {
+	var up atomic.Bool
+	rec := &batchRecorder{}
+	send := func(docs []any) error {
+		if !up.Load() {
+			return errors.New("unavailable")
+		}
+		return rec.send(docs)
+	}
+
+	h := newBatchHook(&config.LoggerSearch{BatchSize: 2, BufferSize: 10, FlushInterval: time.Hour}, send)
+	if err := h.withSpool(&config.LoggerSpool{Dir: t.TempDir(), RetryInterval: 10 * time.Millisecond}, "elasticsearch"); err != nil {
+		t.Fatalf("failed to attach spool: %v", err)
+	}
+	h.start()
+	defer h.Close()
+
+	fireN(t, h, 2)
+	deadline := time.Now().Add(time.Second)
+	for h.spool.Size() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if h.Failed() != 0 {
+		t.Errorf("spooled entries must not count as failed, got %d", h.Failed())
+	}
+
+	up.Store(true)
+	for len(rec.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("expected spooled batch re-sent, got %v", sizes)
+	}
+}
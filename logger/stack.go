@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StackKey is the field carrying the stack trace of Error and above
+const StackKey = "stack"
+
+// loggerPackage prefixes the function names of this package
+const loggerPackage = "ncobase/common/logger."
+
+// StackOptions controls stack trace capture, the zero value disables it
+type StackOptions struct {
+	Enabled bool
+	Skip    int // extra frames skipped above the logging call site
+	Depth   int // max frames captured, default 32
+}
+
+// defaultStackDepth is used when StackOptions.Depth is not set
+const defaultStackDepth = 32
+
+// withStack adds the stack trace to fields when enabled for the level
+func (l *Logger) withStack(fields logrus.Fields, level logrus.Level) logrus.Fields {
+	opts := l.root().stack
+	if opts.Enabled && level <= logrus.ErrorLevel {
+		fields[StackKey] = captureStack(opts.Skip, opts.Depth)
+	}
+	return fields
+}
+
+// captureStack formats the stack of the logging call site, one
+// "function file:line" per line, frames of this package and logrus are
+// trimmed before skip frames are dropped
+func captureStack(skip, depth int) string {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+	pcs := make([]uintptr, depth+skip+16)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	inLogger, count := true, 0
+	for {
+		frame, more := frames.Next()
+		if inLogger && internalFrame(frame) {
+			if !more {
+				break
+			}
+			continue
+		}
+		inLogger = false
+		if frame.Function == "runtime.main" || frame.Function == "runtime.goexit" {
+			break
+		}
+		if skip > 0 {
+			skip--
+		} else {
+			if count > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteByte(' ')
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+			if count++; count == depth {
+				break
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// internalFrame reports whether the frame belongs to the logging machinery
+// rather than the caller, tests of this package count as callers
+func internalFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, loggerPackage) ||
+		strings.HasPrefix(frame.Function, "github.com/sirupsen/logrus.")
+}
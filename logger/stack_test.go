@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func stackFromHelper(skip, depth int) string {
+	return captureStack(skip, depth)
+}
+
+func TestCaptureStack(t *testing.T) {
+	stack := stackFromHelper(0, 2)
+	lines := strings.Split(stack, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected depth of 2 frames, got %q", stack)
+	}
+	if !strings.Contains(lines[0], "logger.stackFromHelper ") || !strings.Contains(lines[0], "stack_test.go:") {
+		t.Errorf("expected call site first, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "logger.TestCaptureStack ") {
+		t.Errorf("expected test function second, got %q", lines[1])
+	}
+
+	skipped := stackFromHelper(1, 1)
+	if !strings.HasPrefix(skipped, "ncobase/common/logger.TestCaptureStack ") {
+		t.Errorf("expected helper frame skipped, got %q", skipped)
+	}
+}
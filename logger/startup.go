@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+
+	"ncobase/common/config"
+	"ncobase/common/helper"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogStartup emits a single info entry describing what is running: app
+// version, build info and the effective configuration with secrets masked.
+// It does not depend on Init, so it is safe to call before hooks are set up.
+func (l *Logger) LogStartup(ctx context.Context, cfg *config.Config) {
+	version := l.version
+	if version == "" {
+		version = helper.Version
+	}
+
+	fields := logrus.Fields{
+		"app_version": version,
+		"build": map[string]string{
+			"branch":     helper.Branch,
+			"revision":   helper.Revision,
+			"built_at":   helper.BuiltAt,
+			"go_version": runtime.Version(),
+		},
+	}
+
+	appName := ""
+	if cfg != nil {
+		appName = cfg.AppName
+		if redacted, err := cfg.Redacted(); err != nil {
+			fields["config_error"] = err.Error()
+		} else {
+			fields["config"] = redacted
+		}
+	}
+
+	l.EntryWithFields(ctx, fields).Infof("starting %s", appName)
+}
+
+// LogStartup emits the startup entry with the package-level logger
+func LogStartup(ctx context.Context, cfg *config.Config) { StdLogger().LogStartup(ctx, cfg) }
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syslogFacilities maps facility names to their RFC 5424 codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogLocalSockets are tried in order for local delivery
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogHook sends entries as RFC 5424 messages to a local or remote syslog daemon
+//
+// Remote delivery supports udp, tcp and tls, stream transports use octet
+// counting framing (RFC 6587). A failed write redials once before giving up.
+type SyslogHook struct {
+	mu        sync.Mutex
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	facility  int
+	tag       string
+	hostname  string
+	formatter logrus.Formatter
+	conn      net.Conn
+}
+
+// NewSyslogHook creates a syslog hook and connects to the daemon
+//
+// Messages are formatted with formatter, when nil the text formatter is used.
+func NewSyslogHook(c *config.LoggerSyslog, formatter logrus.Formatter) (*SyslogHook, error) {
+	facility, ok := syslogFacilities[strings.ToLower(c.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", c.Facility)
+	}
+	if formatter == nil {
+		formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	}
+
+	hostname, _ := os.Hostname()
+	h := &SyslogHook{
+		network:   strings.ToLower(c.Network),
+		address:   c.Address,
+		facility:  facility,
+		tag:       c.Tag,
+		hostname:  hostname,
+		formatter: formatter,
+	}
+	if h.network == "tls" {
+		h.tlsConfig = &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	}
+
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Levels returns all log levels
+func (h *SyslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire sends the entry to syslog
+func (h *SyslogHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return fmt.Errorf("failed to format syslog message: %w", err)
+	}
+	msg := h.message(entry, bytes.TrimRight(line, "\n"))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn != nil {
+		if err := h.write(msg); err == nil {
+			return nil
+		}
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+	if err := h.connect(); err != nil {
+		return err
+	}
+	return h.write(msg)
+}
+
+// Close closes the connection to the daemon
+func (h *SyslogHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+// message builds the RFC 5424 message for the entry
+func (h *SyslogHook) message(entry *logrus.Entry, line []byte) []byte {
+	pri := h.facility*8 + syslogSeverity(entry.Level)
+	header := fmt.Sprintf("<%d>1 %s %s %s %d - - ",
+		pri,
+		entry.Time.Format(time.RFC3339Nano),
+		nilValue(h.hostname),
+		nilValue(h.tag),
+		os.Getpid(),
+	)
+	return append([]byte(header), line...)
+}
+
+// write writes msg with the framing of the transport
+func (h *SyslogHook) write(msg []byte) error {
+	if h.stream() {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+	_, err := h.conn.Write(msg)
+	return err
+}
+
+// stream reports whether the transport is a byte stream
+func (h *SyslogHook) stream() bool {
+	return h.network == "tcp" || h.network == "tls"
+}
+
+// connect dials the daemon, a local socket when no network is configured
+func (h *SyslogHook) connect() error {
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch h.network {
+	case "":
+		for _, path := range syslogLocalSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err = net.Dial(network, path); err == nil {
+					h.conn = conn
+					return nil
+				}
+			}
+		}
+		return errors.New("no local syslog socket found")
+	case "tls":
+		conn, err = tls.Dial("tcp", h.address, h.tlsConfig)
+	case "udp", "tcp":
+		conn, err = net.Dial(h.network, h.address)
+	default:
+		return fmt.Errorf("unsupported syslog network %q", h.network)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	h.conn = conn
+	return nil
+}
+
+// syslogSeverity maps a logrus level to a syslog severity
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0 // emergency
+	case logrus.FatalLevel:
+		return 2 // critical
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7 // debug
+	}
+}
+
+// nilValue returns the RFC 5424 nil value for empty header fields
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(s, " ", "_")
+}
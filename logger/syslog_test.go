@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func syslogEntry(level logrus.Level, msg string) *logrus.Entry {
+	e := logrus.NewEntry(logrus.New())
+	e.Level, e.Message, e.Time = level, msg, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return e
+}
+
+func TestSyslogHook_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer pc.Close()
+
+	h, err := NewSyslogHook(&config.LoggerSyslog{Network: "udp", Address: pc.LocalAddr().String(), Facility: "local0", Tag: "billing"}, nil)
+	if err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+	defer h.Close()
+
+	if err := h.Fire(syslogEntry(logrus.ErrorLevel, "charge failed")); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read datagram: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// local0 (16) * 8 + error (3) = 131
+	if !strings.HasPrefix(msg, "<131>1 2024-05-01T12:00:00Z ") {
+		t.Errorf("unexpected header: %q", msg)
+	}
+	if !strings.Contains(msg, " billing ") || !strings.Contains(msg, "charge failed") {
+		t.Errorf("expected tag and message, got %q", msg)
+	}
+}
+
+func TestSyslogHook_TCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		size, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(size))
+		msg := make([]byte, n)
+		_, _ = r.Read(msg)
+		received <- string(msg)
+	}()
+
+	h, err := NewSyslogHook(&config.LoggerSyslog{Network: "tcp", Address: ln.Addr().String(), Facility: "daemon"}, nil)
+	if err != nil {
+		t.Fatalf("failed to create hook: %v", err)
+	}
+	defer h.Close()
+
+	if err := h.Fire(syslogEntry(logrus.InfoLevel, "started")); err != nil {
+		t.Fatalf("unexpected fire error: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		// daemon (3) * 8 + info (6) = 30
+		if !strings.HasPrefix(msg, "<30>1 ") || !strings.HasSuffix(msg, "msg=started") {
+			t.Errorf("unexpected octet counted message: %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestNewSyslogHook_UnknownFacility(t *testing.T) {
+	if _, err := NewSyslogHook(&config.LoggerSyslog{Network: "udp", Address: "127.0.0.1:514", Facility: "nope"}, nil); err == nil {
+		t.Error("expected error for unknown facility")
+	}
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newFormatter returns the formatter of a format name, text by default
+func newFormatter(c *config.Logger, format string) logrus.Formatter {
+	switch format {
+	case "json":
+		return &DurationFormatter{Formatter: &logrus.JSONFormatter{}}
+	case "gelf":
+		return &GELFFormatter{}
+	case "console":
+		return &ConsoleFormatter{}
+	case "ecs":
+		return &ECSFormatter{ServiceName: c.ServiceName}
+	default:
+		return &logrus.TextFormatter{}
+	}
+}
+
+// WriterHook writes entries to a writer with its own formatter, used for
+// outputs whose format differs from the logger formatter
+type WriterHook struct {
+	mu        sync.Mutex
+	out       io.Writer
+	formatter logrus.Formatter
+}
+
+// NewWriterHook creates a hook writing every entry to out
+func NewWriterHook(out io.Writer, formatter logrus.Formatter) *WriterHook {
+	return &WriterHook{out: out, formatter: formatter}
+}
+
+// Levels returns all levels
+func (h *WriterHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire formats the entry and writes it out
+func (h *WriterHook) Fire(entry *logrus.Entry) error {
+	b, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.out.Write(b)
+	return err
+}
+
+// teeGroup collects the writers sharing a format
+type teeGroup struct {
+	formatter logrus.Formatter
+	writers   []io.Writer
+}
+
+// setupTee sets up the outputs of config.Logger.Outputs
+//
+// Destinations sharing a format are combined with io.MultiWriter so an entry
+// is formatted once per format. The first format becomes the logger output,
+// the others are written by a WriterHook each. At most one file is supported
+// since rotation is tied to the logger, it is buffered by FileBuffer like the
+// file output.
+func (l *Logger) setupTee(c *config.Logger, done <-chan struct{}) ([]func(), error) {
+	var (
+		groups   []*teeGroup
+		byFormat = make(map[string]*teeGroup)
+		closers  []func()
+		files    int
+
+		buffered   bool // the file output is buffered by FileBuffer
+		flushLevel logrus.Level
+	)
+	for _, o := range c.Outputs {
+		if o.Type == "file" {
+			files++
+		}
+	}
+	if files > 1 {
+		return nil, errors.New("only one file log output is supported")
+	}
+
+	for _, o := range c.Outputs {
+		formatter := newFormatter(c, o.Format)
+		var w io.Writer
+		switch o.Type {
+		case "stdout":
+			w = os.Stdout
+		case "stderr":
+			w = os.Stderr
+		case "custom":
+			if l.custom == nil {
+				return nil, errors.New("custom log output requires SetCustomWriter before Init")
+			}
+			w = l.custom
+		case "syslog":
+			if c.Syslog == nil {
+				return nil, errors.New("syslog output requires syslog config")
+			}
+			hook, err := NewSyslogHook(c.Syslog, formatter)
+			if err != nil {
+				return nil, err
+			}
+			l.addHook("syslog", hook)
+			closers = append(closers, func() { _ = hook.Close() })
+			continue
+		case "file":
+			l.logPath = o.Path
+			if l.logPath == "" {
+				l.logPath = c.OutputFile
+			}
+			l.rotate = l.rotateOptions(c)
+			if err := l.openLogFile(); err != nil {
+				if !c.FallbackToStderr {
+					return nil, err
+				}
+				_, _ = fmt.Fprintf(os.Stderr, "Log file %s unavailable, falling back to stderr: %v\n", l.logPath, err)
+				w = os.Stderr
+			} else {
+				w = l.logFile
+				if c.FileBuffer != nil && c.FileBuffer.SizeKB > 0 {
+					level, err := l.startFileBuffer(c.FileBuffer, done)
+					if err != nil {
+						return nil, err
+					}
+					w = l.fileBuf
+					flushLevel, buffered = level, true
+				}
+				go l.periodicLogRotation(done)
+			}
+		default:
+			return nil, fmt.Errorf("unknown log output %q", o.Type)
+		}
+
+		g, ok := byFormat[o.Format]
+		if !ok {
+			g = &teeGroup{formatter: formatter}
+			byFormat[o.Format] = g
+			groups = append(groups, g)
+		}
+		g.writers = append(g.writers, w)
+		if o.Type == "file" && buffered {
+			// Entries at the flush level reach the file without waiting
+			g.formatter = &flushFormatter{Formatter: g.formatter, w: l.fileBuf, level: flushLevel}
+		}
+	}
+
+	if len(groups) == 0 {
+		l.SetOutput(io.Discard)
+		return closers, nil
+	}
+	l.SetFormatter(groups[0].formatter)
+	l.SetOutput(io.MultiWriter(groups[0].writers...))
+	for _, g := range groups[1:] {
+		l.AddHook(NewSafeHook("tee", NewWriterHook(io.MultiWriter(g.writers...), g.formatter)))
+	}
+	return closers, nil
+}
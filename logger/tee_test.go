@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ncobase/common/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWriterHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&bytes.Buffer{})
+	l.AddHook(NewWriterHook(&buf, &logrus.TextFormatter{DisableTimestamp: true}))
+
+	l.WithField("k", "v").Info("hello")
+	if got := buf.String(); got != "level=info msg=hello k=v\n" {
+		t.Errorf("unexpected hook output %q", got)
+	}
+}
+
+func TestInit_Outputs(t *testing.T) {
+	var buf bytes.Buffer
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := newLogger()
+	l.SetCustomWriter(&buf)
+
+	cleanup, err := l.Init(&config.Logger{
+		Level: int(logrus.InfoLevel),
+		Outputs: []config.LoggerOutput{
+			{Type: "custom", Format: "text"},
+			{Type: "file", Format: "json", Path: path},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected init error: %v", err)
+	}
+	l.Info(context.Background(), "to both")
+	cleanup()
+
+	if !strings.Contains(buf.String(), `msg="to both"`) {
+		t.Errorf("expected text line in custom writer, got %q", buf.String())
+	}
+	// The file is named after its rotation period, e.g. app.2006-01-02.log
+	data, err := os.ReadFile(l.logFile.current())
+	if err != nil {
+		t.Fatalf("read log file: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("expected json line in file, got %q", data)
+	}
+	if entry["msg"] != "to both" {
+		t.Errorf("unexpected file entry %v", entry)
+	}
+
+	// A buffered file output is flushed by cleanup
+	var out bytes.Buffer
+	buffered := newLogger()
+	buffered.SetCustomWriter(&out)
+	cleanup, err = buffered.Init(&config.Logger{
+		Level:      int(logrus.InfoLevel),
+		FileBuffer: &config.LoggerFileBuffer{SizeKB: 64, FlushInterval: time.Hour},
+		Outputs: []config.LoggerOutput{
+			{Type: "custom", Format: "json"},
+			{Type: "file", Format: "json", Path: filepath.Join(t.TempDir(), "app.log")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected init error: %v", err)
+	}
+	buffered.Info(context.Background(), "buffered")
+	if buffered.fileBuf == nil {
+		t.Fatal("expected the file output to be buffered")
+	}
+	cleanup()
+	if data, err := os.ReadFile(buffered.logFile.current()); err != nil || !strings.Contains(string(data), `"msg":"buffered"`) {
+		t.Errorf("expected buffered entry in file after cleanup, got %q, %v", data, err)
+	}
+
+	_, err = newLogger().Init(&config.Logger{Outputs: []config.LoggerOutput{{Type: "file", Path: path}, {Type: "file", Path: path}}})
+	if err == nil {
+		t.Error("expected error for two file outputs")
+	}
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// Trace propagation headers
+const (
+	TraceParentHeader = "traceparent"  // W3C Trace Context
+	B3Header          = "b3"           // B3 single header
+	B3TraceIDHeader   = "x-b3-traceid" // B3 multi headers
+	B3SpanIDHeader    = "x-b3-spanid"
+	B3SampledHeader   = "x-b3-sampled"
+)
+
+// ErrInvalidTraceParent is returned when a traceparent or B3 header is malformed
+var ErrInvalidTraceParent = errors.New("invalid trace context header")
+
+// traceParentVersion is the W3C Trace Context version written
+const traceParentVersion = "00"
+
+// TraceParent is the trace context propagated between services
+type TraceParent struct {
+	TraceID string // 32 lowercase hex digits
+	SpanID  string // 16 lowercase hex digits, the span of the caller
+	Sampled bool
+}
+
+// NewTraceParent returns a trace context with random ids
+func NewTraceParent() TraceParent {
+	return TraceParent{TraceID: randomHex(16), SpanID: randomHex(8), Sampled: true}
+}
+
+// ParseTraceParent parses a W3C traceparent header,
+// e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceParent(h string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[0]) {
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	// Version 00 has exactly four parts, later versions may append more
+	if parts[0] == traceParentVersion && len(parts) != 4 {
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	p := TraceParent{TraceID: parts[1], SpanID: parts[2]}
+	if !validID(p.TraceID, 32) || !validID(p.SpanID, 16) || len(parts[3]) != 2 || !isHex(parts[3]) {
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	p.Sampled = flags[0]&1 == 1
+	return p, nil
+}
+
+// String formats the trace context as a W3C traceparent header
+func (p TraceParent) String() string {
+	flags := "00"
+	if p.Sampled {
+		flags = "01"
+	}
+	return traceParentVersion + "-" + p.TraceID + "-" + p.SpanID + "-" + flags
+}
+
+// ParseB3 parses a B3 single header, e.g.
+// 80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90,
+// 64 bit trace ids are padded to 128 bits
+func ParseB3(h string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 2 {
+		// Only a sampling decision, e.g. "0", carries no ids
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	sampled := ""
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+	return b3TraceParent(parts[0], parts[1], sampled)
+}
+
+// B3 formats the trace context as a B3 single header
+func (p TraceParent) B3() string {
+	sampled := "0"
+	if p.Sampled {
+		sampled = "1"
+	}
+	return p.TraceID + "-" + p.SpanID + "-" + sampled
+}
+
+// b3TraceParent validates B3 ids and sampling state
+func b3TraceParent(traceID, spanID, sampled string) (TraceParent, error) {
+	traceID, spanID = strings.ToLower(traceID), strings.ToLower(spanID)
+	if len(traceID) == 16 {
+		traceID = strings.Repeat("0", 16) + traceID
+	}
+	if !validID(traceID, 32) || !validID(spanID, 16) {
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	p := TraceParent{TraceID: traceID, SpanID: spanID, Sampled: true}
+	switch sampled {
+	case "", "1", "d", "true":
+	case "0", "false":
+		p.Sampled = false
+	default:
+		return TraceParent{}, ErrInvalidTraceParent
+	}
+	return p, nil
+}
+
+// ExtractHTTP reads the trace context of the traceparent, b3 or X-B3-* headers
+func ExtractHTTP(h http.Header) (TraceParent, bool) {
+	return extractTraceParent(h.Get)
+}
+
+// InjectHTTP sets the traceparent and b3 headers of the trace context of ctx
+func InjectHTTP(ctx context.Context, h http.Header) {
+	if p, ok := TraceParentFromContext(ctx); ok {
+		h.Set(TraceParentHeader, p.String())
+		h.Set(B3Header, p.B3())
+	}
+}
+
+// ExtractGRPC reads the trace context of the traceparent, b3 or x-b3-* metadata
+func ExtractGRPC(md metadata.MD) (TraceParent, bool) {
+	return extractTraceParent(func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// InjectGRPC sets the traceparent and b3 metadata of the trace context of ctx
+func InjectGRPC(ctx context.Context, md metadata.MD) {
+	if p, ok := TraceParentFromContext(ctx); ok {
+		md.Set(TraceParentHeader, p.String())
+		md.Set(B3Header, p.B3())
+	}
+}
+
+// extractTraceParent reads the first valid trace context header
+func extractTraceParent(get func(key string) string) (TraceParent, bool) {
+	if h := get(TraceParentHeader); h != "" {
+		if p, err := ParseTraceParent(h); err == nil {
+			return p, true
+		}
+	}
+	if h := get(B3Header); h != "" {
+		if p, err := ParseB3(h); err == nil {
+			return p, true
+		}
+	}
+	if traceID := get(B3TraceIDHeader); traceID != "" {
+		if p, err := b3TraceParent(traceID, get(B3SpanIDHeader), get(B3SampledHeader)); err == nil {
+			return p, true
+		}
+	}
+	return TraceParent{}, false
+}
+
+// TraceParentFromContext returns the trace context to send downstream, the
+// active span when there is one, else the trace id of ctx with a new span id,
+// false when ctx has no trace id or it can't be written as a W3C trace id
+func TraceParentFromContext(ctx context.Context) (TraceParent, bool) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return TraceParent{TraceID: sc.TraceID().String(), SpanID: sc.SpanID().String(), Sampled: sc.IsSampled()}, true
+	}
+	traceID, ok := w3cTraceID(getTraceID(ctx))
+	if !ok {
+		return TraceParent{}, false
+	}
+	return TraceParent{TraceID: traceID, SpanID: randomHex(8), Sampled: true}, true
+}
+
+// w3cTraceID converts a trace id, e.g. a UUID, to 32 lowercase hex digits
+func w3cTraceID(id string) (string, bool) {
+	id = strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	if len(id) == 16 {
+		id = strings.Repeat("0", 16) + id
+	}
+	return id, validID(id, 32)
+}
+
+// validID reports whether id is n hex digits, not all zero
+func validID(id string, n int) bool {
+	return len(id) == n && isHex(id) && strings.Trim(id, "0") != ""
+}
+
+// isHex reports whether s only holds lowercase hex digits
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
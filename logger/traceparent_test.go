@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	p, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || p.SpanID != "00f067aa0ba902b7" || !p.Sampled {
+		t.Fatalf("unexpected trace parent %+v", p)
+	}
+	if got := p.String(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, h := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceParent(h); !errors.Is(err, ErrInvalidTraceParent) {
+			t.Errorf("ParseTraceParent(%q) error = %v, want ErrInvalidTraceParent", h, err)
+		}
+	}
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Errorf("expected later versions to allow more parts, got %v", err)
+	}
+}
+
+func TestParseB3(t *testing.T) {
+	p, err := ParseB3("80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0-05e3ac9a4f6e3b90")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TraceID != "80f198ee56343ba864fe8b2a57d3eff7" || p.SpanID != "e457b5a2e4d86bd1" || p.Sampled {
+		t.Fatalf("unexpected trace parent %+v", p)
+	}
+	if p.B3() != "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0" {
+		t.Errorf("B3() = %q", p.B3())
+	}
+
+	p, err = ParseB3("64fe8b2a57d3eff7-e457b5a2e4d86bd1")
+	if err != nil || p.TraceID != "000000000000000064fe8b2a57d3eff7" || !p.Sampled {
+		t.Fatalf("expected padded 64 bit trace id, got %+v, %v", p, err)
+	}
+	for _, h := range []string{"0", "1", "64fe8b2a57d3eff7-xyz", "64fe8b2a57d3eff7-e457b5a2e4d86bd1-2"} {
+		if _, err := ParseB3(h); !errors.Is(err, ErrInvalidTraceParent) {
+			t.Errorf("ParseB3(%q) error = %v, want ErrInvalidTraceParent", h, err)
+		}
+	}
+}
+
+func TestExtractHTTP(t *testing.T) {
+	h := http.Header{}
+	if _, ok := ExtractHTTP(h); ok {
+		t.Fatal("expected no trace context without headers")
+	}
+
+	h.Set("X-B3-TraceId", "463AC35C9F6413AD48485A3953BB6124")
+	h.Set("X-B3-SpanId", "a2fb4a1d1a96d312")
+	h.Set("X-B3-Sampled", "1")
+	p, ok := ExtractHTTP(h)
+	if !ok || p.TraceID != "463ac35c9f6413ad48485a3953bb6124" {
+		t.Fatalf("expected trace context from B3 multi headers, got %+v", p)
+	}
+
+	h.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if p, _ := ExtractHTTP(h); p.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected traceparent preferred, got %+v", p)
+	}
+}
+
+func TestInjectGRPC(t *testing.T) {
+	md := metadata.MD{}
+	InjectGRPC(context.Background(), md)
+	if len(md) != 0 {
+		t.Fatalf("expected nothing injected without a trace id, got %v", md)
+	}
+
+	ctx := setTraceID(context.Background(), "4bf92f35-77b3-4da6-a3ce-929d0e0e4736")
+	InjectGRPC(ctx, md)
+	p, ok := ExtractGRPC(md)
+	if !ok || p.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || !validID(p.SpanID, 16) {
+		t.Fatalf("expected injected trace context, got %+v from %v", p, md)
+	}
+	if b3 := md.Get(B3Header); len(b3) != 1 || b3[0] != p.B3() {
+		t.Errorf("expected b3 metadata, got %v", b3)
+	}
+}
+
+func TestEnsureTraceID_W3C(t *testing.T) {
+	ctx, traceID := EnsureTraceID(context.Background())
+	if !validID(traceID, 32) {
+		t.Fatalf("expected a W3C trace id, got %q", traceID)
+	}
+	if _, again := EnsureTraceID(ctx); again != traceID {
+		t.Errorf("expected the trace id kept, got %q", again)
+	}
+}
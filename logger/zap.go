@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapBackend writes records to a zap logger
+type ZapBackend struct {
+	core zapcore.Core
+}
+
+// NewZapBackend creates a backend writing to the given zap logger
+//
+// Records are written to the logger's core directly, so zap's own Fatal and
+// Panic behavior and its caller/stacktrace options are not applied.
+func NewZapBackend(z *zap.Logger) *ZapBackend {
+	return &ZapBackend{core: z.Core()}
+}
+
+// Enabled implements Backend
+func (b *ZapBackend) Enabled(level logrus.Level) bool {
+	return b.core.Enabled(zapLevel(level))
+}
+
+// Log implements Backend
+func (b *ZapBackend) Log(level logrus.Level, fields []Field, msg string) {
+	ce := b.core.Check(zapcore.Entry{Level: zapLevel(level), Time: time.Now(), Message: msg}, nil)
+	if ce == nil {
+		return
+	}
+
+	buf := zapFieldPool.Get().(*[]zap.Field)
+	zf := (*buf)[:0]
+	for _, f := range fields {
+		switch f.Kind {
+		case StringKind:
+			zf = append(zf, zap.String(f.Key, f.Str))
+		case IntKind:
+			zf = append(zf, zap.Int64(f.Key, f.Int))
+		default:
+			zf = append(zf, zap.Any(f.Key, f.Any))
+		}
+	}
+	ce.Write(zf...)
+
+	clear(zf)
+	*buf = zf[:0]
+	zapFieldPool.Put(buf)
+}
+
+// zapFieldPool recycles the zap fields of records, cores encode them in Write
+var zapFieldPool = sync.Pool{
+	New: func() any {
+		fields := make([]zap.Field, 0, 16)
+		return &fields
+	},
+}
+
+// zapLevel maps a logrus level to the closest zap level, trace becomes debug
+func zapLevel(level logrus.Level) zapcore.Level {
+	switch level {
+	case logrus.PanicLevel:
+		return zapcore.PanicLevel
+	case logrus.FatalLevel:
+		return zapcore.FatalLevel
+	case logrus.ErrorLevel:
+		return zapcore.ErrorLevel
+	case logrus.WarnLevel:
+		return zapcore.WarnLevel
+	case logrus.InfoLevel:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
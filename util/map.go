@@ -0,0 +1,38 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CopyMap returns a shallow copy of the given map.
+//
+// The returned map can be mutated freely without affecting the original,
+// nested reference values (maps, slices, pointers) are still shared.
+func CopyMap[K comparable, V any](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// DeepCopyJSON returns a deep copy of v by round-tripping it through JSON.
+//
+// The result is made of the generic JSON types (map[string]any, []any,
+// string, float64, bool and nil), so values that do not survive JSON
+// encoding (channels, funcs) will return an error.
+func DeepCopyJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package util
+
+import "testing"
+
+func TestCopyMap(t *testing.T) {
+	original := map[string]any{"password": "secret", "user": "alice"}
+
+	copied := CopyMap(original)
+	copied["password"] = "***"
+	delete(copied, "user")
+
+	if original["password"] != "secret" {
+		t.Errorf("original was mutated, got password %v", original["password"])
+	}
+	if _, ok := original["user"]; !ok {
+		t.Error("original lost key user")
+	}
+
+	if CopyMap[string, int](nil) != nil {
+		t.Error("expected nil copy of nil map")
+	}
+}
+
+func TestDeepCopyJSON(t *testing.T) {
+	original := map[string]any{
+		"request": map[string]any{
+			"headers": map[string]any{"authorization": "Bearer token"},
+			"tags":    []any{"a", "b"},
+		},
+	}
+
+	copied, err := DeepCopyJSON(original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := copied.(map[string]any)["request"].(map[string]any)
+	req["headers"].(map[string]any)["authorization"] = "***"
+	req["tags"].([]any)[0] = "z"
+
+	origReq := original["request"].(map[string]any)
+	if got := origReq["headers"].(map[string]any)["authorization"]; got != "Bearer token" {
+		t.Errorf("original nested map was mutated, got %v", got)
+	}
+	if got := origReq["tags"].([]any)[0]; got != "a" {
+		t.Errorf("original nested slice was mutated, got %v", got)
+	}
+
+	if _, err := DeepCopyJSON(make(chan int)); err == nil {
+		t.Error("expected error for non-JSON value")
+	}
+}
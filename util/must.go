@@ -0,0 +1,31 @@
+package util
+
+import "fmt"
+
+// Must returns v, panicking if err is not nil.
+//
+// Use it only for initialization that cannot recover, e.g. parsing
+// constant templates or regexes at startup.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(fmt.Sprintf("util.Must: unexpected error: %v", err))
+	}
+	return v
+}
+
+// Must0 panics if err is not nil, for calls that return only an error.
+func Must0(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("util.Must0: unexpected error: %v", err))
+	}
+}
+
+// CoalesceErrors returns the first non-nil error, or nil if all are nil.
+func CoalesceErrors(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMust(t *testing.T) {
+	if got := Must(42, nil); got != 42 {
+		t.Errorf("expected 42, got %d", got)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected panic")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "boom") {
+			t.Errorf("panic message should contain the error, got %v", r)
+		}
+	}()
+	Must("", errors.New("boom"))
+}
+
+func TestMust0(t *testing.T) {
+	Must0(nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	Must0(errors.New("boom"))
+}
+
+func TestCoalesceErrors(t *testing.T) {
+	first := errors.New("first")
+	second := errors.New("second")
+
+	if err := CoalesceErrors(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := CoalesceErrors(nil, nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := CoalesceErrors(nil, first, second); err != first {
+		t.Errorf("expected first error, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package util
+
+// KeyBy indexes a slice by a derived key.
+//
+// When several elements share a key the last one wins, matching the
+// behavior of assigning in a loop; use KeyByFirst to keep the first.
+func KeyBy[T any, K comparable](in []T, key func(T) K) map[K]T {
+	out := make(map[K]T, len(in))
+	for _, v := range in {
+		out[key(v)] = v
+	}
+	return out
+}
+
+// KeyByFirst indexes a slice by a derived key, keeping the first element
+// when several elements share a key.
+func KeyByFirst[T any, K comparable](in []T, key func(T) K) map[K]T {
+	out := make(map[K]T, len(in))
+	for _, v := range in {
+		k := key(v)
+		if _, ok := out[k]; !ok {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package util
+
+import "testing"
+
+type entity struct {
+	ID   string
+	Name string
+}
+
+func TestKeyBy(t *testing.T) {
+	in := []entity{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}, {ID: "1", Name: "c"}}
+	id := func(e entity) string { return e.ID }
+
+	last := KeyBy(in, id)
+	if len(last) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(last))
+	}
+	if last["1"].Name != "c" {
+		t.Errorf("KeyBy should keep the last element, got %q", last["1"].Name)
+	}
+
+	first := KeyByFirst(in, id)
+	if first["1"].Name != "a" {
+		t.Errorf("KeyByFirst should keep the first element, got %q", first["1"].Name)
+	}
+	if first["2"].Name != "b" {
+		t.Errorf("expected b for key 2, got %q", first["2"].Name)
+	}
+
+	if got := KeyBy([]entity(nil), id); got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil map, got %v", got)
+	}
+	if got := KeyByFirst([]entity{}, id); got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil map, got %v", got)
+	}
+}
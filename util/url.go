@@ -0,0 +1,53 @@
+package util
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPorts are omitted from built URLs for the matching scheme
+var defaultPorts = map[string]int{
+	"http":  80,
+	"https": 443,
+	"ws":    80,
+	"wss":   443,
+}
+
+// BuildURL builds a URL with proper escaping of the path and query.
+//
+// The port is omitted when it is zero or the default for the scheme,
+// e.g. https on 443. Query parameters are encoded in sorted key order.
+func BuildURL(scheme, host string, port int, path string, query map[string]string) string {
+	u := url.URL{
+		Scheme: scheme,
+		Host:   buildHost(scheme, host, port),
+	}
+
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u.Path = path
+
+	if len(query) > 0 {
+		values := make(url.Values, len(query))
+		for k, v := range query {
+			values.Set(k, v)
+		}
+		u.RawQuery = values.Encode()
+	}
+
+	return u.String()
+}
+
+// buildHost joins host and port, bracketing IPv6 addresses
+func buildHost(scheme, host string, port int) string {
+	if port <= 0 || defaultPorts[strings.ToLower(scheme)] == port {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -0,0 +1,38 @@
+package util
+
+import "testing"
+
+func TestBuildURL(t *testing.T) {
+	testCases := []struct {
+		name   string
+		scheme string
+		host   string
+		port   int
+		path   string
+		query  map[string]string
+		want   string
+	}{
+		{name: "localhost with port", scheme: "http", host: "localhost", port: 8080, path: "/api", want: "http://localhost:8080/api"},
+		{name: "default http port", scheme: "http", host: "example.com", port: 80, path: "health", want: "http://example.com/health"},
+		{name: "default https port", scheme: "https", host: "example.com", port: 443, want: "https://example.com"},
+		{name: "https on custom port", scheme: "https", host: "example.com", port: 8443, want: "https://example.com:8443"},
+		{name: "no port", scheme: "https", host: "example.com", path: "/a b", want: "https://example.com/a%20b"},
+		{name: "ipv6", scheme: "http", host: "::1", port: 9000, want: "http://[::1]:9000"},
+		{
+			name:   "query encoding",
+			scheme: "https",
+			host:   "example.com",
+			path:   "/search",
+			query:  map[string]string{"q": "a&b=c d", "lang": "zh/中文"},
+			want:   "https://example.com/search?lang=zh%2F%E4%B8%AD%E6%96%87&q=a%26b%3Dc+d",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := BuildURL(tc.scheme, tc.host, tc.port, tc.path, tc.query); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}